@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"log"
+	"strings"
+)
+
+// describeAlias returns a note naming the declaration that target re-exports,
+// followed by that declaration's documentation unless -deny-packages blocks
+// its package. It returns "" when target is not an alias or cannot be
+// resolved.
+func (gs *godocServer) describeAlias(ctx context.Context, workingDir, importPath, target string) string {
+	if strings.Contains(target, ".") {
+		return ""
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, importPath)
+	if err != nil {
+		log.Printf("Alias resolution skipped for %s.%s: %v", importPath, target, err)
+		return ""
+	}
+	original, err := gs.aliasTarget(ctx, lp, target)
+	if err != nil || original == "" {
+		return ""
+	}
+
+	note := fmt.Sprintf("\n%s is an alias of %s\n", target, original)
+	origPkg, origName := splitQualified(original)
+	if gs.checkPackageAllowed(origPkg) != nil {
+		return note
+	}
+	doc, err := gs.runGoDoc(ctx, workingDir, origPkg, origName)
+	if err != nil {
+		return note
+	}
+	return note + "\n" + doc
+}
+
+// aliasTarget reports the fully qualified symbol ("import/path.Name") that
+// name re-exports through a type alias or a `var X = pkg.X` style declaration.
+// It returns "" when name is declared directly in the package.
+func (gs *godocServer) aliasTarget(ctx context.Context, lp *loadedPackage, name string) (string, error) {
+	var sel *ast.SelectorExpr
+	isAlias := false
+	for _, f := range lp.files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.Name == name && s.Assign.IsValid() {
+						isAlias = true
+					}
+				case *ast.ValueSpec:
+					for i, id := range s.Names {
+						if id.Name == name && i < len(s.Values) {
+							sel, _ = s.Values[i].(*ast.SelectorExpr)
+						}
+					}
+				}
+			}
+		}
+	}
+	if !isAlias && sel == nil {
+		return "", nil
+	}
+
+	if err := gs.typeCheck(ctx, lp); err != nil {
+		return "", err
+	}
+
+	if isAlias {
+		obj, ok := lp.pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			return "", nil
+		}
+		named, ok := types.Unalias(obj.Type()).(*types.Named)
+		if !ok || named.Obj().Pkg() == nil {
+			return "", nil
+		}
+		return qualifiedName(named.Obj()), nil
+	}
+
+	obj := lp.info.Uses[sel.Sel]
+	if obj == nil || obj.Pkg() == nil || obj.Pkg() == lp.pkg {
+		return "", nil
+	}
+	return qualifiedName(obj), nil
+}
+
+// qualifiedName returns obj as "import/path.Name".
+func qualifiedName(obj types.Object) string {
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+// splitQualified splits "import/path.Name" into its import path and symbol.
+func splitQualified(qualified string) (string, string) {
+	i := strings.LastIndex(qualified, ".")
+	if i < 0 || i < strings.LastIndex(qualified, "/") {
+		return qualified, ""
+	}
+	return qualified[:i], qualified[i+1:]
+}
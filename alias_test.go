@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSplitQualified(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantPkg  string
+		wantName string
+	}{
+		{"io/fs.FileMode", "io/fs", "FileMode"},
+		{"github.com/user/repo.Type", "github.com/user/repo", "Type"},
+		{"github.com/user/repo", "github.com/user/repo", ""},
+		{"fmt", "fmt", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			pkg, name := splitQualified(tt.in)
+			if pkg != tt.wantPkg || name != tt.wantName {
+				t.Errorf("splitQualified(%q) = (%q, %q), want (%q, %q)", tt.in, pkg, name, tt.wantPkg, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestAliasTarget(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/facade\n\ngo 1.21\n",
+		"internal/impl/impl.go": `package impl
+
+// Engine does the work.
+type Engine struct{}
+
+// New returns an Engine.
+func New() *Engine { return &Engine{} }
+`,
+		"facade.go": `package facade
+
+import "example.com/facade/internal/impl"
+
+// Engine is re-exported.
+type Engine = impl.Engine
+
+// New is re-exported.
+var New = impl.New
+
+// Local is declared here.
+type Local struct{}
+`,
+	})
+
+	gs := newGodocServer()
+	ctx := context.Background()
+	lp, err := gs.loadPackage(ctx, dir, "example.com/facade")
+	if err != nil {
+		t.Fatalf("loadPackage: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Engine", "example.com/facade/internal/impl.Engine"},
+		{"New", "example.com/facade/internal/impl.New"},
+		{"Local", ""},
+		{"Missing", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gs.aliasTarget(ctx, lp, tt.name)
+			if err != nil {
+				t.Fatalf("aliasTarget: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("aliasTarget(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeAliasStdlib(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer()
+	note := gs.describeAlias(context.Background(), "", "os", "FileMode")
+	if !strings.Contains(note, "FileMode is an alias of io/fs.FileMode") {
+		t.Errorf("expected alias note, got: %s", note)
+	}
+	if !strings.Contains(note, "package fs") {
+		t.Errorf("expected inlined io/fs documentation, got: %s", note)
+	}
+}
+
+func TestDescribeAliasDeniedPackage(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer(withDenyPackages([]string{"io/fs"}))
+	note := gs.describeAlias(context.Background(), "", "os", "FileMode")
+	if !strings.Contains(note, "FileMode is an alias of io/fs.FileMode") {
+		t.Errorf("expected alias note, got: %s", note)
+	}
+	if strings.Contains(note, "package fs") {
+		t.Errorf("documentation of a denied package was inlined: %s", note)
+	}
+}
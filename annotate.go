@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// bodylessFuncNote explains why the function named by target (either "Name"
+// or "Type.Method") has no Go body: it is either pulled in via go:linkname or
+// implemented in assembly. It returns "" for ordinary functions.
+func (gs *godocServer) bodylessFuncNote(ctx context.Context, workingDir, importPath, target string) string {
+	lp, err := gs.loadPackage(ctx, workingDir, importPath)
+	if err != nil {
+		return ""
+	}
+
+	recv, name, isMethod := strings.Cut(target, ".")
+	if !isMethod {
+		name, recv = recv, ""
+	}
+
+	for _, f := range lp.files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Name.Name != name || fn.Body != nil || receiverName(fn) != recv {
+				continue
+			}
+			if linked, ok := linknameTarget(f, name); ok {
+				if linked == "" {
+					return fmt.Sprintf("\nNote: %s has no Go body here; it is provided via a go:linkname directive.\n", target)
+				}
+				return fmt.Sprintf("\nNote: %s has no Go body here; it is linked via go:linkname to %s.\n", target, linked)
+			}
+			if file := findAsmDefinition(lp, name); file != "" {
+				return fmt.Sprintf("\nNote: %s is implemented in assembly (see %s).\n", target, file)
+			}
+			return fmt.Sprintf("\nNote: %s has no Go body; its implementation is supplied elsewhere, typically by the runtime via go:linkname.\n", target)
+		}
+	}
+	return ""
+}
+
+// receiverName returns the base type name of fn's receiver, or "".
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	expr := fn.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		expr = t.X
+	case *ast.IndexListExpr:
+		expr = t.X
+	}
+	if id, ok := expr.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// linknameTarget looks for a "//go:linkname name [target]" directive in f.
+// It reports the target symbol (possibly empty) and whether a directive
+// for name exists.
+func linknameTarget(f *ast.File, name string) (string, bool) {
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			fields := strings.Fields(c.Text)
+			if len(fields) < 2 || fields[0] != "//go:linkname" || fields[1] != name {
+				continue
+			}
+			if len(fields) >= 3 {
+				return fields[2], true
+			}
+			return "", true
+		}
+	}
+	return "", false
+}
+
+// findAsmDefinition returns the name of the first assembly file in lp that
+// defines a TEXT symbol for name.
+func findAsmDefinition(lp *loadedPackage, name string) string {
+	marker := "·" + name + "("
+	for _, s := range lp.sFiles {
+		content, err := os.ReadFile(filepath.Join(lp.dir, s))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "TEXT") && strings.Contains(line, marker) {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// promotedMethodsNote lists the exported methods the type typeName gains from
+// its embedded fields, each marked with the embedded type it is promoted
+// from. go doc omits these, so they are otherwise invisible. It returns ""
+// when the type has no promoted methods or cannot be type-checked.
+func (gs *godocServer) promotedMethodsNote(ctx context.Context, workingDir, importPath, typeName string) string {
+	lp, err := gs.loadPackage(ctx, workingDir, importPath)
+	if err != nil || gs.typeCheck(ctx, lp) != nil {
+		return ""
+	}
+	obj, ok := lp.pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return ""
+	}
+	if _, isIface := obj.Type().Underlying().(*types.Interface); isIface {
+		return ""
+	}
+
+	var lines []string
+	for _, m := range methodSet(lp.pkg, obj) {
+		if m.from != "" {
+			lines = append(lines, m.decl+"  // promoted from "+m.from)
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\nPROMOTED METHODS (from embedded fields of %s)\n\n%s\n", typeName, strings.Join(lines, "\n"))
+}
+
+// initHints describes the side effects of well-known registration calls made
+// from init functions, keyed by "importpath.Func". A "*" function matches any
+// function of that package.
+var initHints = map[string]string{
+	"database/sql.Register":     "registers a database/sql driver",
+	"image.RegisterFormat":      "registers an image format decoder",
+	"net/http.Handle":           "registers HTTP handlers on http.DefaultServeMux",
+	"net/http.HandleFunc":       "registers HTTP handlers on http.DefaultServeMux",
+	"expvar.Publish":            "publishes expvar variables",
+	"encoding/gob.Register":     "registers types with encoding/gob",
+	"encoding/gob.RegisterName": "registers types with encoding/gob",
+	"crypto.RegisterHash":       "registers a crypto.Hash implementation",
+	"mime.AddExtensionType":     "registers MIME types",
+	"flag.*":                    "defines command-line flags on flag.CommandLine",
+	"os/signal.Notify":          "installs signal handlers",
+}
+
+// initFuncNote reports how many init functions the package at importPath
+// declares, since importing it runs them, and names the well-known
+// registration side effects they perform. It returns "" when there are none.
+func (gs *godocServer) initFuncNote(ctx context.Context, workingDir, importPath string) string {
+	lp, err := gs.loadPackage(ctx, workingDir, importPath)
+	if err != nil {
+		return ""
+	}
+
+	count := 0
+	var hints []string
+	seen := make(map[string]bool)
+	for _, f := range lp.files {
+		imports := fileImports(f)
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Name.Name != "init" || fn.Body == nil {
+				continue
+			}
+			count++
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				x, ok := sel.X.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				pkg, ok := imports[x.Name]
+				if !ok {
+					return true
+				}
+				hint, ok := initHints[pkg+"."+sel.Sel.Name]
+				if !ok {
+					hint, ok = initHints[pkg+".*"]
+				}
+				if ok && !seen[hint] {
+					seen[hint] = true
+					hints = append(hints, fmt.Sprintf("%s (%s.%s)", hint, x.Name, sel.Sel.Name))
+				}
+				return true
+			})
+		}
+	}
+	if count == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	noun := "function"
+	if count > 1 {
+		noun = "functions"
+	}
+	fmt.Fprintf(&b, "\nNote: this package has %d init %s; importing it has side effects.\n", count, noun)
+	for _, h := range hints {
+		fmt.Fprintf(&b, "  - %s\n", h)
+	}
+	return b.String()
+}
+
+// docOnlyNote reports that the package at importPath declares no exported
+// symbols, as umbrella packages whose doc.go holds only the package comment
+// do, so its overview is the whole of its documentation. It returns "" when
+// the package exports anything or cannot be parsed.
+func (gs *godocServer) docOnlyNote(ctx context.Context, workingDir, importPath string) string {
+	lp, err := gs.loadPackage(ctx, workingDir, importPath)
+	if err != nil {
+		return ""
+	}
+	dp, err := lp.docPackage()
+	if err != nil || len(packageSymbols(dp)) > 0 {
+		return ""
+	}
+	return "\nNote: this package declares no exported symbols; it exists for its package documentation.\n"
+}
+
+// fileImports maps the names f uses for its imports to their import paths.
+// Blank and dot imports are skipped.
+func fileImports(f *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range f.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(p)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		imports[name] = p
+	}
+	return imports
+}
+
+// cgoNote explains that the package in importPath uses cgo: building it needs
+// CGO_ENABLED=1 and a C toolchain, and any functions it exports to C with
+// //export are listed. It returns "" for packages without cgo files.
+func (gs *godocServer) cgoNote(ctx context.Context, workingDir, importPath string) string {
+	dir, err := gs.packageDir(ctx, workingDir, importPath)
+	if err != nil {
+		return ""
+	}
+
+	// Look at cgo files even when cgo is disabled for this process.
+	ctxt := build.Default
+	ctxt.CgoEnabled = true
+	bp, err := ctxt.ImportDir(dir, 0)
+	if err != nil || len(bp.CgoFiles) == 0 {
+		return ""
+	}
+
+	var exported []string
+	fset := token.NewFileSet()
+	for _, name := range bp.CgoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil {
+				continue
+			}
+			for _, c := range fn.Doc.List {
+				if fields := strings.Fields(c.Text); len(fields) >= 2 && fields[0] == "//export" {
+					exported = append(exported, fields[1])
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("\nNote: this package uses cgo (import \"C\"); building it requires CGO_ENABLED=1 and a C toolchain.\n")
+	if !build.Default.CgoEnabled {
+		b.WriteString("cgo is disabled here, so declarations from its cgo files may be missing above.\n")
+	}
+	if len(exported) > 0 {
+		fmt.Fprintf(&b, "Functions exported to C with //export: %s\n", strings.Join(exported, ", "))
+	}
+	return b.String()
+}
+
+// isCgoPseudoPackage reports whether a get_doc path names the cgo "C"
+// pseudo-package, alone or qualifying a symbol such as "C.malloc", which has
+// no Go documentation. Targets are not checked: a package may declare its own
+// symbol named C.
+func isCgoPseudoPackage(pkgPath string) bool {
+	return pkgPath == "C" || strings.HasPrefix(pkgPath, "C.")
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestBodylessFuncNote(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/lowlevel\n\ngo 1.21\n",
+		"lowlevel.go": `package lowlevel
+
+import _ "unsafe"
+
+// Add is implemented in assembly.
+func Add(a, b int) int
+
+// Nanotime comes from the runtime.
+//
+//go:linkname Nanotime runtime.nanotime
+func Nanotime() int64
+
+// Plain has a body.
+func Plain() {}
+
+type T struct{}
+
+// Method is implemented in assembly.
+func (T) Method()
+`,
+		"add_amd64.s": "#include \"textflag.h\"\n\nTEXT ·Add(SB),NOSPLIT,$0-24\n\tRET\n",
+		"add_other.s": "#include \"textflag.h\"\n\nTEXT ·Add(SB),NOSPLIT,$0-24\n\tRET\n",
+	})
+
+	gs := newGodocServer()
+	ctx := context.Background()
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"Add", "implemented in assembly (see add_"},
+		{"Nanotime", "linked via go:linkname to runtime.nanotime"},
+		{"T.Method", "has no Go body"},
+		{"Plain", ""},
+		{"Missing", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			got := gs.bodylessFuncNote(ctx, dir, "example.com/lowlevel", tt.target)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("expected no note, got %q", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("note = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodylessFuncNoteStdlibAssembly(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer()
+	note := gs.bodylessFuncNote(context.Background(), "", "sync/atomic", "AddInt32")
+	if !strings.Contains(note, "implemented in assembly") {
+		t.Errorf("expected assembly note for sync/atomic.AddInt32, got %q", note)
+	}
+}
+
+func TestPromotedMethodsNote(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/embed\n\ngo 1.21\n",
+		"embed.go": `package embed
+
+import "bytes"
+
+type Base struct{}
+
+// Close releases resources.
+func (*Base) Close() error { return nil }
+
+func (Base) internal() {}
+
+// Conn embeds Base and a buffer.
+type Conn struct {
+	*Base
+	bytes.Buffer
+}
+
+// Dial is Conn's own method.
+func (*Conn) Dial() {}
+
+type Plain struct{ n int }
+`,
+	})
+
+	gs := newGodocServer()
+	ctx := context.Background()
+
+	note := gs.promotedMethodsNote(ctx, dir, "example.com/embed", "Conn")
+	for _, want := range []string{
+		"func (*Base) Close() error  // promoted from Base",
+		"func (*bytes.Buffer) WriteString(s string) (n int, err error)  // promoted from bytes.Buffer",
+	} {
+		if !strings.Contains(note, want) {
+			t.Errorf("note missing %q:\n%s", want, note)
+		}
+	}
+	for _, unwanted := range []string{"Dial", "internal"} {
+		if strings.Contains(note, unwanted) {
+			t.Errorf("note should not mention %s:\n%s", unwanted, note)
+		}
+	}
+
+	if got := gs.promotedMethodsNote(ctx, dir, "example.com/embed", "Plain"); got != "" {
+		t.Errorf("expected no note for Plain, got %q", got)
+	}
+}
+
+func TestInitFuncNote(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/driver\n\ngo 1.21\n",
+		"driver.go": `package driver
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	stdflag "flag"
+)
+
+type Driver struct{ driver.Driver }
+
+func init() {
+	sql.Register("demo", Driver{})
+}
+
+func init() {
+	stdflag.Bool("demo-debug", false, "")
+}
+`,
+		"none/none.go": "package none\n\nfunc Init() {}\n",
+	})
+
+	gs := newGodocServer()
+	ctx := context.Background()
+
+	note := gs.initFuncNote(ctx, dir, "example.com/driver")
+	for _, want := range []string{
+		"has 2 init functions",
+		"registers a database/sql driver (sql.Register)",
+		"defines command-line flags on flag.CommandLine (stdflag.Bool)",
+	} {
+		if !strings.Contains(note, want) {
+			t.Errorf("note missing %q:\n%s", want, note)
+		}
+	}
+
+	if got := gs.initFuncNote(ctx, dir, "example.com/driver/none"); got != "" {
+		t.Errorf("expected no note for package without init, got %q", got)
+	}
+
+	pprof := gs.initFuncNote(ctx, "", "net/http/pprof")
+	if !strings.Contains(pprof, "registers HTTP handlers on http.DefaultServeMux") {
+		t.Errorf("expected HTTP handler hint for net/http/pprof, got %q", pprof)
+	}
+}
+
+func TestCgoNote(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/cgolib\n\ngo 1.21\n",
+		"lib.go": `package cgolib
+
+// #include <stdlib.h>
+import "C"
+
+// Add is callable from C.
+//
+//export Add
+func Add(a, b C.int) C.int { return a + b }
+
+// Version is Go-only.
+func Version() string { return "1" }
+`,
+		"pure/pure.go": "package pure\n\nfunc F() {}\n",
+	})
+
+	gs := newGodocServer()
+	ctx := context.Background()
+
+	note := gs.cgoNote(ctx, dir, "example.com/cgolib")
+	if !strings.Contains(note, "requires CGO_ENABLED=1") {
+		t.Errorf("expected CGO_ENABLED note, got %q", note)
+	}
+	if !strings.Contains(note, "//export: Add\n") {
+		t.Errorf("expected exported function list, got %q", note)
+	}
+
+	if got := gs.cgoNote(ctx, dir, "example.com/cgolib/pure"); got != "" {
+		t.Errorf("expected no note for pure Go package, got %q", got)
+	}
+}
+
+func TestDocOnlyNote(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":        "module example.com/umbrella\n\ngo 1.21\n",
+		"meta/doc.go":   "// Package meta describes the project layout.\n//\n// See the subpackages.\npackage meta\n",
+		"hidden/doc.go": "// Package hidden has only internals.\npackage hidden\n\nfunc helper() {}\n",
+		"code/code.go":  "// Package code does work.\npackage code\n\nfunc Run() {}\n",
+	})
+
+	gs := newGodocServer()
+	ctx := context.Background()
+
+	for _, pkg := range []string{"example.com/umbrella/meta", "example.com/umbrella/hidden"} {
+		if note := gs.docOnlyNote(ctx, dir, pkg); !strings.Contains(note, "declares no exported symbols") {
+			t.Errorf("docOnlyNote(%s) = %q, want a no-exported-symbols note", pkg, note)
+		}
+	}
+	if got := gs.docOnlyNote(ctx, dir, "example.com/umbrella/code"); got != "" {
+		t.Errorf("expected no note for package with exported symbols, got %q", got)
+	}
+
+	// -short lists declarations only, so get_doc falls back to the overview.
+	for _, flags := range [][]any{nil, {"-short"}} {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		args := map[string]any{"path": "./meta", "working_dir": dir}
+		if flags != nil {
+			args["cmd_flags"] = flags
+		}
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(ctx, req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		text := result.Content[0].(mcp.TextContent).Text
+		if result.IsError {
+			t.Fatalf("flags %v: unexpected tool error: %s", flags, text)
+		}
+		for _, want := range []string{"Package meta describes the project layout.", "declares no exported symbols"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("flags %v: output missing %q:\n%s", flags, want, text)
+			}
+		}
+	}
+}
+
+func TestIsCgoPseudoPackage(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"C", true},
+		{"C.malloc", true},
+		{"example.com/cgolib", false},
+		{"example.com/C", false},
+		{"crypto", false},
+	}
+	for _, tt := range tests {
+		if got := isCgoPseudoPackage(tt.path); got != tt.want {
+			t.Errorf("isCgoPseudoPackage(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/build"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const apiDiffDescription = `Compare the exported API of a local Go package between two git revisions,
+such as a pull request's base branch and its head. Each revision's files are
+read with git (the working tree is never checked out or modified), parsed
+with the server's default build context, and every exported symbol that was
+added, removed, or changed signature is listed. Use this when reviewing a
+change to judge its public API impact without comparing the source by hand.`
+
+// shortHashLength is how many hex digits of a commit hash results show.
+const shortHashLength = 12
+
+// apiRevision is the exported API of a package at one git revision.
+type apiRevision struct {
+	ref    string
+	commit string
+	exists bool              // the package directory had Go files at ref
+	sigs   map[string]string // exported symbol to signature
+}
+
+func (gs *godocServer) handleAPIDiff(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgArg, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	workingDir, err := request.RequireString("working_dir")
+	if err != nil {
+		return mcp.NewToolResultError("working_dir argument is required; only packages in a local git repository can be compared"), nil
+	}
+	baseRef, err := request.RequireString("base")
+	if err != nil {
+		return mcp.NewToolResultError("base argument is required"), nil
+	}
+	headRef := request.GetString("head", "HEAD")
+	for _, ref := range []string{baseRef, headRef} {
+		// A leading "-" would be read by git as an option.
+		if ref == "" || strings.HasPrefix(ref, "-") {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid git ref %q", ref)), nil
+		}
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgArg, workingDir)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// A package deleted from the working tree can still be compared by the
+	// relative path it had.
+	dir, err := gs.packageDir(ctx, workingDir, pkgPath)
+	if err != nil {
+		if !strings.HasPrefix(pkgArg, ".") {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		dir = filepath.Join(workingDir, pkgArg)
+	}
+
+	out, err := gs.git(ctx, workingDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is not in a git repository: %v", workingDir, err)), nil
+	}
+	root := strings.TrimSpace(string(out))
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return mcp.NewToolResultError(fmt.Sprintf("package directory %s is outside the git repository at %s", dir, root)), nil
+	}
+
+	base, err := gs.revisionAPI(ctx, root, filepath.ToSlash(rel), baseRef)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	head, err := gs.revisionAPI(ctx, root, filepath.ToSlash(rel), headRef)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Exported API changes in %s from %s (%s) to %s (%s)\n", pkgPath,
+		base.ref, base.commit[:min(len(base.commit), shortHashLength)], head.ref, head.commit[:min(len(head.commit), shortHashLength)])
+	for _, rev := range []apiRevision{base, head} {
+		if !rev.exists {
+			fmt.Fprintf(&b, "The package has no Go files at %s.\n", rev.ref)
+		}
+	}
+
+	diff := diffSignatures(base.sigs, head.sigs)
+	if len(diff) == 0 {
+		b.WriteString("\nNo exported API changes.\n")
+		return mcp.NewToolResultText(b.String()), nil
+	}
+	counts := map[byte]int{}
+	for _, line := range diff {
+		counts[line[0]]++
+	}
+	fmt.Fprintf(&b, "%d added, %d removed, %d changed\n\n", counts['+'], counts['-'], counts['~'])
+	for _, line := range diff {
+		b.WriteString(line + "\n")
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// revisionAPI reads the Go files of directory rel (slash-separated, relative
+// to the repository root) at ref and returns the exported API they declare.
+// The files are copied with git into a temporary directory and parsed there.
+func (gs *godocServer) revisionAPI(ctx context.Context, root, rel, ref string) (apiRevision, error) {
+	out, err := gs.git(ctx, root, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return apiRevision{}, fmt.Errorf("unknown git revision %q", ref)
+	}
+	rev := apiRevision{ref: ref, commit: strings.TrimSpace(string(out))}
+
+	args := []string{"ls-tree", "-z", rev.commit}
+	if rel != "." {
+		args = append(args, "--", rel+"/")
+	}
+	out, err = gs.git(ctx, root, args...)
+	if err != nil {
+		return apiRevision{}, err
+	}
+
+	tmp, err := os.MkdirTemp("", "godoc-mcp-api-")
+	if err != nil {
+		return apiRevision{}, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	// Entries are "<mode> <type> <object>\t<path>", NUL-terminated.
+	for _, entry := range strings.Split(string(out), "\x00") {
+		info, name, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(info)
+		name = path.Base(name)
+		if !ok || len(fields) != 3 || fields[1] != "blob" || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		src, err := gs.git(ctx, root, "cat-file", "blob", fields[2])
+		if err != nil {
+			return apiRevision{}, err
+		}
+		if err := os.WriteFile(filepath.Join(tmp, name), src, 0644); err != nil {
+			return apiRevision{}, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		rev.exists = true
+	}
+
+	ctxt := build.Default
+	rev.sigs, err = exportedSignatures(&ctxt, tmp)
+	if err != nil {
+		return apiRevision{}, fmt.Errorf("failed to parse the package at %s: %w", ref, err)
+	}
+	return rev, nil
+}
+
+// git runs git in dir, in a process pool slot, and returns its standard
+// output. Errors include what git wrote to standard error.
+func (gs *godocServer) git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	gitCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(gitCtx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := gs.output(gitCtx, cmd)
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, errors.New("git not found in PATH")
+		}
+		return nil, fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
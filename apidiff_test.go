@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleAPIDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.21\n",
+		"cart/cart.go": `package cart
+
+// Add adds an item.
+func Add(item string) {}
+
+// Remove removes an item.
+func Remove(item string) {}
+
+func helper() {}
+`,
+		"cart/cart_test.go": "package cart\n\nfunc TestOnly() {}\n",
+	})
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "v1")
+	git("tag", "v1")
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("cart/cart.go", `package cart
+
+// Add adds count items.
+func Add(item string, count int) {}
+
+// Total sums the cart.
+func Total() int { return 0 }
+
+func helper(n int) {}
+`)
+	write("pay/pay.go", "package pay\n\n// Charge charges.\nfunc Charge() {}\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "v2")
+
+	// Uncommitted edits are not part of HEAD.
+	write("cart/cart.go", "package cart\n\nfunc Uncommitted() {}\n")
+
+	gs := newGodocServer(withGitTools(true))
+	call := func(args map[string]any) (string, bool) {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "api_diff"
+		req.Params.Arguments = args
+		result, err := gs.mcpServer.GetTool("api_diff").Handler(context.Background(), req)
+		if err != nil {
+			t.Fatalf("api_diff returned protocol error: %v", err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	text, isErr := call(map[string]any{"path": "./cart", "working_dir": dir, "base": "v1"})
+	if isErr {
+		t.Fatalf("unexpected tool error: %s", text)
+	}
+	for _, want := range []string{
+		"from v1 (",
+		"to HEAD (",
+		"1 added, 1 removed, 1 changed",
+		"+ func Total() int",
+		"- func Remove(item string)",
+		"~ func Add(item string, count int)  (was: func Add(item string))",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"helper", "TestOnly", "Uncommitted"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("output mentions %s:\n%s", unwanted, text)
+		}
+	}
+
+	text, isErr = call(map[string]any{"path": "./pay", "working_dir": dir, "base": "v1", "head": "HEAD"})
+	if isErr || !strings.Contains(text, "no Go files at v1") || !strings.Contains(text, "+ func Charge()") {
+		t.Errorf("new package: unexpected result:\n%s", text)
+	}
+
+	text, isErr = call(map[string]any{"path": "./cart", "working_dir": dir, "base": "HEAD", "head": "HEAD"})
+	if isErr || !strings.Contains(text, "No exported API changes.") {
+		t.Errorf("same revision: unexpected result:\n%s", text)
+	}
+
+	for _, ref := range []string{"--output=/tmp/x", "no-such-ref"} {
+		if text, isErr := call(map[string]any{"path": "./cart", "working_dir": dir, "base": ref}); !isErr {
+			t.Errorf("base %q: expected an error, got:\n%s", ref, text)
+		}
+	}
+
+	if newGodocServer().mcpServer.GetTool("api_diff") != nil {
+		t.Error("api_diff registered without -enable-git")
+	}
+}
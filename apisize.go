@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const apiSizeDescription = `Measure the exported API surface of a Go package.
+Returns counts of exported functions, types, interfaces, methods, constants,
+and variables, plus the length in lines of the package's full (go doc -all)
+documentation. Use this to gauge how large a package is before deciding how
+to explore it, or to compare the complexity of libraries cheaply.`
+
+func (gs *godocServer) handleAPISize(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	counts := make(map[string]int)
+	total := 0
+	for _, sym := range packageSymbols(dp) {
+		if !ast.IsExported(lastName(sym.name)) {
+			continue
+		}
+		kind := sym.kind
+		if kind == "type" && isInterfaceDecl(sym.decl, sym.name) {
+			kind = "interface"
+		}
+		counts[kind]++
+		total++
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Exported API of %s\n\n", pkgPath)
+	for _, row := range []struct{ label, kind string }{
+		{"functions", "func"},
+		{"types", "type"},
+		{"interfaces", "interface"},
+		{"methods", "method"},
+		{"constants", "const"},
+		{"variables", "var"},
+	} {
+		fmt.Fprintf(&b, "%-12s %d\n", row.label, counts[row.kind])
+	}
+	fmt.Fprintf(&b, "%-12s %d\n", "total", total)
+
+	if doc, err := gs.runGoDoc(ctx, workingDir, "-all", pkgPath); err == nil {
+		lines := strings.Count(doc, "\n")
+		fmt.Fprintf(&b, "\ngo doc -all: %d lines (%d pages at the default page size)\n", lines, pageCount(doc, 1000))
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// isInterfaceDecl reports whether decl declares name as an interface type.
+func isInterfaceDecl(decl ast.Decl, name string) bool {
+	gd, ok := decl.(*ast.GenDecl)
+	if !ok {
+		return false
+	}
+	for _, spec := range gd.Specs {
+		if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == name {
+			_, isIface := ts.Type.(*ast.InterfaceType)
+			return isIface
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleAPISize(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/shapes\n\ngo 1.21\n",
+		"shapes.go": `// Package shapes measures shapes.
+package shapes
+
+// Pi is close enough.
+const Pi = 3.14
+
+const (
+	// Small and Large are sizes.
+	Small, Large = 1, 2
+	internal     = 3
+)
+
+// Default is the default shape.
+var Default Shape = Circle{}
+
+// Shape has an area.
+type Shape interface {
+	Area() float64
+}
+
+// Circle is round.
+type Circle struct{ R float64 }
+
+// NewCircle returns a Circle.
+func NewCircle(r float64) Circle { return Circle{r} }
+
+// Area returns the area.
+func (c Circle) Area() float64 { return Pi * c.R * c.R }
+
+func (c Circle) scale() {}
+
+// Total sums areas.
+func Total(shapes ...Shape) float64 { return 0 }
+`,
+	})
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "api_size"
+	req.Params.Arguments = map[string]any{"path": ".", "working_dir": dir}
+	result, err := gs.handleAPISize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleAPISize returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleAPISize returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+
+	for _, want := range []string{
+		"Exported API of example.com/shapes",
+		"functions    2\n",
+		"types        1\n",
+		"interfaces   1\n",
+		"methods      1\n",
+		"constants    3\n",
+		"variables    1\n",
+		"total        9\n",
+		"go doc -all: ",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const compareBuildTagsDescription = `Report how build tags change the exported API of a Go package.
+The package is parsed once with the server's default build context and once per
+candidate, and every exported symbol that appears, disappears, or changes
+signature is listed. Use this for packages with conditional APIs (e.g. purego
+vs cgo builds, or platform-specific files) that a single get_doc call can't
+reveal.
+
+Each candidate is a build tag (e.g. "purego", "cgo", "netgo") or a platform
+written as GOOS/GOARCH (e.g. "windows/amd64").`
+
+func (gs *godocServer) handleCompareBuildTags(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	tagsArg, err := request.RequireString("tags")
+	if err != nil {
+		return mcp.NewToolResultError("tags argument is required"), nil
+	}
+	var candidates []string
+	for _, tag := range strings.Split(tagsArg, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			candidates = append(candidates, tag)
+		}
+	}
+	if len(candidates) == 0 {
+		return mcp.NewToolResultError("tags must name at least one build tag or GOOS/GOARCH pair"), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dir, err := gs.packageDir(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	base := build.Default
+	baseline, err := exportedSignatures(&base, dir)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var b strings.Builder
+	cgo := "cgo disabled"
+	if base.CgoEnabled {
+		cgo = "cgo enabled"
+	}
+	fmt.Fprintf(&b, "Build tag effects on the exported API of %s\n", pkgPath)
+	fmt.Fprintf(&b, "Baseline: %s/%s, %s, %d exported symbols\n", base.GOOS, base.GOARCH, cgo, len(baseline))
+
+	for _, tag := range candidates {
+		ctxt := tagContext(tag)
+		syms, err := exportedSignatures(&ctxt, dir)
+		b.WriteString("\n" + tag + ":")
+		if err != nil {
+			fmt.Fprintf(&b, " %v\n", err)
+			continue
+		}
+		diff := diffSignatures(baseline, syms)
+		if len(diff) == 0 {
+			b.WriteString(" no change\n")
+			continue
+		}
+		b.WriteString("\n")
+		for _, line := range diff {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// tagContext returns the default build context with tag applied: a GOOS/GOARCH
+// pair selects that platform, "cgo" enables cgo, and anything else is added to
+// the build tags.
+func tagContext(tag string) build.Context {
+	ctxt := build.Default
+	ctxt.BuildTags = slices.Clone(ctxt.BuildTags)
+	if goos, goarch, ok := strings.Cut(tag, "/"); ok {
+		ctxt.GOOS, ctxt.GOARCH = goos, goarch
+		// Cross builds disable cgo in the go command unless CC is set.
+		ctxt.CgoEnabled = false
+		return ctxt
+	}
+	if tag == "cgo" {
+		ctxt.CgoEnabled = true
+		return ctxt
+	}
+	ctxt.BuildTags = append(ctxt.BuildTags, tag)
+	return ctxt
+}
+
+// exportedSignatures parses the package in dir under ctxt and maps each
+// exported symbol to its one-line signature. A package whose files are all
+// excluded has no symbols.
+func exportedSignatures(ctxt *build.Context, dir string) (map[string]string, error) {
+	lp, err := parsePackageContext(ctxt, dir)
+	var noGo *build.NoGoError
+	if errors.As(err, &noGo) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return nil, err
+	}
+
+	sigs := make(map[string]string)
+	for _, sym := range packageSymbols(dp) {
+		if ast.IsExported(lastName(sym.name)) {
+			sigs[sym.name] = symbolSignature(lp.fset, sym)
+		}
+	}
+	return sigs, nil
+}
+
+// diffSignatures lists the symbols added ("+"), removed ("-"), and changed
+// ("~") in got relative to base, sorted by name.
+func diffSignatures(base, got map[string]string) []string {
+	names := make([]string, 0, len(base)+len(got))
+	for name := range base {
+		names = append(names, name)
+	}
+	for name := range got {
+		if _, ok := base[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var diff []string
+	for _, name := range names {
+		was, inBase := base[name]
+		now, inGot := got[name]
+		switch {
+		case !inBase:
+			diff = append(diff, "+ "+now)
+		case !inGot:
+			diff = append(diff, "- "+was)
+		case was != now:
+			diff = append(diff, "~ "+now+"  (was: "+was+")")
+		}
+	}
+	return diff
+}
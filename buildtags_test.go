@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestDiffSignatures(t *testing.T) {
+	base := map[string]string{"A": "func A()", "B": "func B()", "C": "const C = 1"}
+	got := map[string]string{"A": "func A()", "C": "const C = 2", "D": "func D()"}
+	want := []string{"- func B()", "~ const C = 2  (was: const C = 1)", "+ func D()"}
+	if diff := diffSignatures(base, got); !slices.Equal(diff, want) {
+		t.Errorf("diffSignatures() = %q, want %q", diff, want)
+	}
+}
+
+func TestTagContext(t *testing.T) {
+	ctxt := tagContext("windows/arm64")
+	if ctxt.GOOS != "windows" || ctxt.GOARCH != "arm64" {
+		t.Errorf("tagContext(windows/arm64) = %s/%s", ctxt.GOOS, ctxt.GOARCH)
+	}
+	if ctxt := tagContext("cgo"); !ctxt.CgoEnabled {
+		t.Error("tagContext(cgo) should enable cgo")
+	}
+	if ctxt := tagContext("purego"); !slices.Contains(ctxt.BuildTags, "purego") {
+		t.Errorf("tagContext(purego) tags = %v", ctxt.BuildTags)
+	}
+}
+
+func TestHandleCompareBuildTags(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":          "module example.com/hash\n\ngo 1.21\n",
+		"hash.go":         "package hash\n\n// Sum hashes b.\nfunc Sum(b []byte) uint64 { return sum(b) }\n",
+		"hash_generic.go": "//go:build purego\n\npackage hash\n\nfunc sum(b []byte) uint64 { return 0 }\n\n// Generic reports that the pure Go implementation is in use.\nconst Generic = true\n",
+		"hash_asm.go":     "//go:build !purego\n\npackage hash\n\nfunc sum(b []byte) uint64 { return 1 }\n\n// Accelerated hashes using assembly.\nfunc Accelerated(b []byte) uint64 { return 1 }\n",
+	})
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "compare_build_tags"
+	req.Params.Arguments = map[string]any{"path": ".", "tags": "purego, netgo", "working_dir": dir}
+	result, err := gs.handleCompareBuildTags(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleCompareBuildTags returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleCompareBuildTags returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"2 exported symbols",
+		"purego:\n  - func Accelerated(b []byte) uint64\n  + const Generic = true\n",
+		"netgo: no change",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+}
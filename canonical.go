@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/build"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const canonicalImportDescription = `Find the canonical import path of a Go package.
+Reports the path declared by the package's import comment
+(package foo // import "example.com/foo"), if any, and the module that
+provides the package, including any replace directive that swaps in a fork.
+Use this when a package was reached through a mirror, fork, or vanity path to
+learn the path code should import.`
+
+func (gs *godocServer) handleCanonicalImport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dir, err := gs.packageDir(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	comment, err := importComment(dir)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Import path: %s\n", pkgPath)
+	if comment == "" {
+		b.WriteString("Import comment: none\n")
+	} else {
+		fmt.Fprintf(&b, "Import comment: %q\n", comment)
+	}
+	if modPath, replace := gs.packageModule(ctx, workingDir, pkgPath); modPath != "" {
+		fmt.Fprintf(&b, "Module: %s\n", modPath)
+		if replace != "" {
+			fmt.Fprintf(&b, "Replaced by: %s (code still imports %s)\n", replace, modPath)
+		}
+	}
+
+	b.WriteString("\n")
+	switch {
+	case comment == "":
+		fmt.Fprintf(&b, "The package declares no canonical path; %s is the path to import.\n", pkgPath)
+	case comment == pkgPath:
+		fmt.Fprintf(&b, "%s is the canonical import path.\n", pkgPath)
+	default:
+		fmt.Fprintf(&b, "%s is not the canonical import path; import %s instead.\n", pkgPath, comment)
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// importComment returns the path declared by the import comment of the
+// package in dir, or "" if it has none. The go command enforces these
+// comments only in GOPATH mode, so under modules they are advisory.
+func importComment(dir string) (string, error) {
+	bp, err := build.Default.ImportDir(dir, build.ImportComment)
+	if err != nil {
+		if _, ok := err.(*build.NoGoError); ok {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read package in %s: %w", dir, err)
+	}
+	return bp.ImportComment, nil
+}
+
+// packageModule returns the path of the module providing importPath as seen
+// from workingDir and, if a replace directive substitutes it, the
+// replacement's path. Both are "" for the standard library or on error.
+func (gs *godocServer) packageModule(ctx context.Context, workingDir, importPath string) (path, replace string) {
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	cmd := gs.goCommand(listCtx, workingDir, "list", "-e", "-f", "{{with .Module}}{{.Path}} {{with .Replace}}{{.Path}}{{end}}{{end}}", importPath)
+	out, err := gs.output(listCtx, cmd)
+	if err != nil {
+		return "", ""
+	}
+	path, replace, _ = strings.Cut(strings.TrimSpace(string(out)), " ")
+	return path, replace
+}
+
+// canonicalImportNote warns that importPath is not the path its import
+// comment declares, for package-level get_doc results.
+func (gs *godocServer) canonicalImportNote(ctx context.Context, workingDir, importPath string) string {
+	dir, err := gs.packageDir(ctx, workingDir, importPath)
+	if err != nil {
+		return ""
+	}
+	comment, err := importComment(dir)
+	if err != nil || comment == "" || comment == importPath {
+		return ""
+	}
+	return fmt.Sprintf("\nNOTE: this package declares its canonical import path as %q; import it by that path rather than %s.\n", comment, importPath)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestImportComment(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"a.go": "package legacy // import \"github.com/old/legacy\"\n",
+		"b.go": "package legacy\n",
+	})
+	got, err := importComment(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "github.com/old/legacy" {
+		t.Errorf("importComment = %q, want github.com/old/legacy", got)
+	}
+
+	if got, err := importComment(t.TempDir()); err != nil || got != "" {
+		t.Errorf("importComment of an empty directory = (%q, %v), want no comment", got, err)
+	}
+}
+
+func TestHandleCanonicalImport(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	root := writeModule(t, map[string]string{
+		"app/go.mod":         "module example.com/app\n\ngo 1.21\n\nrequire example.com/orig v0.0.0\n\nreplace example.com/orig => ../fork\n",
+		"app/main.go":        "package main\n\nimport _ \"example.com/orig\"\n\nfunc main() {}\n",
+		"app/legacy/l.go":    "// Package legacy moved here.\npackage legacy // import \"github.com/old/legacy\"\n\n// X is exported.\nfunc X() {}\n",
+		"fork/go.mod":        "module example.com/orig\n\ngo 1.21\n",
+		"fork/orig.go":       "package orig // import \"example.com/orig\"\n",
+		"app/plain/plain.go": "package plain\n\nfunc P() {}\n",
+	})
+	app := filepath.Join(root, "app")
+
+	gs := newGodocServer()
+	call := func(tool string, args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = tool
+		req.Params.Arguments = args
+		handle := gs.handleCanonicalImport
+		if tool == "get_doc" {
+			handle = gs.handleGetDoc
+		}
+		result, err := handle(context.Background(), req)
+		if err != nil {
+			t.Fatalf("%s returned protocol error: %v", tool, err)
+		}
+		if result.IsError {
+			t.Fatalf("%s returned tool error: %+v", tool, result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := call("canonical_import", map[string]any{"path": "./legacy", "working_dir": app})
+	if !strings.Contains(text, `Import comment: "github.com/old/legacy"`) || !strings.Contains(text, "import github.com/old/legacy instead") {
+		t.Errorf("expected a non-canonical verdict:\n%s", text)
+	}
+
+	text = call("canonical_import", map[string]any{"path": "example.com/orig", "working_dir": app})
+	for _, want := range []string{"Module: example.com/orig", "Replaced by: ../fork", "example.com/orig is the canonical import path."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q:\n%s", want, text)
+		}
+	}
+
+	if text := call("canonical_import", map[string]any{"path": "./plain", "working_dir": app}); !strings.Contains(text, "declares no canonical path") {
+		t.Errorf("expected no import comment:\n%s", text)
+	}
+
+	text = call("get_doc", map[string]any{"path": "./legacy", "working_dir": app})
+	if !strings.Contains(text, `declares its canonical import path as "github.com/old/legacy"`) {
+		t.Errorf("expected get_doc to note the canonical path:\n%s", text)
+	}
+}
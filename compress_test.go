@@ -0,0 +1,136 @@
+package main
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestAcceptedEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                          "",
+		"identity":                  "",
+		"gzip":                      "gzip",
+		"deflate":                   "deflate",
+		"deflate, gzip":             "gzip",
+		"gzip;q=0.5, deflate":       "deflate",
+		"GZIP;q=0.8, deflate;q=0.8": "gzip",
+		"gzip;q=0, br":              "",
+		"br, deflate;q=0.1":         "deflate",
+	}
+	for header, want := range tests {
+		if got := acceptedEncoding(header); got != want {
+			t.Errorf("acceptedEncoding(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCompressHandler(t *testing.T) {
+	body := `{"jsonrpc":"2.0","result":"` + strings.Repeat("documentation ", 200) + `"}`
+	serve := func(contentType, acceptEncoding string) *httptest.ResponseRecorder {
+		t.Helper()
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Content-Length", "12345")
+			io.WriteString(w, body)
+		})
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		compressHandler(next).ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("gzip", func(t *testing.T) {
+		rec := serve("application/json", "gzip")
+		if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+			t.Fatalf("Content-Encoding = %q, want gzip", got)
+		}
+		if rec.Header().Get("Content-Length") != "" {
+			t.Error("stale Content-Length kept on compressed response")
+		}
+		zr, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("gzip.NewReader: %v", err)
+		}
+		got, _ := io.ReadAll(zr)
+		if string(got) != body {
+			t.Errorf("decompressed body does not match")
+		}
+	})
+
+	t.Run("deflate", func(t *testing.T) {
+		rec := serve("application/json; charset=utf-8", "deflate")
+		if got := rec.Header().Get("Content-Encoding"); got != "deflate" {
+			t.Fatalf("Content-Encoding = %q, want deflate", got)
+		}
+		zr, err := zlib.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("zlib.NewReader: %v", err)
+		}
+		got, _ := io.ReadAll(zr)
+		if string(got) != body {
+			t.Errorf("decompressed body does not match")
+		}
+	})
+
+	t.Run("event stream", func(t *testing.T) {
+		rec := serve("text/event-stream", "gzip")
+		if got := rec.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("event stream compressed with %q", got)
+		}
+		if rec.Body.String() != body {
+			t.Error("event stream body altered")
+		}
+	})
+
+	t.Run("not accepted", func(t *testing.T) {
+		rec := serve("application/json", "")
+		if got := rec.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("Content-Encoding = %q without Accept-Encoding", got)
+		}
+		if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("Vary = %q, want Accept-Encoding", got)
+		}
+	})
+}
+
+func TestCompressHandlerStreamableHTTP(t *testing.T) {
+	gs := newGodocServer()
+	ts := httptest.NewServer(compressHandler(server.NewStreamableHTTPServer(gs.mcpServer)))
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("initialize request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("reading compressed body: %v", err)
+	}
+	if !strings.Contains(string(got), `"serverInfo"`) {
+		t.Errorf("unexpected initialize response: %s", got)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const concurrencyNotesDescription = `Summarize the thread-safety of a Go type, or of every exported type in a
+package. Consolidates the signals needed to use a type correctly from several
+goroutines: doc comments saying it is (or is not) safe for concurrent use,
+doc sentences on the type and its methods that mention goroutines or
+synchronization, and sync.Mutex, sync.RWMutex, atomic, and channel fields that
+suggest the type guards its own state. Types with no signals follow the Go
+convention of being unsafe for concurrent use without external locking.`
+
+var (
+	notSafePattern     = regexp.MustCompile(`(?i)\bnot (?:safe for (?:concurrent|simultaneous|parallel) use|safe to use concurrently|(?:goroutine|thread|concurrency)[- ]safe)|\b(?:must|should) not be (?:used|called|accessed) (?:concurrently|simultaneously|from multiple goroutines)|\bunsafe for concurrent use`)
+	safePattern        = regexp.MustCompile(`(?i)\bsafe for (?:concurrent|simultaneous|parallel) use|\bsafe to (?:use|call) (?:concurrently|from multiple goroutines)|\b(?:goroutine|thread|concurrency)[- ]safe\b|\b(?:may|can) be (?:used|called) (?:concurrently|simultaneously|from multiple goroutines)`)
+	concurrencyMention = regexp.MustCompile(`(?i)concurren|goroutine|thread[- ]safe|simultaneous|synchroni[sz]|data race|must not be copied`)
+)
+
+// concurrencyReport is the thread-safety evidence gathered for one type.
+type concurrencyReport struct {
+	verdict  string
+	quote    string   // the sentence that settled a documented verdict
+	fields   []string // synchronization fields, "name type  // role"
+	mentions []string // other doc sentences about concurrency
+}
+
+func (gs *godocServer) handleConcurrencyNotes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	target := request.GetString("target", "")
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// go/doc strips unexported fields from the AST, and the mutex guarding a
+	// type's state is usually unexported, so collect fields first.
+	fields := make(map[string][]string)
+	for _, f := range lp.files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if st, ok := ts.Type.(*ast.StructType); ok && ts.Name.IsExported() {
+					fields[ts.Name.Name] = syncFields(lp.fset, st, pkgPath)
+				}
+			}
+		}
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var b strings.Builder
+	if target != "" {
+		var t *doc.Type
+		for _, dt := range dp.Types {
+			if dt.Name == target {
+				t = dt
+			}
+		}
+		if t == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("type %s not found in %s", target, pkgPath)), nil
+		}
+		r := concurrencySignals(t, fields[t.Name])
+		fmt.Fprintf(&b, "Concurrency notes for %s.%s\n\nVerdict: %s\n", pkgPath, target, r.verdict)
+		if r.quote != "" {
+			fmt.Fprintf(&b, "    %s\n", r.quote)
+		}
+		if len(r.fields) > 0 {
+			b.WriteString("\nSYNCHRONIZATION FIELDS\n\n" + strings.Join(r.fields, "\n") + "\n")
+		}
+		if len(r.mentions) > 0 {
+			b.WriteString("\nRELATED DOC SENTENCES\n\n" + strings.Join(r.mentions, "\n") + "\n")
+		}
+		return mcp.NewToolResultText(b.String()), nil
+	}
+
+	fmt.Fprintf(&b, "Concurrency notes for the exported types of %s\n\n", pkgPath)
+	for _, t := range dp.Types {
+		if !ast.IsExported(t.Name) {
+			continue
+		}
+		r := concurrencySignals(t, fields[t.Name])
+		fmt.Fprintf(&b, "%s: %s\n", t.Name, r.verdict)
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// concurrencySignals weighs the doc comments of t and its methods, and the
+// synchronization fields found in its declaration, into a verdict.
+func concurrencySignals(t *doc.Type, fields []string) concurrencyReport {
+	r := concurrencyReport{fields: fields}
+	var safe, notSafe string
+	check := func(prefix, text string) {
+		for _, s := range sentences(text) {
+			switch {
+			case notSafePattern.MatchString(s):
+				if notSafe == "" {
+					notSafe = s
+					continue
+				}
+			case safePattern.MatchString(s):
+				if safe == "" {
+					safe = s
+					continue
+				}
+			case !concurrencyMention.MatchString(s):
+				continue
+			}
+			r.mentions = append(r.mentions, prefix+s)
+		}
+	}
+	check("", t.Doc)
+	for _, m := range t.Methods {
+		if ast.IsExported(m.Name) {
+			check(m.Name+": ", m.Doc)
+		}
+	}
+
+	switch {
+	case notSafe != "":
+		r.verdict, r.quote = "documented as NOT safe for concurrent use", notSafe
+	case safe != "":
+		r.verdict, r.quote = "documented as safe for concurrent use", safe
+	case len(fields) > 0:
+		r.verdict = "not documented; its synchronization fields suggest it guards its own state, but confirm before sharing it between goroutines"
+	default:
+		r.verdict = "no concurrency documentation or synchronization fields; by Go convention, assume it needs external locking"
+	}
+	return r
+}
+
+// syncFields lists the fields of st whose types synchronize access: mutexes,
+// sync primitives, atomics, and channels. importPath qualifies the names
+// when the type is declared in sync or sync/atomic itself.
+func syncFields(fset *token.FileSet, st *ast.StructType, importPath string) []string {
+	var out []string
+	for _, field := range st.Fields.List {
+		typ := nodeString(fset, field.Type)
+		bare := strings.TrimPrefix(typ, "*")
+		if importPath == "sync" || importPath == "sync/atomic" {
+			bare = importPath[strings.LastIndexByte(importPath, '/')+1:] + "." + bare
+		}
+		var role string
+		switch {
+		case bare == "sync.Mutex" || bare == "sync.RWMutex":
+			role = "lock guarding the type's state"
+		case bare == "sync.Once":
+			role = "one-time initialization"
+		case bare == "sync.WaitGroup" || bare == "sync.Cond":
+			role = "goroutine coordination"
+		case bare == "sync.Map" || bare == "sync.Pool":
+			role = "concurrent-safe container"
+		case strings.HasPrefix(bare, "atomic."):
+			role = "atomically accessed value"
+		case strings.HasPrefix(typ, "chan ") || strings.HasPrefix(typ, "<-chan ") || strings.HasPrefix(typ, "chan<- "):
+			role = "channel"
+		case strings.HasSuffix(bare, "noCopy"):
+			role = "must not be copied after first use (checked by go vet)"
+		default:
+			continue
+		}
+		names := make([]string, 0, len(field.Names))
+		for _, id := range field.Names {
+			names = append(names, id.Name)
+		}
+		name := strings.Join(names, ", ")
+		if name == "" {
+			name = embeddedName(field.Type) + " (embedded)"
+		}
+		out = append(out, fmt.Sprintf("%s %s  // %s", name, typ, role))
+	}
+	return out
+}
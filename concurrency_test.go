@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleConcurrencyNotes(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/store\n\ngo 1.21\n",
+		"store.go": `// Package store keeps things.
+package store
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Store is a key-value store. It is safe for concurrent use by multiple goroutines.
+type Store struct {
+	mu   sync.RWMutex
+	data map[string]string
+	hits atomic.Int64
+}
+
+// Get returns the value for key.
+func (s *Store) Get(key string) string { return "" }
+
+// Buffer accumulates bytes. A Buffer is not safe for concurrent use.
+type Buffer struct{ b []byte }
+
+// Counter counts events.
+type Counter struct {
+	sync.Mutex
+	n    int
+	done chan struct{}
+}
+
+// Reset zeroes the counter. It must not be called while other goroutines hold the lock.
+func (c *Counter) Reset() {}
+
+// Point is a plain value.
+type Point struct{ X, Y int }
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "concurrency_notes"
+		req.Params.Arguments = args
+		result, err := gs.handleConcurrencyNotes(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleConcurrencyNotes returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleConcurrencyNotes returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	t.Run("type", func(t *testing.T) {
+		text := call(map[string]any{"path": ".", "target": "Store", "working_dir": dir})
+		for _, want := range []string{
+			"Verdict: documented as safe for concurrent use\n",
+			"    It is safe for concurrent use by multiple goroutines.",
+			"mu sync.RWMutex  // lock guarding the type's state",
+			"hits atomic.Int64  // atomically accessed value",
+		} {
+			if !strings.Contains(text, want) {
+				t.Errorf("missing %q in:\n%s", want, text)
+			}
+		}
+		if strings.Contains(text, "data map") {
+			t.Errorf("plain field reported as synchronization:\n%s", text)
+		}
+	})
+
+	t.Run("embedded mutex", func(t *testing.T) {
+		text := call(map[string]any{"path": ".", "target": "Counter", "working_dir": dir})
+		for _, want := range []string{
+			"Verdict: not documented; its synchronization fields suggest it guards its own state",
+			"Mutex (embedded) sync.Mutex  // lock guarding the type's state",
+			"done chan struct{}  // channel",
+			"RELATED DOC SENTENCES",
+			"Reset: It must not be called while other goroutines hold the lock.",
+		} {
+			if !strings.Contains(text, want) {
+				t.Errorf("missing %q in:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("package", func(t *testing.T) {
+		text := call(map[string]any{"path": ".", "working_dir": dir})
+		for _, want := range []string{
+			"Buffer: documented as NOT safe for concurrent use\n",
+			"Counter: not documented;",
+			"Point: no concurrency documentation",
+			"Store: documented as safe for concurrent use\n",
+		} {
+			if !strings.Contains(text, want) {
+				t.Errorf("missing %q in:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]any{"path": ".", "target": "Missing", "working_dir": dir}
+		result, err := gs.handleConcurrencyNotes(context.Background(), req)
+		if err != nil || !result.IsError {
+			t.Fatalf("expected tool error, got %+v, %v", result, err)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// docArgs are the get_doc arguments that can contradict one another.
+type docArgs struct {
+	targets        []string // [""] for a package query
+	cmdFlags       []string
+	signatureOnly  bool
+	synopsis       bool
+	exclude        bool
+	fieldFilter    bool // fields or documented_fields
+	resolveAliases bool
+	returnedType   bool
+}
+
+func (a docArgs) hasFlag(f string) bool { return slices.Contains(a.cmdFlags, f) }
+
+// symbolQuery reports whether a names target symbols rather than the package.
+func (a docArgs) symbolQuery() bool { return a.targets[0] != "" }
+
+// memberTarget reports whether a targets a method or field ("Type.Name").
+func (a docArgs) memberTarget() bool {
+	return slices.ContainsFunc(a.targets, func(t string) bool { return strings.Contains(t, ".") })
+}
+
+// docArgConflicts is the matrix of get_doc argument combinations that are
+// rejected before any go command runs, because go doc would refuse them,
+// silently ignore part of them, or the server would.
+var docArgConflicts = []struct {
+	conflicts func(docArgs) bool
+	reason    string
+}{
+	{
+		func(a docArgs) bool { return a.signatureOnly && !a.symbolQuery() },
+		"signature_only requires a target symbol",
+	},
+	{
+		func(a docArgs) bool { return a.signatureOnly && (a.hasFlag("-src") || a.hasFlag("-all")) },
+		"signature_only cannot be combined with -src or -all",
+	},
+	{
+		func(a docArgs) bool { return a.synopsis && a.signatureOnly },
+		"synopsis cannot be combined with signature_only: signature_only drops the doc sentence synopsis keeps",
+	},
+	{
+		func(a docArgs) bool {
+			return a.synopsis && slices.ContainsFunc(a.cmdFlags, func(f string) bool { return f != "-c" })
+		},
+		"synopsis cannot be combined with -all, -src, -short, or -u: it renders exported declarations with their first sentence itself",
+	},
+	{
+		func(a docArgs) bool { return a.exclude && a.symbolQuery() },
+		"exclude cannot be combined with a target symbol: it leaves symbols out of a package listing",
+	},
+	{
+		func(a docArgs) bool { return a.fieldFilter && (!a.symbolQuery() || a.memberTarget()) },
+		"fields and documented_fields require a struct type target",
+	},
+	{
+		func(a docArgs) bool { return a.fieldFilter && (a.signatureOnly || a.synopsis) },
+		"fields and documented_fields cannot be combined with signature_only or synopsis",
+	},
+	{
+		func(a docArgs) bool { return a.hasFlag("-all") && a.hasFlag("-short") },
+		"-all cannot be combined with -short: -all prints every declaration with its documentation, -short one line per declaration",
+	},
+	{
+		func(a docArgs) bool { return a.hasFlag("-src") && a.hasFlag("-short") },
+		"-src cannot be combined with -short: go doc ignores -src when listing one-line declarations",
+	},
+	{
+		func(a docArgs) bool { return a.hasFlag("-all") && a.memberTarget() },
+		"-all cannot be combined with a method or field target (Type.Name): it expands package and type documentation; target the type instead",
+	},
+	{
+		func(a docArgs) bool { return a.hasFlag("-c") && !a.symbolQuery() },
+		"-c requires a target symbol: it only changes how target names are matched",
+	},
+	{
+		func(a docArgs) bool { return a.resolveAliases && !a.symbolQuery() },
+		"resolve_aliases requires a target symbol",
+	},
+	{
+		func(a docArgs) bool { return a.returnedType && !a.symbolQuery() },
+		"include_returned_type requires a target function or method",
+	},
+}
+
+// validateDocArgs returns an error describing the first conflict in a.
+func validateDocArgs(a docArgs) error {
+	for _, c := range docArgConflicts {
+		if c.conflicts(a) {
+			return errors.New(c.reason)
+		}
+	}
+	return nil
+}
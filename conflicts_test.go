@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestValidateDocArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args docArgs
+		want string // substring of the error, or "" for none
+	}{
+		{"package", docArgs{targets: []string{""}}, ""},
+		{"all on package", docArgs{targets: []string{""}, cmdFlags: []string{"-all"}}, ""},
+		{"all on type", docArgs{targets: []string{"Buffer"}, cmdFlags: []string{"-all"}}, ""},
+		{"short on symbol", docArgs{targets: []string{"Copy"}, cmdFlags: []string{"-short"}}, ""},
+		{"src on method", docArgs{targets: []string{"Buffer.Len"}, cmdFlags: []string{"-src", "-u"}}, ""},
+		{"case-sensitive symbol", docArgs{targets: []string{"reader"}, cmdFlags: []string{"-c"}}, ""},
+		{"signature of symbol", docArgs{targets: []string{"Copy"}, signatureOnly: true, cmdFlags: []string{"-u"}}, ""},
+		{"synopsis of package", docArgs{targets: []string{""}, synopsis: true}, ""},
+		{"case-sensitive synopsis", docArgs{targets: []string{"Reader"}, synopsis: true, cmdFlags: []string{"-c"}}, ""},
+		{"exclude from package", docArgs{targets: []string{""}, exclude: true, cmdFlags: []string{"-all", "-u"}}, ""},
+		{"fields of struct", docArgs{targets: []string{"Config"}, fieldFilter: true, cmdFlags: []string{"-src"}}, ""},
+
+		{"signature without target", docArgs{targets: []string{""}, signatureOnly: true}, "signature_only requires a target symbol"},
+		{"signature with src", docArgs{targets: []string{"Copy"}, signatureOnly: true, cmdFlags: []string{"-src"}}, "signature_only cannot be combined with -src or -all"},
+		{"signature with all", docArgs{targets: []string{"Copy"}, signatureOnly: true, cmdFlags: []string{"-all"}}, "signature_only cannot be combined with -src or -all"},
+		{"synopsis with signature", docArgs{targets: []string{"Copy"}, synopsis: true, signatureOnly: true}, "synopsis cannot be combined with signature_only"},
+		{"synopsis with short", docArgs{targets: []string{""}, synopsis: true, cmdFlags: []string{"-short"}}, "synopsis cannot be combined with -all, -src, -short, or -u"},
+		{"exclude with target", docArgs{targets: []string{"Reader"}, exclude: true}, "exclude cannot be combined with a target symbol"},
+		{"fields of package", docArgs{targets: []string{""}, fieldFilter: true}, "fields and documented_fields require a struct type target"},
+		{"fields of field", docArgs{targets: []string{"Config.Timeout"}, fieldFilter: true}, "fields and documented_fields require a struct type target"},
+		{"fields with synopsis", docArgs{targets: []string{"Config"}, fieldFilter: true, synopsis: true}, "fields and documented_fields cannot be combined with signature_only or synopsis"},
+		{"all with short", docArgs{targets: []string{""}, cmdFlags: []string{"-all", "-short"}}, "-all cannot be combined with -short"},
+		{"src with short", docArgs{targets: []string{"Reader"}, cmdFlags: []string{"-short", "-src"}}, "-src cannot be combined with -short"},
+		{"all on method", docArgs{targets: []string{"Reader", "Buffer.Len"}, cmdFlags: []string{"-all"}}, "-all cannot be combined with a method or field target"},
+		{"c on package", docArgs{targets: []string{""}, cmdFlags: []string{"-c"}}, "-c requires a target symbol"},
+		{"aliases on package", docArgs{targets: []string{""}, resolveAliases: true}, "resolve_aliases requires a target symbol"},
+		{"returned type of package", docArgs{targets: []string{""}, returnedType: true}, "include_returned_type requires a target"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDocArgs(tt.args)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("validateDocArgs() = %v, want nil", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("validateDocArgs() = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleGetDocConflictingArgs(t *testing.T) {
+	// A fake go command that fails proves nothing ran before the conflict
+	// was reported.
+	fakeGo(t, "echo \"go should not run: $*\" >&2; exit 1\n")
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_doc"
+	req.Params.Arguments = map[string]any{"path": "bytes.Buffer.Len", "cmd_flags": []any{"-all"}}
+	result, err := gs.handleGetDoc(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleGetDoc returned protocol error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !result.IsError || !strings.Contains(text, "-all cannot be combined with a method or field target") {
+		t.Errorf("expected a conflict error, got %q", text)
+	}
+}
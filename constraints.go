@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const buildConstraintsDescription = `List the build constraints that decide which files of a Go package are
+compiled: every //go:build line and every _GOOS, _GOARCH, or _GOOS_GOARCH
+file name suffix. The tags they mention are grouped into operating systems,
+architectures, Go versions, toolchain tags (cgo, gc, gccgo), and other tags
+such as purego, followed by each constrained file and whether the default
+build excludes it. Use this to learn which tags and platforms matter before
+calling compare_build_tags, instead of guessing.`
+
+// constrainedFile is a package file with the constraint that selects it.
+type constrainedFile struct {
+	name     string
+	expr     string // the //go:build expression, or ""
+	fileTags []string
+	excluded bool // by the default build context
+}
+
+// knownPlatforms returns the GOOS and GOARCH values the go command matches in
+// file names, read from the toolchain's internal/syslist source, or empty
+// sets if it is unavailable.
+var knownPlatforms = sync.OnceValues(func() (oses, arches map[string]bool) {
+	oses, arches = make(map[string]bool), make(map[string]bool)
+	file := filepath.Join(build.Default.GOROOT, "src", "internal", "syslist", "syslist.go")
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return oses, arches
+	}
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if len(vs.Names) != 1 || len(vs.Values) != 1 {
+				continue
+			}
+			var set map[string]bool
+			switch vs.Names[0].Name {
+			case "KnownOS":
+				set = oses
+			case "KnownArch":
+				set = arches
+			default:
+				continue
+			}
+			lit, ok := vs.Values[0].(*ast.CompositeLit)
+			if !ok {
+				continue
+			}
+			for _, elt := range lit.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if key, ok := kv.Key.(*ast.BasicLit); ok && key.Kind == token.STRING {
+						set[strings.Trim(key.Value, `"`)] = true
+					}
+				}
+			}
+		}
+	}
+	return oses, arches
+})
+
+// crossArch is the architecture suggested for a GOOS that has no amd64 port.
+var crossArch = map[string]string{"aix": "ppc64", "ios": "arm64", "js": "wasm", "wasip1": "wasm", "zos": "s390x"}
+
+func (gs *godocServer) handleBuildConstraints(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dir, err := gs.packageDir(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	files, total, err := packageConstraints(dir)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Build constraints of %s in %s\n", pkgPath, dir)
+	if len(files) == 0 {
+		fmt.Fprintf(&b, "None of its %d files has a build constraint; every build includes them all.\n", total)
+		return mcp.NewToolResultText(b.String()), nil
+	}
+	excluded := 0
+	for _, f := range files {
+		if f.excluded {
+			excluded++
+		}
+	}
+	fmt.Fprintf(&b, "%d of %d files are constrained; the default build (%s/%s) excludes %d.\n",
+		len(files), total, build.Default.GOOS, build.Default.GOARCH, excluded)
+
+	groups := constraintTags(files)
+	for _, g := range groups {
+		if len(g.tags) > 0 {
+			fmt.Fprintf(&b, "\n%s: %s", g.title, strings.Join(g.tags, ", "))
+		}
+	}
+	b.WriteString("\n\nFILES\n\n")
+	for _, f := range files {
+		var parts []string
+		if f.expr != "" {
+			parts = append(parts, "//go:build "+f.expr)
+		}
+		if len(f.fileTags) > 0 {
+			parts = append(parts, strings.Join(f.fileTags, " && ")+" (file name)")
+		}
+		line := f.name + ": " + strings.Join(parts, "; ")
+		if f.excluded {
+			line += "  [excluded by default]"
+		}
+		b.WriteString(line + "\n")
+	}
+	if candidates := compareCandidates(groups); len(candidates) > 0 {
+		fmt.Fprintf(&b, "\nTo see how these change the exported API, call compare_build_tags with tags %q.\n", strings.Join(candidates, ","))
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// packageConstraints returns the Go files in dir that carry a build
+// constraint, in name order, and the number of Go files the go command
+// considers at all (names starting with "_" or "." are always ignored).
+func packageConstraints(dir string) ([]constrainedFile, int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read package directory: %w", err)
+	}
+	var files []constrainedFile
+	total := 0
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") {
+			continue
+		}
+		total++
+		expr, err := fileConstraint(filepath.Join(dir, name))
+		if err != nil {
+			return nil, 0, err
+		}
+		fileTags := fileNameTags(name)
+		if expr == "" && len(fileTags) == 0 {
+			continue
+		}
+		match, _ := build.Default.MatchFile(dir, name)
+		files = append(files, constrainedFile{name: name, expr: expr, fileTags: fileTags, excluded: !match})
+	}
+	return files, total, nil
+}
+
+// fileConstraint returns the build expression of the //go:build line (or
+// legacy // +build lines) in the header of file, or "" if it has none.
+func fileConstraint(file string) (string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", filepath.Base(file), err)
+	}
+	var plus []constraint.Expr
+	for _, group := range f.Comments {
+		if group.Pos() > f.Package {
+			break
+		}
+		for _, c := range group.List {
+			switch {
+			case constraint.IsGoBuild(c.Text):
+				if x, err := constraint.Parse(c.Text); err == nil {
+					return x.String(), nil
+				}
+			case constraint.IsPlusBuild(c.Text):
+				if x, err := constraint.Parse(c.Text); err == nil {
+					plus = append(plus, x)
+				}
+			}
+		}
+	}
+	if len(plus) == 0 {
+		return "", nil
+	}
+	x := plus[0]
+	for _, y := range plus[1:] {
+		x = &constraint.AndExpr{X: x, Y: y}
+	}
+	return x.String(), nil
+}
+
+// fileNameTags returns the GOOS and GOARCH implied by a file name suffix such
+// as _windows.go, _arm64.go, or _linux_amd64_test.go, following the go
+// command's rules.
+func fileNameTags(name string) []string {
+	name = strings.TrimSuffix(name, ".go")
+	i := strings.Index(name, "_")
+	if i < 0 {
+		return nil
+	}
+	l := strings.Split(name[i:], "_")
+	if n := len(l); n > 0 && l[n-1] == "test" {
+		l = l[:n-1]
+	}
+	oses, arches := knownPlatforms()
+	n := len(l)
+	switch {
+	case n >= 2 && oses[l[n-2]] && arches[l[n-1]]:
+		return []string{l[n-2], l[n-1]}
+	case n >= 1 && (oses[l[n-1]] || arches[l[n-1]]):
+		return []string{l[n-1]}
+	}
+	return nil
+}
+
+// constraintGroup is one category of build tags.
+type constraintGroup struct {
+	title string
+	tags  []string
+}
+
+// constraintTags collects the tags that files' constraints mention, sorted
+// within the categories operating systems, architectures, Go versions,
+// toolchain, and other tags.
+func constraintTags(files []constrainedFile) []constraintGroup {
+	oses, arches := knownPlatforms()
+	groups := []constraintGroup{
+		{title: "OPERATING SYSTEMS"},
+		{title: "ARCHITECTURES"},
+		{title: "GO VERSIONS"},
+		{title: "TOOLCHAIN"},
+		{title: "OTHER TAGS"},
+	}
+	add := func(tag string) {
+		var i int
+		switch {
+		case oses[tag] || tag == "unix":
+			i = 0
+		case arches[tag]:
+			i = 1
+		case constraint.GoVersion(&constraint.TagExpr{Tag: tag}) != "":
+			i = 2
+		case tag == "cgo" || tag == "gc" || tag == "gccgo":
+			i = 3
+		default:
+			i = 4
+		}
+		if !slices.Contains(groups[i].tags, tag) {
+			groups[i].tags = append(groups[i].tags, tag)
+		}
+	}
+	for _, f := range files {
+		if f.expr != "" {
+			x, err := constraint.Parse("//go:build " + f.expr)
+			if err == nil {
+				walkTags(x, add)
+			}
+		}
+		for _, tag := range f.fileTags {
+			add(tag)
+		}
+	}
+	for i := range groups {
+		slices.Sort(groups[i].tags)
+	}
+	return groups
+}
+
+// walkTags calls fn for each tag in x.
+func walkTags(x constraint.Expr, fn func(string)) {
+	switch x := x.(type) {
+	case *constraint.TagExpr:
+		fn(x.Tag)
+	case *constraint.NotExpr:
+		walkTags(x.X, fn)
+	case *constraint.AndExpr:
+		walkTags(x.X, fn)
+		walkTags(x.Y, fn)
+	case *constraint.OrExpr:
+		walkTags(x.X, fn)
+		walkTags(x.Y, fn)
+	}
+}
+
+// compareCandidates turns grouped tags into compare_build_tags candidates:
+// other tags and cgo as they are, and each operating system or architecture
+// that differs from the default build as a GOOS/GOARCH pair.
+func compareCandidates(groups []constraintGroup) []string {
+	var candidates []string
+	for _, tag := range groups[0].tags {
+		if tag == "unix" || tag == build.Default.GOOS {
+			continue
+		}
+		arch, ok := crossArch[tag]
+		if !ok {
+			arch = "amd64"
+		}
+		candidates = append(candidates, tag+"/"+arch)
+	}
+	for _, tag := range groups[1].tags {
+		if tag != build.Default.GOARCH {
+			candidates = append(candidates, build.Default.GOOS+"/"+tag)
+		}
+	}
+	if slices.Contains(groups[3].tags, "cgo") {
+		candidates = append(candidates, "cgo")
+	}
+	for _, tag := range groups[4].tags {
+		if tag != "ignore" {
+			candidates = append(candidates, tag)
+		}
+	}
+	return candidates
+}
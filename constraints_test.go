@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"go/build"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestFileNameTags(t *testing.T) {
+	if oses, _ := knownPlatforms(); !oses["linux"] {
+		t.Skip("toolchain source does not list known platforms")
+	}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"file.go", nil},
+		{"linux.go", nil},
+		{"file_linux.go", []string{"linux"}},
+		{"file_arm64.go", []string{"arm64"}},
+		{"file_windows_amd64.go", []string{"windows", "amd64"}},
+		{"file_darwin_test.go", []string{"darwin"}},
+		{"file_amd64_linux.go", []string{"linux"}},
+		{"file_custom.go", nil},
+	}
+	for _, tt := range tests {
+		if got := fileNameTags(tt.name); !slices.Equal(got, tt.want) {
+			t.Errorf("fileNameTags(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHandleBuildConstraints(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+	if oses, _ := knownPlatforms(); !oses["linux"] || build.Default.GOOS != "linux" || build.Default.GOARCH != "amd64" {
+		t.Skip("expectations assume a linux/amd64 default build")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":            "module example.com/hash\n\ngo 1.21\n",
+		"hash.go":           "package hash\n\nfunc Sum() {}\n",
+		"hash_amd64.go":     "//go:build !purego\n\npackage hash\n\nfunc sumAsm() {}\n",
+		"hash_generic.go":   "//go:build purego || !(amd64 || arm64)\n\npackage hash\n\nfunc sumGeneric() {}\n",
+		"hash_windows.go":   "package hash\n\nfunc lock() {}\n",
+		"legacy.go":         "// +build go1.22,!gccgo\n\npackage hash\n\nfunc legacy() {}\n",
+		"hash_unix_test.go": "//go:build unix\n\npackage hash\n",
+	})
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "build_constraints"
+	req.Params.Arguments = map[string]any{"path": ".", "working_dir": dir}
+	result, err := gs.handleBuildConstraints(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleBuildConstraints returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleBuildConstraints returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"5 of 6 files are constrained; the default build (linux/amd64) excludes 2.",
+		"OPERATING SYSTEMS: unix, windows\nARCHITECTURES: amd64, arm64\nGO VERSIONS: go1.22\nTOOLCHAIN: gccgo\nOTHER TAGS: purego\n",
+		"hash_amd64.go: //go:build !purego; amd64 (file name)\n",
+		"hash_generic.go: //go:build purego || !(amd64 || arm64)  [excluded by default]\n",
+		"hash_unix_test.go: //go:build unix\n",
+		"hash_windows.go: windows (file name)  [excluded by default]\n",
+		"legacy.go: //go:build go1.22 && !gccgo\n",
+		`call compare_build_tags with tags "windows/amd64,linux/arm64,purego".`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// corsAllowHeaders are the request headers browser clients need to send to
+// the sse and http transports, used when a preflight does not list its own.
+var corsAllowHeaders = []string{"Content-Type", "Authorization", "Accept", "Mcp-Session-Id", "Mcp-Protocol-Version", "Last-Event-ID"}
+
+// corsHandler wraps next with CORS support for the given allowed origins
+// ("*" allows any origin). Preflight OPTIONS requests are answered directly;
+// requests from other origins are passed through without CORS headers, so
+// browsers refuse to expose the response.
+func corsHandler(origins []string, next http.Handler) http.Handler {
+	anyOrigin := slices.Contains(origins, "*")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		allowed := origin != "" && (anyOrigin || slices.Contains(origins, origin))
+		if allowed {
+			if anyOrigin {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			h.Set("Access-Control-Expose-Headers", "Mcp-Session-Id")
+		}
+
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Preflight.
+		if !allowed {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		h.Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+			h.Set("Access-Control-Allow-Headers", requested)
+		} else {
+			h.Set("Access-Control-Allow-Headers", strings.Join(corsAllowHeaders, ", "))
+		}
+		h.Set("Access-Control-Max-Age", "600")
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSHandler(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	serve := func(origins []string, method, origin string, preflight bool) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, "/mcp", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", "POST")
+		}
+		rec := httptest.NewRecorder()
+		corsHandler(origins, next).ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("allowed origin", func(t *testing.T) {
+		rec := serve([]string{"https://app.example.com"}, http.MethodPost, "https://app.example.com", false)
+		if rec.Code != http.StatusTeapot {
+			t.Errorf("request not passed through: status %d", rec.Code)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+			t.Errorf("Access-Control-Allow-Origin = %q", got)
+		}
+		if got := rec.Header().Get("Access-Control-Expose-Headers"); got != "Mcp-Session-Id" {
+			t.Errorf("Access-Control-Expose-Headers = %q", got)
+		}
+	})
+
+	t.Run("wildcard", func(t *testing.T) {
+		rec := serve([]string{"*"}, http.MethodGet, "https://any.example.com", false)
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+		}
+	})
+
+	t.Run("other origin", func(t *testing.T) {
+		rec := serve([]string{"https://app.example.com"}, http.MethodPost, "https://evil.example.com", false)
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("unexpected Access-Control-Allow-Origin %q", got)
+		}
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		rec := serve([]string{"https://app.example.com"}, http.MethodOptions, "https://app.example.com", true)
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("preflight status = %d, want %d", rec.Code, http.StatusNoContent)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Methods"); got == "" {
+			t.Error("missing Access-Control-Allow-Methods")
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Headers"); got == "" {
+			t.Error("missing Access-Control-Allow-Headers")
+		}
+	})
+
+	t.Run("preflight from other origin", func(t *testing.T) {
+		rec := serve([]string{"https://app.example.com"}, http.MethodOptions, "https://evil.example.com", true)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("preflight status = %d, want %d", rec.Code, http.StatusForbidden)
+		}
+	})
+}
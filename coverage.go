@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const testCoverageDescription = `Run a local Go package's tests with go test -cover and report the share of
+statements they cover, whether they pass, and which tests fail, followed by
+the package documentation. Coverage is a rough signal of how well-tested a
+package is when deciding whether to trust or depend on it. Results of
+unchanged packages come from the go command's test cache.`
+
+// defaultTestTimeout bounds go test when the request does not set
+// timeout_seconds; tests routinely outlast cmdTimeout.
+const defaultTestTimeout = 2 * time.Minute
+
+var (
+	// coveragePattern matches go test's coverage summary.
+	coveragePattern = regexp.MustCompile(`coverage: (\d+(?:\.\d+)?)% of statements`)
+	// failedTestPattern matches the report of a failed test or subtest.
+	failedTestPattern = regexp.MustCompile(`(?m)^\s*--- FAIL: (\S+)`)
+)
+
+// testCoverage is the outcome of go test -cover for one package.
+type testCoverage struct {
+	percent string // "75.0", or "" if go test reported none
+	noTests bool
+	passed  bool
+	cached  bool
+	failed  []string // failed tests, in report order
+}
+
+func (gs *godocServer) handleTestCoverage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	workingDir, err := request.RequireString("working_dir")
+	if err != nil {
+		return mcp.NewToolResultError("working_dir argument is required; only local packages can be tested"), nil
+	}
+	timeout := defaultTestTimeout
+	if seconds := request.GetInt("timeout_seconds", 0); seconds != 0 {
+		if seconds < 0 || time.Duration(seconds)*time.Second > maxCmdTimeout {
+			return mcp.NewToolResultError(fmt.Sprintf("timeout_seconds must be between 1 and %d", int(maxCmdTimeout.Seconds()))), nil
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	pkgPath, workingDir, err = gs.resolvePackage(ctx, pkgPath, workingDir)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cov, err := gs.runCoverage(ctx, workingDir, pkgPath, timeout)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Test coverage of %s: ", pkgPath)
+	switch {
+	case cov.noTests:
+		b.WriteString("no test files\n")
+	case cov.percent == "":
+		b.WriteString("not reported\n")
+	default:
+		fmt.Fprintf(&b, "%s%% of statements\n", cov.percent)
+	}
+	if !cov.noTests {
+		status := "Tests pass"
+		if !cov.passed {
+			status = "Tests FAIL"
+			if len(cov.failed) > 0 {
+				status += ": " + strings.Join(cov.failed, ", ")
+			}
+		}
+		if cov.cached {
+			status += " (cached result)"
+		}
+		b.WriteString(status + "\n")
+	}
+
+	if request.GetBool("include_doc", true) {
+		doc, err := gs.runGoDoc(ctx, workingDir, pkgPath)
+		if err != nil {
+			fmt.Fprintf(&b, "\nDocumentation unavailable: %v\n", err)
+		} else {
+			b.WriteString("\n" + doc)
+		}
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// runCoverage runs go test -cover for importPath in workingDir, within
+// timeout. Failing tests are reported in the result; a package that does not
+// build, or a run that times out, is an error.
+func (gs *godocServer) runCoverage(ctx context.Context, workingDir, importPath string, timeout time.Duration) (testCoverage, error) {
+	testCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	out, err := gs.combinedOutput(testCtx, gs.goCommand(testCtx, workingDir, "test", "-cover", importPath))
+	output := string(out)
+	if testCtx.Err() != nil {
+		return testCoverage{}, fmt.Errorf("go test timed out after %s; retry with a larger timeout_seconds", timeout)
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return testCoverage{}, fmt.Errorf("failed to run go test: %w", err)
+	}
+	if strings.Contains(output, "[build failed]") || strings.Contains(output, "[setup failed]") {
+		return testCoverage{}, fmt.Errorf("the package or its tests failed to build:\n%s", strings.TrimSpace(output))
+	}
+
+	// Depending on the Go version, a package without tests is reported as
+	// [no test files] or by its bare import path; either way, with no ok line.
+	cov := testCoverage{
+		passed:  err == nil,
+		noTests: strings.Contains(output, "[no test files]") || (err == nil && !strings.Contains(output, "ok  ")),
+		cached:  strings.Contains(output, "(cached)"),
+	}
+	if m := coveragePattern.FindStringSubmatch(output); m != nil {
+		cov.percent = m[1]
+	}
+	for _, m := range failedTestPattern.FindAllStringSubmatch(output, -1) {
+		cov.failed = append(cov.failed, m[1])
+	}
+	if !cov.passed && len(cov.failed) == 0 && cov.percent == "" {
+		return testCoverage{}, fmt.Errorf("go test failed: %w\noutput: %s", err, strings.TrimSpace(output))
+	}
+	return cov, nil
+}
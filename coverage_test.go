@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleTestCoverage(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/cov\n\ngo 1.21\n",
+		"sign/sign.go": `// Package sign classifies numbers.
+package sign
+
+// Of returns the sign of x.
+func Of(x int) int {
+	if x > 0 {
+		return 1
+	}
+	if x < 0 {
+		return -1
+	}
+	return 0
+}
+`,
+		"sign/sign_test.go": `package sign
+
+import "testing"
+
+func TestOf(t *testing.T) {
+	if Of(2) != 1 {
+		t.Fatal("Of(2)")
+	}
+}
+`,
+		"untested/untested.go":  "package untested\n\nfunc F() {}\n",
+		"failing/failing.go":    "package failing\n\nfunc F() int { return 1 }\n",
+		"failing/fail_test.go":  "package failing\n\nimport \"testing\"\n\nfunc TestF(t *testing.T) {\n\tt.Run(\"sub\", func(t *testing.T) { t.Error(\"wrong\") })\n}\n",
+		"broken/broken.go":      "package broken\n\nfunc F() {}\n",
+		"broken/broken_test.go": "package broken\n\nfunc TestF(t *testing.T) { undefined() }\n",
+	})
+
+	gs := newGodocServer(withTestTools(true))
+	call := func(args map[string]any) (string, bool) {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "test_coverage"
+		req.Params.Arguments = args
+		result, err := gs.mcpServer.GetTool("test_coverage").Handler(context.Background(), req)
+		if err != nil {
+			t.Fatalf("test_coverage returned protocol error: %v", err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	text, isErr := call(map[string]any{"path": "./sign", "working_dir": dir})
+	for _, want := range []string{"Test coverage of example.com/cov/sign: 40.0% of statements", "Tests pass", "func Of(x int) int"} {
+		if isErr || !strings.Contains(text, want) {
+			t.Errorf("sign: output missing %q:\n%s", want, text)
+		}
+	}
+
+	text, isErr = call(map[string]any{"path": "./untested", "working_dir": dir, "include_doc": false})
+	if isErr || !strings.Contains(text, "no test files") || strings.Contains(text, "package untested") {
+		t.Errorf("untested: unexpected result:\n%s", text)
+	}
+
+	text, isErr = call(map[string]any{"path": "./failing", "working_dir": dir, "include_doc": false})
+	if isErr || !strings.Contains(text, "Tests FAIL: TestF, TestF/sub") {
+		t.Errorf("failing: unexpected result:\n%s", text)
+	}
+
+	text, isErr = call(map[string]any{"path": "./broken", "working_dir": dir})
+	if !isErr || !strings.Contains(text, "failed to build") {
+		t.Errorf("broken: expected a build error, got:\n%s", text)
+	}
+
+	if _, isErr := call(map[string]any{"path": "./sign", "working_dir": dir, "timeout_seconds": 301}); !isErr {
+		t.Error("expected an error for timeout_seconds above the maximum")
+	}
+	if _, isErr := call(map[string]any{"path": "./sign"}); !isErr {
+		t.Error("expected an error without working_dir")
+	}
+
+	if newGodocServer().mcpServer.GetTool("test_coverage") != nil {
+		t.Error("test_coverage registered without -enable-test")
+	}
+}
+
+func TestRunCoverageTimeout(t *testing.T) {
+	fakeGo(t, "exec sleep 5\n")
+	_, err := newGodocServer().runCoverage(context.Background(), t.TempDir(), "example.com/slow", 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("runCoverage() error = %v, want a timeout", err)
+	}
+}
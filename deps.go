@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const dependsOnDescription = `Check whether one Go package depends on another, directly or
+transitively. Returns the shortest import chain from the first package to the
+second, or a statement that no such dependency exists. Use this for
+architectural questions about coupling, e.g. whether a domain package
+(indirectly) imports a database driver.`
+
+func (gs *godocServer) handleDependsOn(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	from, err := request.RequireString("from")
+	if err != nil {
+		return mcp.NewToolResultError("from argument is required"), nil
+	}
+	to, err := request.RequireString("to")
+	if err != nil {
+		return mcp.NewToolResultError("to argument is required"), nil
+	}
+	workingDir := request.GetString("working_dir", "")
+
+	from, listDir, err := gs.resolvePackage(ctx, from, workingDir)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := gs.checkPathsAllowed(to, workingDir); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// The target only names a package in from's build; it needs no project.
+	to, _, err = validatePath(to, workingDir)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	to, _ = splitVersion(to)
+
+	imports, err := gs.importGraph(ctx, listDir, from)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	chain := importChain(imports, from, to)
+	if chain == nil {
+		return mcp.NewToolResultText(fmt.Sprintf("%s does not depend on %s (checked %d packages in its build)", from, to, len(imports))), nil
+	}
+
+	if len(chain) == 1 {
+		return mcp.NewToolResultText(fmt.Sprintf("%s and %s are the same package", from, to)), nil
+	}
+
+	var b strings.Builder
+	kind := "directly"
+	if len(chain) > 2 {
+		kind = fmt.Sprintf("through %d intermediate packages", len(chain)-2)
+	}
+	fmt.Fprintf(&b, "%s depends on %s %s:\n\n%s\n", from, to, kind, chain[0])
+	for _, p := range chain[1:] {
+		b.WriteString("  imports " + p + "\n")
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// importGraph lists importPath and its transitive dependencies as seen from
+// workingDir, mapping each package to the packages it imports.
+func (gs *godocServer) importGraph(ctx context.Context, workingDir, importPath string) (map[string][]string, error) {
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	cmd := gs.goCommand(listCtx, workingDir, "list", "-deps", "-e", "-f", `{{.ImportPath}}{{range .Imports}} {{.}}{{end}}`, importPath)
+	out, err := gs.combinedOutput(listCtx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("go list failed: %w\noutput: %s", err, string(out))
+	}
+
+	imports := make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 {
+			imports[fields[0]] = fields[1:]
+		}
+	}
+	return imports, nil
+}
+
+// importChain returns the shortest import chain from one package to another
+// in the graph, starting with from and ending with to, or nil if to is not
+// reachable.
+func importChain(imports map[string][]string, from, to string) []string {
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if p == to {
+			var chain []string
+			for ; p != ""; p = prev[p] {
+				chain = append([]string{p}, chain...)
+			}
+			return chain
+		}
+		for _, imp := range imports[p] {
+			if _, seen := prev[imp]; !seen {
+				prev[imp] = p
+				queue = append(queue, imp)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestImportChain(t *testing.T) {
+	imports := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"d"},
+		"c": {"e"},
+		"e": {"d"},
+	}
+	tests := []struct {
+		to   string
+		want []string
+	}{
+		{"d", []string{"a", "b", "d"}},
+		{"c", []string{"a", "c"}},
+		{"a", []string{"a"}},
+		{"x", nil},
+	}
+	for _, tt := range tests {
+		if got := importChain(imports, "a", tt.to); !slices.Equal(got, tt.want) {
+			t.Errorf("importChain(a, %s) = %v, want %v", tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestHandleDependsOn(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":         "module example.com/app\n\ngo 1.21\n",
+		"api/api.go":     "package api\n\nimport _ \"example.com/app/service\"\n",
+		"service/svc.go": "package service\n\nimport _ \"example.com/app/store\"\n",
+		"store/store.go": "package store\n\nimport _ \"database/sql\"\n",
+		"util/util.go":   "package util\n",
+	})
+
+	gs := newGodocServer()
+	call := func(from, to string) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "depends_on"
+		req.Params.Arguments = map[string]any{"from": from, "to": to, "working_dir": dir}
+		result, err := gs.handleDependsOn(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleDependsOn returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleDependsOn returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := call("./api", "database/sql")
+	want := "example.com/app/api depends on database/sql through 2 intermediate packages:\n\n" +
+		"example.com/app/api\n  imports example.com/app/service\n  imports example.com/app/store\n  imports database/sql\n"
+	if text != want {
+		t.Errorf("unexpected chain:\n%s\nwant:\n%s", text, want)
+	}
+
+	if text := call("./api", "./util"); !strings.Contains(text, "example.com/app/api does not depend on example.com/app/util") {
+		t.Errorf("expected no dependency, got:\n%s", text)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/build"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const disassembleDescription = `Show the assembly the Go compiler generates for a function or method
+in a local package. Compiles the package with -gcflags=-S and returns the
+instructions for the named function, including closures it contains and every
+instantiation of a generic function, annotated with source lines. Use this to
+inspect inlining, bounds checks, and allocation in hot paths.`
+
+var (
+	// asmNoise matches compiler listing lines that carry no instructions:
+	// hex dumps, relocations, and GC metadata pseudo-instructions.
+	asmNoise = regexp.MustCompile(`^\t(?:0x[0-9a-f]{4}(?: [0-9a-f]{2})+ |rel |0x[0-9a-f]{4} \d{5} \([^)]*\)\t(?:FUNCDATA|PCDATA)\t)`)
+	// asmSourcePos matches the source position of an instruction, capturing
+	// the file's base name and line.
+	asmSourcePos = regexp.MustCompile(`\((?:[^()\t]*/)?([^/()\t]+\.go:\d+)\)`)
+)
+
+func (gs *godocServer) handleDisassemble(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	target, err := request.RequireString("target")
+	if err != nil {
+		return mcp.NewToolResultError("target argument is required"), nil
+	}
+	workingDir, err := request.RequireString("working_dir")
+	if err != nil {
+		return mcp.NewToolResultError("working_dir argument is required; only local packages can be disassembled"), nil
+	}
+	page := request.GetInt("page", 1)
+
+	pkgPath, workingDir, err = gs.resolvePackage(ctx, pkgPath, workingDir)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	listing, err := gs.compilerListing(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	asm := functionAssembly(listing, pkgPath, target)
+	if asm == "" {
+		return mcp.NewToolResultError(fmt.Sprintf("no compiled code for %s in %s; it may not exist, or be inlined everywhere and never compiled on its own", target, pkgPath)), nil
+	}
+
+	text := fmt.Sprintf("Assembly for %s.%s (%s/%s)\n\n%s", pkgPath, target, build.Default.GOOS, build.Default.GOARCH, asm)
+	result, err := paginate(text, page, 1000)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+// compilerListing compiles importPath with -gcflags=-S and returns the
+// compiler's assembly listing. The go command replays the listing from its
+// build cache when the package is unchanged.
+func (gs *godocServer) compilerListing(ctx context.Context, workingDir, importPath string) (string, error) {
+	buildCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	cmd := gs.goCommand(buildCtx, workingDir, "build", "-gcflags=-S", importPath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if _, err := gs.output(buildCtx, cmd); err != nil {
+		return "", fmt.Errorf("go build failed: %w\noutput: %s", err, stderr.String())
+	}
+	return stderr.String(), nil
+}
+
+// functionAssembly extracts from a compiler listing the code of target
+// ("Func" or "Type.Method") in importPath: the function itself, its
+// closures, its generic instantiations, and for methods both the value and
+// pointer receiver forms. Instruction lines keep only the source file's base
+// name; metadata lines are dropped.
+func functionAssembly(listing, importPath, target string) string {
+	want := importPath + "." + target
+	var b strings.Builder
+	in := false
+	for _, line := range strings.Split(listing, "\n") {
+		if line == "" {
+			continue
+		}
+		if line[0] != '\t' && line[0] != ' ' {
+			name, _, _ := strings.Cut(line, " ")
+			in = strings.Contains(line, " STEXT ") && asmSymbolMatches(name, want)
+			if in {
+				if b.Len() > 0 {
+					b.WriteString("\n")
+				}
+				b.WriteString(line + "\n")
+			}
+			continue
+		}
+		if in && !asmNoise.MatchString(line) {
+			b.WriteString(asmSourcePos.ReplaceAllString(line, "($1)") + "\n")
+		}
+	}
+	return b.String()
+}
+
+// asmSymbolMatches reports whether the linker symbol name denotes want
+// ("importpath.Func" or "importpath.Type.Method") or a closure inside it,
+// ignoring type arguments and pointer receivers.
+func asmSymbolMatches(name, want string) bool {
+	name = stripTypeArgs(name)
+	name = strings.NewReplacer("(*", "", ")", "").Replace(name)
+	return name == want || strings.HasPrefix(name, want+".func")
+}
+
+// stripTypeArgs removes the bracketed type arguments of instantiated generic
+// functions and types from a symbol name, e.g. "Map[go.shape.[]int]".
+func stripTypeArgs(name string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range name {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const sampleListing = `# example.com/asmt
+example.com/asmt.(*T).Inc STEXT nosplit size=4 align=0x0 args=0x8 locals=0x0 funcid=0x0
+	0x0000 00000 (/src/asmt/a.go:5)	TEXT	example.com/asmt.(*T).Inc(SB), NOSPLIT|NOFRAME|ABIInternal, $0-8
+	0x0000 00000 (/src/asmt/a.go:5)	FUNCDATA	$0, gclocals·wvjpxkknJ4nY1JtrArJJaw==(SB)
+	0x0000 00000 (/src/asmt/a.go:5)	PCDATA	$3, $1
+	0x0000 00000 (/src/asmt/a.go:5)	INCQ	(AX)
+	0x0003 00003 (/src/asmt/a.go:5)	RET
+	0x0000 48 ff 00 c3                                      H...
+example.com/asmt.Use STEXT size=81 align=0x0 args=0x0 locals=0x28 funcid=0x0
+	0x0000 00000 (/src/asmt/a.go:20)	TEXT	example.com/asmt.Use(SB), ABIInternal, $40-0
+	0x0000 00000 (/src/asmt/a.go:20)	CALL	example.com/asmt.Map[go.shape.int](SB)
+	rel 3+4 t=R_CALL example.com/asmt.Map[go.shape.int]+0
+example.com/asmt.Use.func1 STEXT nosplit size=4 align=0x0 args=0x8 locals=0x0 funcid=0x0
+	0x0000 00000 (/src/asmt/a.go:20)	INCQ	AX
+example.com/asmt.Map[go.shape.[]int] STEXT dupok size=168 align=0x0 args=0x28 locals=0x18 funcid=0x0
+	0x0000 00000 (/src/asmt/a.go:14)	TEXT	example.com/asmt.Map[go.shape.[]int](SB), ABIInternal, $32-40
+go:info.example.com/asmt.Use.func1$abstract SDWARFABSFCN dupok size=40 align=0x0
+	0x0000 04 65 78 61                                      .exa
+example.com/asmt.Useless STEXT size=1 align=0x0 args=0x0 locals=0x0 funcid=0x0
+	0x0000 00000 (/src/asmt/a.go:30)	RET
+`
+
+func TestFunctionAssembly(t *testing.T) {
+	got := functionAssembly(sampleListing, "example.com/asmt", "T.Inc")
+	want := `example.com/asmt.(*T).Inc STEXT nosplit size=4 align=0x0 args=0x8 locals=0x0 funcid=0x0
+	0x0000 00000 (a.go:5)	TEXT	example.com/asmt.(*T).Inc(SB), NOSPLIT|NOFRAME|ABIInternal, $0-8
+	0x0000 00000 (a.go:5)	INCQ	(AX)
+	0x0003 00003 (a.go:5)	RET
+`
+	if got != want {
+		t.Errorf("functionAssembly(T.Inc) =\n%s\nwant:\n%s", got, want)
+	}
+
+	got = functionAssembly(sampleListing, "example.com/asmt", "Use")
+	if !strings.Contains(got, "asmt.Use STEXT") || !strings.Contains(got, "asmt.Use.func1 STEXT") {
+		t.Errorf("expected Use and its closure:\n%s", got)
+	}
+	if strings.Contains(got, "Useless") || strings.Contains(got, "rel 3+4") {
+		t.Errorf("unexpected lines in Use listing:\n%s", got)
+	}
+
+	if got := functionAssembly(sampleListing, "example.com/asmt", "Map"); !strings.Contains(got, "Map[go.shape.[]int] STEXT") {
+		t.Errorf("expected the generic instantiation:\n%s", got)
+	}
+	if got := functionAssembly(sampleListing, "example.com/asmt", "Missing"); got != "" {
+		t.Errorf("expected no output for a missing function, got:\n%s", got)
+	}
+}
+
+func TestHandleDisassemble(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/hot\n\ngo 1.21\n",
+		"hot.go": "package hot\n\n// Sum adds xs.\nfunc Sum(xs []int) int {\n\ts := 0\n\tfor _, x := range xs {\n\t\ts += x\n\t}\n\treturn s\n}\n",
+	})
+
+	if newGodocServer().mcpServer.GetTool("disassemble") != nil {
+		t.Error("disassemble should only be registered with -enable-disassembly")
+	}
+
+	gs := newGodocServer(withDisassembly(true))
+	if gs.mcpServer.GetTool("disassemble") == nil {
+		t.Fatal("disassemble tool not registered")
+	}
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "disassemble"
+	req.Params.Arguments = map[string]any{"path": ".", "target": "Sum", "working_dir": dir}
+	result, err := gs.handleDisassemble(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleDisassemble returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleDisassemble returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Assembly for example.com/hot.Sum (") || !strings.Contains(text, "example.com/hot.Sum STEXT") || !strings.Contains(text, "(hot.go:") {
+		t.Errorf("unexpected listing:\n%s", text)
+	}
+
+	req.Params.Arguments = map[string]any{"path": ".", "target": "Nope", "working_dir": dir}
+	if result, _ := gs.handleDisassemble(context.Background(), req); !result.IsError {
+		t.Error("expected an error for a function that does not exist")
+	}
+}
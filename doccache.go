@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+// defaultCacheShards is the number of independently locked buckets the
+// documentation cache is split into unless configured otherwise.
+const defaultCacheShards = 16
+
+// noCacheKey marks a context whose documentation lookups must not be
+// answered from the cache.
+type noCacheKey struct{}
+
+// withNoCache returns a context whose lookups bypass cached documentation.
+// Their fresh results still replace the cached entries, so later requests
+// see them too.
+func withNoCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// noCache reports whether ctx was returned by withNoCache.
+func noCache(ctx context.Context) bool {
+	skip, _ := ctx.Value(noCacheKey{}).(bool)
+	return skip
+}
+
+// docCache holds go doc output by cache key. Keys are spread over shards by
+// hash, each with its own lock and an equal share of maxCacheSize, so
+// concurrent requests for different packages rarely wait on each other.
+type docCache struct {
+	shards   []*cacheShard
+	capacity int // entries per shard
+	seed     maphash.Seed
+}
+
+type cacheShard struct {
+	mu      sync.Mutex
+	entries map[string]cachedDoc
+}
+
+// newDocCache returns an empty cache with n shards (at least one).
+func newDocCache(n int) *docCache {
+	if n < 1 {
+		n = 1
+	}
+	c := &docCache{
+		shards:   make([]*cacheShard, n),
+		capacity: max(1, (maxCacheSize+n-1)/n),
+		seed:     maphash.MakeSeed(),
+	}
+	for i := range c.shards {
+		c.shards[i] = &cacheShard{entries: make(map[string]cachedDoc)}
+	}
+	return c
+}
+
+// shard returns the shard holding key, chosen by its hash.
+func (c *docCache) shard(key string) *cacheShard {
+	return c.shards[maphash.String(c.seed, key)%uint64(len(c.shards))]
+}
+
+// get returns the content cached under key. When expires is set, entries
+// older than cacheTTL are dropped instead of returned.
+func (c *docCache) get(key string, expires bool) (string, bool) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc, ok := s.entries[key]
+	if !ok {
+		return "", false
+	}
+	if expires && time.Since(doc.timestamp) >= cacheTTL {
+		delete(s.entries, key)
+		return "", false
+	}
+	return doc.content, true
+}
+
+// put caches content under key, evicting the oldest entry of a full shard.
+func (c *docCache) put(key, content string) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= c.capacity {
+		var oldestKey string
+		var oldestTime time.Time
+		for k, v := range s.entries {
+			if oldestKey == "" || v.timestamp.Before(oldestTime) {
+				oldestKey = k
+				oldestTime = v.timestamp
+			}
+		}
+		delete(s.entries, oldestKey)
+	}
+	s.entries[key] = cachedDoc{content: content, timestamp: time.Now()}
+}
+
+// len returns the number of cached entries.
+func (c *docCache) len() int {
+	n := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		n += len(s.entries)
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// keys returns every cache key, for tests and diagnostics.
+func (c *docCache) keys() []string {
+	var keys []string
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for k := range s.entries {
+			keys = append(keys, k)
+		}
+		s.mu.Unlock()
+	}
+	return keys
+}
+
+// cachedNote returns the note get_doc appends to the documentation of dr's
+// package, or of its symbol target, computing it with note only when the
+// cache has none. The notes parse the package or run go list, which would
+// otherwise cost more than the cached documentation they annotate. The notes
+// of a package or symbol share one cache entry, a JSON object keyed by kind;
+// like the documentation, a local package's entry is keyed by its source hash
+// and others expire with cacheTTL.
+func (gs *godocServer) cachedNote(ctx context.Context, dr docRequest, kind, target string, note func(ctx context.Context, workingDir, importPath string) string) string {
+	key := gs.docCacheKey(ctx, dr.workingDir, []string{"notes", dr.pkgPath + "#" + target})
+	if dr.srcHash != "" {
+		key += "|src=" + dr.srcHash
+	}
+	notes := make(map[string]string)
+	if !noCache(ctx) {
+		if entry, ok := gs.cache.get(key, dr.srcHash == ""); ok && json.Unmarshal([]byte(entry), &notes) == nil {
+			if text, ok := notes[kind]; ok {
+				return text
+			}
+		}
+	}
+	text := note(ctx, dr.workingDir, dr.pkgPath)
+	notes[kind] = text
+	if entry, err := json.Marshal(notes); err == nil {
+		gs.cache.put(key, string(entry))
+	}
+	return text
+}
+
+// cachedSymbolNote is cachedNote for a note about the symbol target.
+func (gs *godocServer) cachedSymbolNote(ctx context.Context, dr docRequest, kind, target string, note func(ctx context.Context, workingDir, importPath, target string) string) string {
+	return gs.cachedNote(ctx, dr, kind, target, func(ctx context.Context, workingDir, importPath string) string {
+		return note(ctx, workingDir, importPath, target)
+	})
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCacheEviction(t *testing.T) {
+	c := newDocCache(1)
+
+	// Fill cache to maxCacheSize, oldest first.
+	for i := 0; i < maxCacheSize; i++ {
+		key := strings.Repeat("x", i+1)
+		c.put(key, "content")
+		s := c.shard(key)
+		s.entries[key] = cachedDoc{content: "content", timestamp: time.Now().Add(-time.Duration(maxCacheSize-i) * time.Second)}
+	}
+	if c.len() != maxCacheSize {
+		t.Fatalf("cache size = %d, want %d", c.len(), maxCacheSize)
+	}
+
+	// Inserting one more entry should evict the oldest.
+	c.put("new-entry", "new")
+	if c.len() != maxCacheSize {
+		t.Errorf("cache size after eviction = %d, want %d", c.len(), maxCacheSize)
+	}
+	if _, ok := c.get("new-entry", true); !ok {
+		t.Error("new entry not found in cache")
+	}
+	if _, ok := c.get("x", false); ok {
+		t.Error("oldest entry should have been evicted")
+	}
+	if _, ok := c.get("xx", false); !ok {
+		t.Error("second oldest entry should have been kept")
+	}
+
+	// Replacing an existing key does not evict anything.
+	c.put("new-entry", "newer")
+	if got, _ := c.get("new-entry", true); got != "newer" || c.len() != maxCacheSize {
+		t.Errorf("after replacing: content = %q, size = %d", got, c.len())
+	}
+}
+
+func TestCacheShardedCapacity(t *testing.T) {
+	c := newDocCache(16)
+	for i := 0; i < 3*maxCacheSize; i++ {
+		c.put(fmt.Sprintf("key-%d", i), "content")
+	}
+	if n := c.len(); n > maxCacheSize+len(c.shards) {
+		t.Errorf("sharded cache holds %d entries, want at most about %d", n, maxCacheSize)
+	}
+	if _, ok := c.get(fmt.Sprintf("key-%d", 3*maxCacheSize-1), true); !ok {
+		t.Error("most recent entry should be cached")
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	c := newDocCache(4)
+	c.put("expired", "old")
+	c.put("fresh", "new")
+	s := c.shard("expired")
+	s.entries["expired"] = cachedDoc{content: "old", timestamp: time.Now().Add(-cacheTTL - time.Second)}
+
+	// Fresh entry should be returned.
+	if doc, ok := c.get("fresh", true); !ok || doc != "new" {
+		t.Errorf("fresh cache entry = (%q, %v), want (%q, true)", doc, ok, "new")
+	}
+
+	// Entries for local packages are validated by source hash, not age.
+	if doc, ok := c.get("expired", false); !ok || doc != "old" {
+		t.Errorf("non-expiring lookup = (%q, %v), want (%q, true)", doc, ok, "old")
+	}
+
+	// Expired entry should be evicted on access.
+	if _, ok := c.get("expired", true); ok {
+		t.Error("expired entry should not be returned")
+	}
+	if _, ok := s.entries["expired"]; ok {
+		t.Error("expired entry should have been evicted")
+	}
+}
+
+func TestCacheConcurrentAccess(t *testing.T) {
+	c := newDocCache(8)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				key := fmt.Sprintf("%d-%d", g, i%50)
+				c.put(key, key)
+				if doc, ok := c.get(key, true); ok && doc != key {
+					t.Errorf("get(%q) = %q", key, doc)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func BenchmarkDocCacheParallel(b *testing.B) {
+	keys := make([]string, 256)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("/work/project|github.com/user/repo/pkg%d|Symbol", i)
+	}
+	for _, shards := range []int{1, defaultCacheShards} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			c := newDocCache(shards)
+			for _, k := range keys {
+				c.put(k, "documentation")
+			}
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					k := keys[i%len(keys)]
+					if i%10 == 0 {
+						c.put(k, "documentation")
+					} else {
+						c.get(k, true)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
+
+func TestGetDocNotesCached(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found in PATH")
+	}
+	// Log each go command, then run the real one.
+	log := filepath.Join(t.TempDir(), "go.log")
+	fakeGo(t, "echo \"$*\" >> "+log+"\nexec "+goBin+" \"$@\"\n")
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/notes\n\ngo 1.21\n",
+		"notes.go": `// Package notes has notes.
+package notes
+
+func init() {}
+
+// MustParse panics if s is invalid.
+func MustParse(s string) int { return 0 }
+
+// Parse parses s.
+func Parse(s string) (int, error) { return 0, nil }
+`,
+	})
+
+	gs := newGodocServer()
+	defer gs.cleanup()
+	call := func(args map[string]any) (doc string, commands []string) {
+		t.Helper()
+		os.Remove(log)
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetDoc(%v) = %+v, %v", args, result, err)
+		}
+		out, _ := os.ReadFile(log)
+		return result.Content[0].(mcp.TextContent).Text, strings.Fields(strings.ReplaceAll(string(out), " ", "_"))
+	}
+
+	for _, args := range []map[string]any{
+		{"path": ".", "working_dir": dir},
+		{"path": ".", "working_dir": dir, "target": "MustParse"},
+	} {
+		first, _ := call(args)
+		second, commands := call(args)
+		if len(commands) > 0 {
+			t.Errorf("repeated get_doc %v ran go commands: %v", args, commands)
+		}
+		if first != second {
+			t.Errorf("repeated get_doc %v changed:\nfirst:\n%s\nsecond:\n%s", args, first, second)
+		}
+	}
+
+	doc, _ := call(map[string]any{"path": ".", "working_dir": dir, "target": "MustParse"})
+	if !strings.Contains(doc, "WARNING: MustParse may panic") {
+		t.Fatalf("panic note missing:\n%s", doc)
+	}
+
+	// Editing the package invalidates its notes along with its documentation.
+	src := "// Package notes has notes.\npackage notes\n\n// MustParse parses s.\nfunc MustParse(s string) int { return 1 }\n"
+	if err := os.WriteFile(filepath.Join(dir, "notes.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc, _ = call(map[string]any{"path": ".", "working_dir": dir})
+	if strings.Contains(doc, "init") {
+		t.Errorf("init note survived removing the init function:\n%s", doc)
+	}
+}
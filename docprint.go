@@ -0,0 +1,540 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/format"
+	"go/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	docIndent        = "    " // go doc's indentation of doc comments
+	punchedCardWidth = 80     // go doc's limit for one-line parameter lists
+)
+
+// docPrinter renders symbol documentation exactly as go doc does, from a
+// package documented the way go doc documents it: all declarations kept and
+// the AST stripped of doc comments and function bodies. It follows go doc's
+// own printer (cmd/go/internal/doc), but never modifies the documentation,
+// so one docPrinter serves any number of symbols.
+type docPrinter struct {
+	dp   *doc.Package
+	fset *token.FileSet
+	buf  bytes.Buffer
+}
+
+// newDocPrinter documents lp for go doc-style rendering. go/doc edits the
+// AST, so a private parse is consumed; a cached one is parsed again.
+func newDocPrinter(lp *loadedPackage) (*docPrinter, error) {
+	private, err := lp.editable()
+	if err != nil {
+		return nil, err
+	}
+	dp, err := doc.NewFromFiles(private.fset, private.files, lp.path, doc.AllDecls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute documentation for %s: %w", lp.path, err)
+	}
+	return &docPrinter{dp: dp, fset: private.fset}, nil
+}
+
+// symbolDoc renders the output of "go doc <package> <target>" for a function,
+// constant, variable, type, or concrete method. It reports false for
+// anything else, such as struct fields, interface methods, examples, and
+// names go doc would not find, which are left to go doc.
+func (p *docPrinter) symbolDoc(target string) (string, bool) {
+	if p.dp.Name == "main" || strings.HasPrefix(target, "Example") {
+		return "", false
+	}
+	p.buf.Reset()
+	fmt.Fprintf(&p.buf, "package %s // import %q\n\n", p.dp.Name, p.dp.ImportPath)
+
+	symbol, method, isMethod := strings.Cut(target, ".")
+	if isMethod {
+		if !p.methodDoc(symbol, method) {
+			return "", false
+		}
+		return p.buf.String(), true
+	}
+
+	found := false
+	var funcs []*doc.Func
+	consts, vars := p.dp.Consts, p.dp.Vars
+	funcs = append(funcs, p.dp.Funcs...)
+	for _, typ := range p.dp.Types {
+		consts = append(consts, typ.Consts...)
+		vars = append(vars, typ.Vars...)
+		funcs = append(funcs, typ.Funcs...)
+	}
+	for _, fun := range funcs {
+		if matchSymbol(symbol, fun.Name) {
+			p.emit(fun.Doc, fun.Decl)
+			found = true
+		}
+	}
+	printed := make(map[*ast.GenDecl]bool)
+	for _, values := range [][]*doc.Value{consts, vars} {
+		for _, value := range values {
+			for _, name := range value.Names {
+				if matchSymbol(symbol, name) {
+					p.valueDoc(value, printed)
+					found = true
+				}
+			}
+		}
+	}
+	for _, typ := range p.dp.Types {
+		if matchSymbol(symbol, typ.Name) {
+			p.typeDoc(typ)
+			found = true
+		}
+	}
+	if !found {
+		return "", false
+	}
+	return p.buf.String(), true
+}
+
+// methodDoc prints the concrete methods of the types named symbol that match
+// method. It reports false if there are none, or if a matching type is an
+// interface, whose methods go doc prints within the interface.
+func (p *docPrinter) methodDoc(symbol, method string) bool {
+	found := false
+	for _, typ := range p.dp.Types {
+		if !matchSymbol(symbol, typ.Name) {
+			continue
+		}
+		if len(typ.Methods) == 0 {
+			return false
+		}
+		for _, meth := range typ.Methods {
+			if matchSymbol(method, meth.Name) {
+				p.emit(meth.Doc, meth.Decl)
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// emit prints node followed by its doc comment.
+func (p *docPrinter) emit(comment string, node ast.Node) {
+	if err := format.Node(&p.buf, p.fset, node); err != nil {
+		return
+	}
+	if comment == "" {
+		p.newlines(1)
+		return
+	}
+	p.newlines(1)
+	d := p.dp.Parser().Parse(comment)
+	pr := p.dp.Printer()
+	pr.TextPrefix = docIndent
+	pr.TextCodePrefix = docIndent + docIndent
+	p.buf.Write(pr.Text(d))
+	p.newlines(2)
+}
+
+// newlines ends the output with at least n newlines, n being 1 or 2.
+func (p *docPrinter) newlines(n int) {
+	for !bytes.HasSuffix(p.buf.Bytes(), []byte("\n\n")[:n]) {
+		p.buf.WriteByte('\n')
+	}
+}
+
+// valueDoc prints a constant or variable declaration, keeping only the specs
+// that declare an exported name. printed records the declarations already
+// printed, which a group matching several names would otherwise repeat.
+func (p *docPrinter) valueDoc(value *doc.Value, printed map[*ast.GenDecl]bool) {
+	if printed[value.Decl] {
+		return
+	}
+	specs := make([]ast.Spec, 0, len(value.Decl.Specs))
+	var typ ast.Expr
+	for _, spec := range value.Decl.Specs {
+		vspec := spec.(*ast.ValueSpec)
+		// The type may carry over from a previous spec, as with iota.
+		if vspec.Type != nil {
+			typ = vspec.Type
+		}
+		for _, ident := range vspec.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			if vspec.Type == nil && vspec.Values == nil && typ != nil {
+				cp := *vspec
+				cp.Type = &ast.Ident{Name: p.oneLineNode(typ), NamePos: vspec.End() - 1}
+				vspec = &cp
+			}
+			specs = append(specs, vspec)
+			typ = nil // only the first exported name gets the type
+			break
+		}
+	}
+	if len(specs) == 0 {
+		return
+	}
+	printed[value.Decl] = true
+	decl := *value.Decl
+	decl.Specs = specs
+	p.emit(value.Doc, &decl)
+}
+
+// typeDoc prints a type declaration, with unexported fields and methods
+// elided, followed by one-line summaries of its constants, variables,
+// constructors, and methods.
+func (p *docPrinter) typeDoc(typ *doc.Type) {
+	var spec *ast.TypeSpec
+	for _, s := range typ.Decl.Specs {
+		if ts := s.(*ast.TypeSpec); ts.Name.Name == typ.Name {
+			cp := *ts
+			spec = &cp
+		}
+	}
+	if spec == nil {
+		return
+	}
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		cp := *t
+		cp.Fields = trimUnexportedFields(t.Fields, false)
+		spec.Type = &cp
+	case *ast.InterfaceType:
+		cp := *t
+		cp.Methods = trimUnexportedFields(t.Methods, true)
+		spec.Type = &cp
+	}
+	decl := *typ.Decl
+	decl.Specs = []ast.Spec{spec}
+	p.emit(typ.Doc, &decl)
+	p.newlines(2)
+
+	for _, values := range [][]*doc.Value{typ.Consts, typ.Vars} {
+		for _, value := range values {
+			if line := p.oneLineNode(value.Decl); line != "" {
+				p.buf.WriteString(line + "\n")
+			}
+		}
+	}
+	for _, funcs := range [][]*doc.Func{typ.Funcs, typ.Methods} {
+		for _, fun := range funcs {
+			if token.IsExported(fun.Name) {
+				p.buf.WriteString(p.oneLineNode(fun.Decl) + "\n")
+			}
+		}
+	}
+}
+
+// trimUnexportedFields returns a copy of fields without its unexported
+// fields or methods, ending in a "Has unexported ..." comment if any were
+// removed. Doc comments are rebuilt from their text, so that directives are
+// left out, as in go doc.
+func trimUnexportedFields(fields *ast.FieldList, isInterface bool) *ast.FieldList {
+	what := "methods"
+	if !isInterface {
+		what = "fields"
+	}
+
+	trimmed := false
+	list := make([]*ast.Field, 0, len(fields.List))
+	for _, field := range fields.List {
+		if field.Doc != nil {
+			cp := *field
+			cp.Doc = rebuildFieldDoc(field.Doc)
+			field = &cp
+		}
+
+		names := field.Names
+		if len(names) == 0 {
+			// An embedded type is named by its type.
+			ty := field.Type
+			if se, ok := field.Type.(*ast.StarExpr); !isInterface && ok {
+				ty = se.X
+			}
+			switch ident := ty.(type) {
+			case *ast.Ident:
+				if isInterface && ident.Obj == nil && (ident.Name == "error" || ident.Name == "comparable") {
+					list = append(list, field)
+					continue
+				}
+				names = []*ast.Ident{ident}
+			case *ast.SelectorExpr:
+				names = []*ast.Ident{ident.Sel}
+			}
+		}
+		ok := true
+		for _, name := range names {
+			if !name.IsExported() {
+				trimmed = true
+				ok = false
+				break
+			}
+		}
+		if ok {
+			list = append(list, field)
+		}
+	}
+	if !trimmed {
+		return &ast.FieldList{Opening: fields.Opening, List: list, Closing: fields.Closing}
+	}
+	unexportedField := &ast.Field{
+		// The printer treats an empty name as a field of a named type,
+		// placed just before the closing brace.
+		Type: &ast.Ident{Name: "", NamePos: fields.Closing - 1},
+		Comment: &ast.CommentGroup{
+			List: []*ast.Comment{{Text: fmt.Sprintf("// Has unexported %s.\n", what)}},
+		},
+	}
+	return &ast.FieldList{
+		Opening: fields.Opening,
+		List:    append(list, unexportedField),
+		Closing: fields.Closing,
+	}
+}
+
+// rebuildFieldDoc returns a comment group holding the text of doc, without
+// the directives the source comment may contain.
+func rebuildFieldDoc(doc *ast.CommentGroup) *ast.CommentGroup {
+	text := doc.Text()
+	if len(doc.List[len(doc.List)-1].Text) != 2 {
+		text = strings.TrimSuffix(text, "\n")
+	}
+	rebuilt := &ast.CommentGroup{}
+	for _, line := range strings.Split(text, "\n") {
+		prefix := "// "
+		if len(line) > 0 && line[0] == '\t' {
+			prefix = "//"
+		}
+		rebuilt.List = append(rebuilt.List, &ast.Comment{Text: prefix + line})
+	}
+	rebuilt.List[0].Slash = doc.List[0].Slash
+	return rebuilt
+}
+
+// oneLineNode returns go doc's one-line summary of node.
+func (p *docPrinter) oneLineNode(node ast.Node) string {
+	const maxDepth = 10
+	return p.oneLineNodeDepth(node, maxDepth)
+}
+
+func (p *docPrinter) oneLineNodeDepth(node ast.Node, depth int) string {
+	const dotDotDot = "..."
+	if depth == 0 {
+		return dotDotDot
+	}
+	depth--
+
+	switch n := node.(type) {
+	case nil:
+		return ""
+
+	case *ast.GenDecl:
+		trailer := ""
+		if len(n.Specs) > 1 {
+			trailer = " " + dotDotDot
+		}
+		// Summarize the first exported spec; its type may carry over from a
+		// previous spec, as with iota.
+		typ := ""
+		for i, spec := range n.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			if valueSpec.Type != nil {
+				typ = " " + p.oneLineNodeDepth(valueSpec.Type, depth)
+			} else if len(valueSpec.Values) > 0 {
+				typ = ""
+			}
+			if !valueSpec.Names[0].IsExported() {
+				continue
+			}
+			val := ""
+			if i < len(valueSpec.Values) && valueSpec.Values[i] != nil {
+				val = " = " + p.oneLineNodeDepth(valueSpec.Values[i], depth)
+			}
+			return fmt.Sprintf("%s %s%s%s%s", n.Tok, valueSpec.Names[0], typ, val, trailer)
+		}
+		return ""
+
+	case *ast.FuncDecl:
+		recv := p.oneLineNodeDepth(n.Recv, depth)
+		if len(recv) > 0 {
+			recv = "(" + recv + ") "
+		}
+		fnc := strings.TrimPrefix(p.oneLineNodeDepth(n.Type, depth), "func")
+		return fmt.Sprintf("func %s%s%s", recv, n.Name.Name, fnc)
+
+	case *ast.TypeSpec:
+		sep := " "
+		if n.Assign.IsValid() {
+			sep = " = "
+		}
+		tparams := p.formatTypeParams(n.TypeParams, depth)
+		return fmt.Sprintf("type %s%s%s%s", n.Name.Name, tparams, sep, p.oneLineNodeDepth(n.Type, depth))
+
+	case *ast.FuncType:
+		var params []string
+		if n.Params != nil {
+			for _, field := range n.Params.List {
+				params = append(params, p.oneLineField(field, depth))
+			}
+		}
+		needParens := false
+		var results []string
+		if n.Results != nil {
+			needParens = needParens || len(n.Results.List) > 1
+			for _, field := range n.Results.List {
+				needParens = needParens || len(field.Names) > 0
+				results = append(results, p.oneLineField(field, depth))
+			}
+		}
+
+		tparam := p.formatTypeParams(n.TypeParams, depth)
+		param := joinParams(params)
+		if len(results) == 0 {
+			return fmt.Sprintf("func%s(%s)", tparam, param)
+		}
+		result := joinParams(results)
+		if !needParens {
+			return fmt.Sprintf("func%s(%s) %s", tparam, param, result)
+		}
+		return fmt.Sprintf("func%s(%s) (%s)", tparam, param, result)
+
+	case *ast.StructType:
+		if n.Fields == nil || len(n.Fields.List) == 0 {
+			return "struct{}"
+		}
+		return "struct{ ... }"
+
+	case *ast.InterfaceType:
+		if n.Methods == nil || len(n.Methods.List) == 0 {
+			return "interface{}"
+		}
+		return "interface{ ... }"
+
+	case *ast.FieldList:
+		if n == nil || len(n.List) == 0 {
+			return ""
+		}
+		if len(n.List) == 1 {
+			return p.oneLineField(n.List[0], depth)
+		}
+		return dotDotDot
+
+	case *ast.FuncLit:
+		return p.oneLineNodeDepth(n.Type, depth) + " { ... }"
+
+	case *ast.CompositeLit:
+		typ := p.oneLineNodeDepth(n.Type, depth)
+		if len(n.Elts) == 0 {
+			return typ + "{}"
+		}
+		return typ + "{ " + dotDotDot + " }"
+
+	case *ast.ArrayType:
+		length := p.oneLineNodeDepth(n.Len, depth)
+		element := p.oneLineNodeDepth(n.Elt, depth)
+		return fmt.Sprintf("[%s]%s", length, element)
+
+	case *ast.MapType:
+		key := p.oneLineNodeDepth(n.Key, depth)
+		value := p.oneLineNodeDepth(n.Value, depth)
+		return fmt.Sprintf("map[%s]%s", key, value)
+
+	case *ast.CallExpr:
+		fnc := p.oneLineNodeDepth(n.Fun, depth)
+		var args []string
+		for _, arg := range n.Args {
+			args = append(args, p.oneLineNodeDepth(arg, depth))
+		}
+		return fmt.Sprintf("%s(%s)", fnc, joinParams(args))
+
+	case *ast.UnaryExpr:
+		return fmt.Sprintf("%s%s", n.Op, p.oneLineNodeDepth(n.X, depth))
+
+	case *ast.Ident:
+		return n.Name
+
+	default:
+		// Anything else is printed as is, unless it spans several lines.
+		var buf strings.Builder
+		format.Node(&buf, p.fset, node)
+		s := buf.String()
+		if strings.Contains(s, "\n") {
+			return dotDotDot
+		}
+		return s
+	}
+}
+
+func (p *docPrinter) formatTypeParams(list *ast.FieldList, depth int) string {
+	if list.NumFields() == 0 {
+		return ""
+	}
+	var tparams []string
+	for _, field := range list.List {
+		tparams = append(tparams, p.oneLineField(field, depth))
+	}
+	return "[" + joinParams(tparams) + "]"
+}
+
+// oneLineField returns a one-line summary of field.
+func (p *docPrinter) oneLineField(field *ast.Field, depth int) string {
+	var names []string
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+	if len(names) == 0 {
+		return p.oneLineNodeDepth(field.Type, depth)
+	}
+	return joinParams(names) + " " + p.oneLineNodeDepth(field.Type, depth)
+}
+
+// joinParams joins ss with commas, truncating the list with "..." once it
+// grows past punchedCardWidth.
+func joinParams(ss []string) string {
+	var n int
+	for i, s := range ss {
+		n += len(s) + len(", ")
+		if n > punchedCardWidth {
+			ss = append(ss[:i:i], "...")
+			break
+		}
+	}
+	return strings.Join(ss, ", ")
+}
+
+// matchSymbol reports whether the user's symbol matches the exported name
+// program as go doc matches them: a lower-case letter in symbol matches
+// either case.
+func matchSymbol(symbol, program string) bool {
+	if !token.IsExported(program) {
+		return false
+	}
+	for _, u := range symbol {
+		p, w := utf8.DecodeRuneInString(program)
+		program = program[w:]
+		if u == p {
+			continue
+		}
+		if unicode.IsLower(u) && simpleFold(u) == simpleFold(p) {
+			continue
+		}
+		return false
+	}
+	return program == ""
+}
+
+// simpleFold returns the minimum rune equivalent to r under Unicode simple
+// case folding.
+func simpleFold(r rune) rune {
+	for {
+		r1 := unicode.SimpleFold(r)
+		if r1 <= r {
+			return r1
+		}
+		r = r1
+	}
+}
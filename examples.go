@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const getExamplesDescription = `Get the runnable examples (Example functions from _test.go files) for a
+Go package, optionally only those for one symbol. Examples show idiomatic usage
+and are often the fastest way to learn how an API is meant to be called.
+
+Set include_output to false to omit the expected "// Output:" sections when
+only the usage pattern matters.`
+
+func (gs *godocServer) handleGetExamples(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	target := request.GetString("target", "")
+	includeOutput := request.GetBool("include_output", true)
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dir, err := gs.packageDir(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	fset := token.NewFileSet()
+	examples, err := packageExamples(fset, dir)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, ex := range examples {
+		if target != "" && !exampleMatches(ex.Name, target) {
+			continue
+		}
+		if n > 0 {
+			b.WriteString("\n")
+		}
+		writeExample(&b, fset, ex, includeOutput)
+		n++
+	}
+
+	if n == 0 {
+		if target != "" {
+			return mcp.NewToolResultText(fmt.Sprintf("No examples for %s in %s", target, pkgPath)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("No examples in %s", pkgPath)), nil
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// packageExamples parses the test files of the package in dir, including
+// external _test packages, and returns their examples sorted by name.
+func packageExamples(fset *token.FileSet, dir string) ([]*doc.Example, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package in %s: %w", dir, err)
+	}
+
+	var files []*ast.File
+	for _, name := range append(append([]string{}, bp.TestGoFiles...), bp.XTestGoFiles...) {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		files = append(files, f)
+	}
+	return doc.Examples(files...), nil
+}
+
+// exampleMatches reports whether the example named name documents target,
+// given as "Name" or "Type.Method". Suffixed examples such as
+// "Client_Do_retry" match as well.
+func exampleMatches(name, target string) bool {
+	want := strings.ReplaceAll(target, ".", "_")
+	if name == want {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(name, want+"_")
+	return ok && suffix != "" && !ast.IsExported(suffix)
+}
+
+// writeExample renders ex as its function name, doc comment, and body. The
+// expected output is appended as an "// Output:" comment when includeOutput
+// is set and the example declares one.
+func writeExample(b *strings.Builder, fset *token.FileSet, ex *doc.Example, includeOutput bool) {
+	fmt.Fprintf(b, "func Example%s()\n", ex.Name)
+	if ex.Doc != "" {
+		for _, line := range strings.Split(strings.TrimRight(ex.Doc, "\n"), "\n") {
+			b.WriteString("    " + line + "\n")
+		}
+	}
+
+	// The output comment is rendered separately, so drop it from the code.
+	var comments []*ast.CommentGroup
+	for _, c := range ex.Comments {
+		text := c.Text()
+		if strings.HasPrefix(text, "Output:") || strings.HasPrefix(text, "Unordered output:") {
+			continue
+		}
+		comments = append(comments, c)
+	}
+
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, &printer.CommentedNode{Node: ex.Code, Comments: comments})
+	code := strings.TrimSpace(buf.String())
+	if block, ok := ex.Code.(*ast.BlockStmt); ok && block != nil {
+		code = strings.TrimSuffix(strings.TrimPrefix(code, "{"), "}")
+	}
+	b.WriteString("\n")
+	for _, line := range strings.Split(strings.Trim(code, "\n"), "\n") {
+		b.WriteString("    " + strings.TrimPrefix(line, "\t") + "\n")
+	}
+
+	if includeOutput && (ex.Output != "" || ex.EmptyOutput) {
+		label := "Output:"
+		if ex.Unordered {
+			label = "Unordered output:"
+		}
+		b.WriteString("\n    // " + label + "\n")
+		for _, line := range strings.Split(strings.TrimRight(ex.Output, "\n"), "\n") {
+			if line != "" {
+				b.WriteString("    // " + line + "\n")
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestExampleMatches(t *testing.T) {
+	tests := []struct {
+		name, target string
+		want         bool
+	}{
+		{"Client_Do", "Client.Do", true},
+		{"Client_Do_retry", "Client.Do", true},
+		{"Client_DoAll", "Client.Do", false},
+		{"Client_Do", "Client", false},
+		{"Client_basic", "Client", true},
+		{"Reader", "Reader", true},
+	}
+	for _, tt := range tests {
+		if got := exampleMatches(tt.name, tt.target); got != tt.want {
+			t.Errorf("exampleMatches(%q, %q) = %v, want %v", tt.name, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestHandleGetExamples(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":   "module example.com/greet\n\ngo 1.21\n",
+		"greet.go": "package greet\n\n// Hello greets.\nfunc Hello(name string) string { return \"hello \" + name }\n",
+		"greet_test.go": `package greet_test
+
+import (
+	"fmt"
+
+	"example.com/greet"
+)
+
+// Greeting a user.
+func ExampleHello() {
+	// Say hello.
+	fmt.Println(greet.Hello("gopher"))
+	// Output:
+	// hello gopher
+}
+
+func Example() {
+	fmt.Println("package example")
+}
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_examples"
+		req.Params.Arguments = args
+		result, err := gs.handleGetExamples(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetExamples returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleGetExamples returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	all := call(map[string]any{"path": ".", "working_dir": dir})
+	for _, want := range []string{"func Example()", "func ExampleHello()", "    Greeting a user.", "    // Say hello.", "    fmt.Println(greet.Hello(\"gopher\"))", "    // Output:\n    // hello gopher"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("expected %q in output:\n%s", want, all)
+		}
+	}
+
+	hello := call(map[string]any{"path": ".", "working_dir": dir, "target": "Hello", "include_output": false})
+	if strings.Contains(hello, "func Example()") {
+		t.Errorf("target should filter out the package example:\n%s", hello)
+	}
+	if strings.Contains(hello, "Output:") || strings.Contains(hello, "hello gopher") {
+		t.Errorf("include_output=false should drop the output section:\n%s", hello)
+	}
+	if !strings.Contains(hello, "fmt.Println(greet.Hello(\"gopher\"))") {
+		t.Errorf("expected example code in output:\n%s", hello)
+	}
+
+	none := call(map[string]any{"path": ".", "working_dir": dir, "target": "Missing"})
+	if !strings.HasPrefix(none, "No examples for Missing") {
+		t.Errorf("unexpected result for missing target: %q", none)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// excludeModulePath is the module path of the temporary module an exclude
+// listing is documented in. go doc's header names it, so it is replaced
+// with the real import path. It cannot collide with a standard library
+// package, as a module named after the package itself could.
+const excludeModulePath = "godoc-mcp.invalid/exclude"
+
+// validateExclude checks that each exclude entry names a symbol: an
+// identifier, or "Type.Method".
+func validateExclude(names []string) error {
+	for _, name := range names {
+		typeName, member, isMember := strings.Cut(name, ".")
+		if !token.IsIdentifier(typeName) || (isMember && !token.IsIdentifier(member)) {
+			return fmt.Errorf("invalid exclude entry %q: use a symbol name such as Foo or Type.Method", name)
+		}
+	}
+	return nil
+}
+
+// excludedDoc documents the package of dr without the symbols in
+// dr.exclude. go doc cannot leave symbols out, so their declarations are
+// removed from the parsed source, which is then documented in a temporary
+// module with the request's flags; the output has go doc's layout, minus the
+// excluded symbols. A synopsis is rendered from the filtered source directly.
+func (gs *godocServer) excludedDoc(ctx context.Context, dr docRequest) (string, error) {
+	var lp *loadedPackage
+	var err error
+	if dr.collisionDir != "" {
+		if lp, err = parsePackage(dr.collisionDir); err == nil {
+			lp.path = dr.pkgPath
+		}
+	} else if lp, err = gs.loadPackage(ctx, dr.workingDir, dr.pkgPath); err == nil {
+		lp, err = lp.editable()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	missing := excludeDecls(lp, dr.exclude)
+	var doc string
+	if dr.synopsis {
+		doc, err = synopsisDoc(lp, "")
+	} else {
+		doc, err = gs.filteredGoDoc(ctx, lp, dr.cmdFlags, "")
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(missing) > 0 {
+		doc += fmt.Sprintf("\nNote: %s declares no %s to exclude.\n", dr.pkgPath, strings.Join(missing, ", "))
+	}
+	return doc, nil
+}
+
+// excludeDecls removes from lp's files the declarations of the symbols in
+// names, along with their comments: functions, "Type.Method" methods, and
+// types with all their methods. go/doc then drops the constructors of an
+// excluded type too, as it groups them under the type. A const or var spec is removed when all the
+// names it declares are excluded, and a declaration when none of its specs
+// remain. It returns the names that matched nothing, in order.
+func excludeDecls(lp *loadedPackage, names []string) []string {
+	exclude := make(map[string]bool, len(names))
+	for _, name := range names {
+		exclude[name] = true
+	}
+	found := make(map[string]bool)
+	// removed holds the source ranges, from doc comment to line comment, of
+	// the removed declarations and specs.
+	var removed [][2]token.Pos
+	remove := func(doc *ast.CommentGroup, pos, end token.Pos, comment *ast.CommentGroup) {
+		r := [2]token.Pos{pos, end}
+		if doc != nil {
+			r[0] = doc.Pos()
+		}
+		if comment != nil {
+			r[1] = comment.End()
+		}
+		removed = append(removed, r)
+	}
+
+	for _, f := range lp.files {
+		decls := f.Decls[:0]
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				name, recv := d.Name.Name, ""
+				if d.Recv != nil && len(d.Recv.List) > 0 {
+					recv = receiverName(d)
+					name = recv + "." + name
+				}
+				if exclude[name] || exclude[recv] {
+					found[name] = found[name] || exclude[name]
+					found[recv] = found[recv] || exclude[recv]
+					remove(d.Doc, d.Pos(), d.End(), nil)
+					continue
+				}
+			case *ast.GenDecl:
+				// The end of a declaration without specs is unknown.
+				pos, end := d.Pos(), d.End()
+				specs := d.Specs[:0]
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						if exclude[s.Name.Name] {
+							found[s.Name.Name] = true
+							remove(s.Doc, s.Pos(), s.End(), s.Comment)
+							continue
+						}
+					case *ast.ValueSpec:
+						all := true
+						for _, id := range s.Names {
+							all = all && exclude[id.Name]
+						}
+						if all && d.Tok != token.IMPORT {
+							for _, id := range s.Names {
+								found[id.Name] = true
+							}
+							remove(s.Doc, s.Pos(), s.End(), s.Comment)
+							continue
+						}
+					}
+					specs = append(specs, spec)
+				}
+				d.Specs = specs
+				if len(specs) == 0 {
+					remove(d.Doc, pos, end, nil)
+					continue
+				}
+			}
+			decls = append(decls, decl)
+		}
+		f.Decls = decls
+		removeComments(f, removed)
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// removeComments drops the comments of f that lie within one of the removed
+// source ranges, so the printer does not leave them behind.
+func removeComments(f *ast.File, removed [][2]token.Pos) {
+	comments := f.Comments[:0]
+	for _, cg := range f.Comments {
+		inRemoved := false
+		for _, r := range removed {
+			inRemoved = inRemoved || (cg.Pos() >= r[0] && cg.End() <= r[1])
+		}
+		if !inRemoved {
+			comments = append(comments, cg)
+		}
+	}
+	f.Comments = comments
+}
+
+// filteredGoDoc runs go doc with flags on lp's files as they are now, in a
+// temporary module, and reports the package under its real import path. A
+// non-empty target documents that symbol instead of the package.
+func (gs *godocServer) filteredGoDoc(ctx context.Context, lp *loadedPackage, flags []string, target string) (string, error) {
+	tmp, err := os.MkdirTemp("", "godoc-mcp-exclude-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := os.WriteFile(filepath.Join(tmp, "go.mod"), []byte("module "+excludeModulePath+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to write go.mod: %w", err)
+	}
+	for _, f := range lp.files {
+		var buf bytes.Buffer
+		if err := format.Node(&buf, lp.fset, f); err != nil {
+			return "", fmt.Errorf("failed to print filtered source: %w", err)
+		}
+		name := filepath.Base(lp.fset.Position(f.Package).Filename)
+		if err := os.WriteFile(filepath.Join(tmp, name), buf.Bytes(), 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	// The temporary module has no dependencies or vendor directory, and is
+	// outside any workspace, so the request's -mod mode does not apply.
+	execCtx, cancel := commandContext(withModMode(ctx, ""))
+	defer cancel()
+	args := append(append([]string{"doc"}, flags...), ".")
+	if target != "" {
+		args = append(args, target)
+	}
+	cmd := gs.goCommand(execCtx, tmp, args...)
+	cmd.Env = append(cmd.Env, "GOWORK=off")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := gs.output(execCtx, cmd)
+	if err != nil {
+		return "", formatGoDocError(stderr.String()+string(out), err)
+	}
+	return strings.Replace(string(out), `// import "`+excludeModulePath+`"`, `// import "`+lp.path+`"`, 1), nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"go/format"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const excludeTestSource = `// Package ex lists symbols.
+package ex
+
+import "errors"
+
+// Mode constants.
+const (
+	ModeA = iota // first
+	ModeB        // second
+)
+
+// ErrX is an error.
+var ErrX = errors.New("x")
+
+// Grouped vars.
+var (
+	// V1 is one.
+	V1 = 1
+	V2 = 2
+)
+
+// Do does things.
+func Do() error { return nil }
+
+// Helper helps.
+func Helper() {}
+
+// T is a type.
+type T struct {
+	// Name names it.
+	Name string
+}
+
+// NewT makes a T.
+func NewT() *T { return nil }
+
+// M is a method.
+func (t *T) M() {}
+
+// Level is a level.
+type Level int
+
+// String formats l.
+func (l Level) String() string { return "" }
+
+// Set sets l.
+func (l *Level) Set(s string) error { return nil }
+`
+
+func TestValidateExclude(t *testing.T) {
+	for _, name := range []string{"Foo", "Type.Method", "_x"} {
+		if err := validateExclude([]string{name}); err != nil {
+			t.Errorf("validateExclude(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", "a.b.c", "Type.", "1x", "io.Reader x"} {
+		if err := validateExclude([]string{"Ok", name}); err == nil {
+			t.Errorf("validateExclude(%q) = nil, want error", name)
+		}
+	}
+}
+
+func TestExcludeDecls(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/ex\n\ngo 1.21\n",
+		"ex.go":  excludeTestSource,
+	})
+	lp, err := parsePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	missing := excludeDecls(lp, []string{"Helper", "T", "Level.String", "V1", "ErrX", "Missing", "Level.Missing"})
+	if got := strings.Join(missing, ","); got != "Missing,Level.Missing" {
+		t.Errorf("missing = %q, want Missing,Level.Missing", got)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, lp.fset, lp.files[0]); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	for _, gone := range []string{"Helper", "type T", "T is a type", "Name names it", "M is a method", "String formats", "V1", "ErrX"} {
+		if strings.Contains(src, gone) {
+			t.Errorf("filtered source still contains %q:\n%s", gone, src)
+		}
+	}
+	for _, kept := range []string{"func Do() error", "func NewT() *T", "Grouped vars.", "V2 = 2", "ModeA = iota // first", "func (l *Level) Set", `import "errors"`} {
+		if !strings.Contains(src, kept) {
+			t.Errorf("filtered source lost %q:\n%s", kept, src)
+		}
+	}
+}
+
+func TestHandleGetDocExclude(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/ex\n\ngo 1.21\n",
+		"ex.go":  excludeTestSource,
+	})
+	gs := newGodocServer()
+	ctx := context.Background()
+
+	call := func(args map[string]any) (string, bool) {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(ctx, req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "index",
+			args:    map[string]any{"path": ".", "working_dir": dir, "exclude": []any{"Helper", "T"}},
+			want:    []string{`package ex // import "example.com/ex"`, "Package ex lists symbols.", "func Do() error", "type Level int"},
+			notWant: []string{"Helper", "type T", "NewT", excludeModulePath},
+		},
+		{
+			name:    "all",
+			args:    map[string]any{"path": ".", "working_dir": dir, "exclude": []any{"Level.String", "ErrX"}, "cmd_flags": []any{"-all"}},
+			want:    []string{"FUNCTIONS", "Do does things.", "func (l *Level) Set(s string) error", "M is a method."},
+			notWant: []string{"String formats", "ErrX"},
+		},
+		{
+			name:    "synopsis",
+			args:    map[string]any{"path": ".", "working_dir": dir, "exclude": []any{"Do"}, "synopsis": true},
+			want:    []string{"func Helper()\n    Helper helps."},
+			notWant: []string{"Do does things"},
+		},
+		{
+			name: "unknown name",
+			args: map[string]any{"path": ".", "working_dir": dir, "exclude": []any{"Nope"}},
+			want: []string{"func Helper()", "Note: example.com/ex declares no Nope to exclude."},
+		},
+		{
+			name:    "standard library",
+			args:    map[string]any{"path": "io", "exclude": []any{"Reader", "Writer"}},
+			want:    []string{`package io // import "io"`, "type ReadWriter interface", "func Copy("},
+			notWant: []string{"type Reader interface", "type Writer interface"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, isErr := call(tt.args)
+			if isErr {
+				t.Fatalf("unexpected tool error: %s", text)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("missing %q in:\n%s", want, text)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(text, notWant) {
+					t.Errorf("unexpected %q in:\n%s", notWant, text)
+				}
+			}
+		})
+	}
+
+	if text, isErr := call(map[string]any{"path": ".", "working_dir": dir, "target": "Do", "exclude": []any{"Helper"}}); !isErr || !strings.Contains(text, "exclude cannot be combined with a target symbol") {
+		t.Errorf("expected a conflict error for exclude with a target, got %q", text)
+	}
+	if text, isErr := call(map[string]any{"path": ".", "working_dir": dir, "exclude": []any{"a.b.c"}}); !isErr || !strings.Contains(text, "invalid exclude entry") {
+		t.Errorf("expected an invalid entry error, got %q", text)
+	}
+}
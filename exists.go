@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const symbolExistsDescription = `Check whether a Go package exports a symbol, without fetching its documentation.
+Returns only whether the symbol exists and, if it does, its kind (func, method,
+type, const, or var). Use this to validate an identifier such as 'ReadAll' or
+'Reader.Read' before writing code that references it; it is cheaper than whatis
+or get_doc.`
+
+// symbolExistence is the symbol_exists result.
+type symbolExistence struct {
+	Exists bool   `json:"exists"`
+	Kind   string `json:"kind,omitempty"` // "func", "method", "type", "const", or "var"
+}
+
+func (gs *godocServer) handleSymbolExists(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	target, err := request.RequireString("target")
+	if err != nil {
+		return mcp.NewToolResultError("target argument is required"), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var result symbolExistence
+	if sym, ok := findSymbol(dp, target); ok {
+		result = symbolExistence{Exists: true, Kind: sym.kind}
+	}
+	out, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode result: %v", err)), nil
+	}
+	return mcp.NewToolResultStructured(result, string(out)), nil
+}
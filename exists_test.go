@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleSymbolExists(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/exists\n\ngo 1.21\n",
+		"exists.go": `package exists
+
+// Reader reads.
+type Reader struct{}
+
+// Read reads.
+func (r *Reader) Read(p []byte) (int, error) { return 0, nil }
+
+// NewReader returns a Reader.
+func NewReader() *Reader { return nil }
+
+// Max is the limit.
+const Max = 1
+
+func helper() {}
+`,
+	})
+
+	gs := newGodocServer()
+	defer gs.cleanup()
+	tests := []struct {
+		target string
+		want   symbolExistence
+	}{
+		{"Reader", symbolExistence{Exists: true, Kind: "type"}},
+		{"Reader.Read", symbolExistence{Exists: true, Kind: "method"}},
+		{"NewReader", symbolExistence{Exists: true, Kind: "func"}},
+		{"Max", symbolExistence{Exists: true, Kind: "const"}},
+		{"Reader.Write", symbolExistence{}},
+		{"helper", symbolExistence{}},
+		{"Missing", symbolExistence{}},
+	}
+	for _, tt := range tests {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]any{"path": ".", "working_dir": dir, "target": tt.target}
+		result, err := gs.handleSymbolExists(context.Background(), req)
+		if err != nil {
+			t.Fatalf("%s: protocol error: %v", tt.target, err)
+		}
+		if result.IsError {
+			t.Fatalf("%s: tool error: %+v", tt.target, result.Content)
+		}
+		if got := result.StructuredContent; got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.target, got, tt.want)
+		}
+	}
+
+	// Interface methods have no declaration of their own.
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"path": "io", "target": "Reader.Read"}
+	result, err := gs.handleSymbolExists(context.Background(), req)
+	if err != nil {
+		t.Fatalf("protocol error: %v", err)
+	}
+	if want := (symbolExistence{Exists: true, Kind: "method"}); result.StructuredContent != want {
+		t.Errorf("io Reader.Read: got %+v, want %+v", result.StructuredContent, want)
+	}
+
+	req = mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"path": "./missing", "working_dir": dir, "target": "X"}
+	result, err = gs.handleSymbolExists(context.Background(), req)
+	if err != nil {
+		t.Fatalf("protocol error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("missing package: want tool error, got %+v", result.Content)
+	}
+}
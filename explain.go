@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const explainErrorDescription = `Fetch the definitions behind a Go compiler or runtime error.
+Paste an error such as "cannot use b (variable of type *bytes.Buffer) as
+io.ReadCloser value" or a panic stack trace; the qualified names in it
+(bytes.Buffer, io.ReadCloser, net/http.(*Client).Do) and, with path, the
+names of that package's own types are looked up, and their definitions,
+including each type's method signatures, are returned side by side. Common
+mistakes such as a missing method or a method declared on the pointer type are
+called out. Use this to diagnose type mismatches without separate get_doc
+calls for every type involved.`
+
+// maxErrorSymbols caps how many definitions explain_error returns.
+const maxErrorSymbols = 8
+
+var (
+	// errorQualifiedName matches "pkg.Name" and "example.com/pkg.Name" in an
+	// error, including the "pkg.(*Type).Method" form of stack traces.
+	errorQualifiedName = regexp.MustCompile(`((?:[\w.-]+/)*[\w-]+)\.(?:\(\*?([A-Za-z_]\w*)\)\.([A-Za-z_]\w*)|([A-Za-z_]\w*))`)
+	// errorIdent matches identifiers not preceded by a dot or path.
+	errorIdent = regexp.MustCompile(`(?:^|[^\w./])([A-Za-z_]\w*)`)
+	// missingMethod and pointerReceiver match the compiler's explanation of
+	// a failed interface satisfaction.
+	missingMethod   = regexp.MustCompile(`(\S+) does not implement (\S+) \((?:missing method|wrong type for method) (\w+)\)`)
+	pointerReceiver = regexp.MustCompile(`(\S+) does not implement (\S+) \(method (\w+) has pointer receiver\)`)
+)
+
+// errorSymbol is a symbol named in an error message.
+type errorSymbol struct {
+	importPath string
+	name       string // "Name" or "Type.Method"
+}
+
+func (gs *godocServer) handleExplainError(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	message, err := request.RequireString("error")
+	if err != nil {
+		return mcp.NewToolResultError("error argument is required"), nil
+	}
+	workingDir := request.GetString("working_dir", "")
+
+	// The package the error was reported in resolves unqualified names and
+	// the import names its files use.
+	var local *loadedPackage
+	imports := make(map[string]string)
+	if p := request.GetString("path", ""); p != "" {
+		pkgPath, wd, err := gs.resolvePackage(ctx, p, workingDir)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		workingDir = wd
+		if local, err = gs.loadPackage(ctx, workingDir, pkgPath); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, f := range local.files {
+			imports[f.Name.Name] = pkgPath
+			for _, imp := range f.Imports {
+				ip := strings.Trim(imp.Path.Value, "`\"")
+				name := importDefaultName(ip)
+				if imp.Name != nil {
+					name = imp.Name.Name
+				}
+				imports[name] = ip
+			}
+		}
+	}
+
+	syms := errorSymbols(message, local, imports)
+	if len(syms) == 0 {
+		return mcp.NewToolResultText("No package-level types or functions could be identified in the error.\n" +
+			"Qualified names such as bytes.Buffer are looked up directly; pass path to also resolve the error's package's own names."), nil
+	}
+
+	var b strings.Builder
+	for i, sym := range syms {
+		if i == maxErrorSymbols {
+			fmt.Fprintf(&b, "... and %d more symbols\n\n", len(syms)-maxErrorSymbols)
+			break
+		}
+		def, err := gs.errorSymbolDef(ctx, workingDir, local, sym)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "=== %s.%s ===\n\n%s\n", sym.importPath, sym.name, strings.TrimRight(def, "\n")+"\n")
+	}
+	if b.Len() == 0 {
+		return mcp.NewToolResultText("None of the names in the error could be found; check working_dir and path."), nil
+	}
+
+	if m := pointerReceiver.FindStringSubmatch(message); m != nil {
+		fmt.Fprintf(&b, "HINT: %s is declared on the pointer type, so only a pointer satisfies %s; pass &x instead of x.\n", m[3], m[2])
+	} else if m := missingMethod.FindStringSubmatch(message); m != nil {
+		fmt.Fprintf(&b, "HINT: %s requires a method %s with the signature shown above; compare it with the methods of %s.\n", m[2], m[3], m[1])
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// errorSymbols extracts the symbols an error message refers to, in order of
+// first appearance: qualified names whose package resolves through imports
+// or the standard library, and identifiers that local declares.
+func errorSymbols(message string, local *loadedPackage, imports map[string]string) []errorSymbol {
+	type hit struct {
+		pos int
+		sym errorSymbol
+	}
+	var hits []hit
+	for _, m := range errorQualifiedName.FindAllStringSubmatchIndex(message, -1) {
+		qualifier := message[m[2]:m[3]]
+		ip := resolveQualifier(qualifier, imports)
+		if ip == "" {
+			continue
+		}
+		name := ""
+		if m[4] >= 0 {
+			name = message[m[4]:m[5]] + "." + message[m[6]:m[7]]
+		} else {
+			name = message[m[8]:m[9]]
+		}
+		hits = append(hits, hit{m[0], errorSymbol{ip, name}})
+	}
+
+	if local != nil {
+		declared := declaredNames(local.files)
+		for _, m := range errorIdent.FindAllStringSubmatchIndex(message, -1) {
+			name := message[m[2]:m[3]]
+			if declared[name] && (m[3] == len(message) || message[m[3]] != '.') {
+				hits = append(hits, hit{m[2], errorSymbol{local.path, name}})
+			}
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].pos < hits[j].pos })
+	seen := make(map[errorSymbol]bool)
+	var syms []errorSymbol
+	for _, h := range hits {
+		if !seen[h.sym] {
+			seen[h.sym] = true
+			syms = append(syms, h.sym)
+		}
+	}
+	return syms
+}
+
+// resolveQualifier maps the package qualifier of a name in an error to an
+// import path: a full import path as printed for ambiguous packages, a name
+// imported by the error's package, or a standard library package name.
+func resolveQualifier(qualifier string, imports map[string]string) string {
+	if ip, ok := imports[qualifier]; ok {
+		return ip
+	}
+	if strings.Contains(qualifier, "/") {
+		return qualifier
+	}
+	if stdPackageExists(qualifier) {
+		return qualifier
+	}
+	// Nested standard packages are printed by name only (json, http).
+	for _, pattern := range []string{"*", "*/*"} {
+		matches, _ := filepath.Glob(filepath.Join(build.Default.GOROOT, "src", pattern, qualifier))
+		for _, m := range matches {
+			rel, _ := filepath.Rel(filepath.Join(build.Default.GOROOT, "src"), m)
+			ip := filepath.ToSlash(rel)
+			if !isInternalPath(ip) && !strings.Contains(ip, "vendor") && !strings.Contains(ip, "testdata") && !strings.HasPrefix(ip, "cmd/") {
+				return ip
+			}
+		}
+	}
+	return ""
+}
+
+// importDefaultName guesses the package name of importPath: its last
+// element, skipping a major version suffix and a gopkg.in-style ".vN".
+func importDefaultName(importPath string) string {
+	name := path.Base(importPath)
+	if majorSuffix.MatchString(name) && path.Dir(importPath) != "." {
+		name = path.Base(path.Dir(importPath))
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// declaredNames returns the package-level type, function, constant, and
+// variable names declared in files, exported or not.
+func declaredNames(files []*ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					names[d.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						names[s.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, id := range s.Names {
+							names[id.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	delete(names, "_")
+	delete(names, "init")
+	delete(names, "main")
+	return names
+}
+
+// errorSymbolDef renders the definition of sym: for a type, its declaration
+// followed by its constructor and method signatures; otherwise the symbol's
+// signature. Unexported types, which compiler errors name as often as
+// exported ones, are rendered with all of their methods.
+func (gs *godocServer) errorSymbolDef(ctx context.Context, workingDir string, local *loadedPackage, sym errorSymbol) (string, error) {
+	var lp *loadedPackage
+	var err error
+	if local != nil && sym.importPath == local.path {
+		// Reparse: go/doc trims unexported declarations from the files it reads.
+		if lp, err = parsePackage(local.dir); err != nil {
+			return "", err
+		}
+		lp.path, lp.workingDir = local.path, local.workingDir
+	} else {
+		ip, wd, err := gs.resolvePackage(ctx, sym.importPath, workingDir)
+		if err != nil {
+			return "", err
+		}
+		if lp, err = gs.loadPackage(ctx, wd, ip); err != nil {
+			return "", err
+		}
+	}
+
+	if typeName, _, _ := strings.Cut(sym.name, "."); !ast.IsExported(typeName) {
+		ts, docText := findTypeSpec(lp.files, typeName)
+		if ts == nil {
+			return "", fmt.Errorf("%s is not a type in %s", typeName, sym.importPath)
+		}
+		var b strings.Builder
+		writeTypeDecl(&b, lp.fset, ts, docText)
+		for _, f := range lp.files {
+			for _, decl := range f.Decls {
+				if fd, ok := decl.(*ast.FuncDecl); ok && fd.Recv != nil && embeddedName(fd.Recv.List[0].Type) == typeName {
+					b.WriteString(symbolSignature(lp.fset, docSymbol{name: fd.Name.Name, kind: "func", decl: fd}) + "\n")
+				}
+			}
+		}
+		return b.String(), nil
+	}
+	return signatureDoc(lp, sym.name)
+}
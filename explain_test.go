@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleExplainError(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.21\n",
+		"app.go": `package app
+
+import (
+	"bytes"
+	"io"
+)
+
+// buffer wraps a bytes.Buffer.
+type buffer struct{ buf bytes.Buffer }
+
+func (b *buffer) Read(p []byte) (int, error) { return b.buf.Read(p) }
+
+func (b *buffer) Close() error { return nil }
+
+var _ io.ReadCloser = &buffer{}
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "explain_error"
+		req.Params.Arguments = args
+		result, err := gs.handleExplainError(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleExplainError returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleExplainError returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := call(map[string]any{
+		"error":       "./app.go:17:23: cannot use buffer{} (value of struct type buffer) as io.ReadCloser value in variable declaration: buffer does not implement io.ReadCloser (method Close has pointer receiver)",
+		"path":        ".",
+		"working_dir": dir,
+	})
+	for _, want := range []string{
+		"=== example.com/app.buffer ===",
+		"type buffer struct",
+		"func (b *buffer) Close() error",
+		"=== io.ReadCloser ===",
+		"type ReadCloser interface",
+		"HINT: Close is declared on the pointer type",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "app.buffer ===") > strings.Index(text, "io.ReadCloser ===") {
+		t.Errorf("definitions not in order of appearance:\n%s", text)
+	}
+
+	text = call(map[string]any{"error": "panic: runtime error\n\ngoroutine 1:\nencoding/json.(*Decoder).Decode(0xc000010000)"})
+	if !strings.Contains(text, "=== encoding/json.Decoder.Decode ===") || !strings.Contains(text, "func (dec *Decoder) Decode(v any) error") {
+		t.Errorf("stack frame not resolved:\n%s", text)
+	}
+
+	text = call(map[string]any{"error": "undefined: frobnicate"})
+	if !strings.Contains(text, "No package-level types or functions") {
+		t.Errorf("unexpected result for an error without symbols:\n%s", text)
+	}
+}
+
+func TestErrorSymbolsQualifiers(t *testing.T) {
+	imports := map[string]string{"yaml": "gopkg.in/yaml.v3"}
+	syms := errorSymbols("cannot use v (variable of type yaml.Node) as json.Marshaler value: yaml.Node does not implement json.Marshaler", nil, imports)
+	want := []errorSymbol{{"gopkg.in/yaml.v3", "Node"}, {"encoding/json", "Marshaler"}}
+	if len(syms) != len(want) {
+		t.Fatalf("errorSymbols = %v, want %v", syms, want)
+	}
+	for i := range want {
+		if syms[i] != want[i] {
+			t.Errorf("errorSymbols[%d] = %v, want %v", i, syms[i], want[i])
+		}
+	}
+
+	for path, name := range map[string]string{
+		"gopkg.in/yaml.v3":             "yaml",
+		"github.com/jackc/pgx/v5":      "pgx",
+		"github.com/go-chi/chi":        "chi",
+		"github.com/google/go-cmp/cmp": "cmp",
+		"net/http":                     "http",
+	} {
+		if got := importDefaultName(path); got != name {
+			t.Errorf("importDefaultName(%q) = %q, want %q", path, got, name)
+		}
+	}
+}
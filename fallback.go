@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// shortFallbackDoc handles a timeout, docErr, of go doc -all on the package
+// of dr by retrying with -short in place of -all, which lists one line per
+// symbol and finishes far sooner on huge packages. The result ends with a
+// note saying the full documentation timed out. docErr is returned when the
+// retry fails too.
+func (gs *godocServer) shortFallbackDoc(ctx context.Context, dr docRequest, docErr error) (string, error) {
+	var args []string
+	for _, f := range dr.cmdFlags {
+		// The index replaces -all and has no room for -src's source.
+		if f != "-all" && f != "-src" && f != "-short" {
+			args = append(args, f)
+		}
+	}
+	args = append(args, "-short", dr.pkgPath)
+	doc, err := gs.runGoDocHashed(ctx, dr.workingDir, dr.srcHash, args...)
+	if err != nil {
+		return "", docErr
+	}
+	return doc + fmt.Sprintf("\nNote: the full documentation (-all) of %s timed out after %s, so only the -short symbol index is shown. "+
+		"Request symbols individually with target, or retry with a larger timeout_seconds.\n", dr.pkgPath, commandTimeout(ctx)), nil
+}
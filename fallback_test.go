@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShortFallbackDoc(t *testing.T) {
+	fakeGo(t, `case "$*" in
+doc*-all*) exec sleep 5 ;;
+doc*-short*) echo "func Open(name string) (*File, error)" ;;
+esac
+`)
+	ctx := withCmdTimeout(context.Background(), 200*time.Millisecond)
+	gs := newGodocServer()
+	defer gs.cleanup()
+
+	doc, err := gs.targetDoc(ctx, docRequest{pkgPath: "demo", cmdFlags: []string{"-all"}, unexported: "none"}, "")
+	if err != nil {
+		t.Fatalf("targetDoc: %v", err)
+	}
+	if !strings.Contains(doc, "func Open(name string) (*File, error)") {
+		t.Errorf("fallback did not return the -short index:\n%s", doc)
+	}
+	if !strings.Contains(doc, "(-all) of demo timed out after 200ms") {
+		t.Errorf("fallback note missing:\n%s", doc)
+	}
+
+	// A symbol request has no index to fall back to.
+	_, err = gs.targetDoc(ctx, docRequest{pkgPath: "demo", cmdFlags: []string{"-all"}, unexported: "none"}, "Open")
+	if !errors.Is(err, errGoDocTimeout) {
+		t.Errorf("symbol request: got %v, want errGoDocTimeout", err)
+	}
+}
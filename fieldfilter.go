@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+)
+
+// filtersFields reports whether dr narrows a struct target to some of its
+// fields.
+func (dr docRequest) filtersFields() bool {
+	return dr.fieldFilter != "" || dr.documentedFields
+}
+
+// fieldFilteredDoc documents the struct type target of dr's package showing
+// only the fields that match dr's filters: a case-insensitive substring of
+// the field name, and a doc or line comment. go doc cannot filter fields, so
+// the others are removed from the parsed source, which is then documented in
+// a temporary module, as for exclude. A note reports how many fields are
+// shown.
+func (gs *godocServer) fieldFilteredDoc(ctx context.Context, dr docRequest, target string) (string, error) {
+	var lp *loadedPackage
+	var err error
+	if dr.collisionDir != "" {
+		if lp, err = parsePackage(dr.collisionDir); err == nil {
+			lp.path = dr.pkgPath
+		}
+	} else if lp, err = gs.loadPackage(ctx, dr.workingDir, dr.pkgPath); err == nil {
+		lp, err = lp.editable()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	ts, _ := findTypeSpec(lp.files, target)
+	if ts == nil {
+		return "", fmt.Errorf("%w: no type %s in %s", errSymbolNotFound, target, dr.pkgPath)
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return "", fmt.Errorf("%s.%s is not a struct type; fields and documented_fields only apply to structs", dr.pkgPath, target)
+	}
+
+	shown, total := filterFields(lp, st, dr.fieldFilter, dr.documentedFields, slices.Contains(dr.cmdFlags, "-u"))
+	doc, err := gs.filteredGoDoc(ctx, lp, dr.cmdFlags, target)
+	if err != nil {
+		return "", err
+	}
+
+	var criteria []string
+	if dr.fieldFilter != "" {
+		criteria = append(criteria, fmt.Sprintf("name containing %q", dr.fieldFilter))
+	}
+	if dr.documentedFields {
+		criteria = append(criteria, "a doc comment")
+	}
+	return doc + fmt.Sprintf("\nNote: showing %d of %d fields of %s, those with %s.\n", shown, total, target, strings.Join(criteria, " and ")), nil
+}
+
+// filterFields removes from st, and its comments from lp's files, the fields
+// whose names do not contain filter (ignoring case) or, when documented is
+// set, that have neither a doc nor a line comment. A field declaring several
+// names is kept when any of them matches. Only fields go doc shows are
+// counted: exported ones, or all with unexported set. It returns the number
+// of those fields kept and the number there were.
+func filterFields(lp *loadedPackage, st *ast.StructType, filter string, documented, unexported bool) (shown, total int) {
+	filter = strings.ToLower(filter)
+	var removed [][2]token.Pos
+	fields := st.Fields.List[:0]
+	for _, field := range st.Fields.List {
+		names := []string{embeddedName(field.Type)}
+		if len(field.Names) > 0 {
+			names = names[:0]
+			for _, id := range field.Names {
+				names = append(names, id.Name)
+			}
+		}
+		visible, matches := 0, false
+		for _, name := range names {
+			if unexported || token.IsExported(name) {
+				visible++
+				matches = matches || strings.Contains(strings.ToLower(name), filter)
+			}
+		}
+		total += visible
+		if documented && field.Doc == nil && field.Comment == nil {
+			matches = false
+		}
+		if !matches {
+			r := [2]token.Pos{field.Pos(), field.End()}
+			if field.Doc != nil {
+				r[0] = field.Doc.Pos()
+			}
+			if field.Comment != nil {
+				r[1] = field.Comment.End()
+			}
+			removed = append(removed, r)
+			continue
+		}
+		shown += visible
+		fields = append(fields, field)
+	}
+	st.Fields.List = fields
+	// Close the struct right after the last field kept, so the lines of the
+	// removed ones do not leave a gap before the brace.
+	if len(fields) > 0 {
+		last := fields[len(fields)-1]
+		st.Fields.Closing = last.End()
+		if last.Comment != nil {
+			st.Fields.Closing = last.Comment.End()
+		}
+	}
+	for _, f := range lp.files {
+		removeComments(f, removed)
+	}
+	return shown, total
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"go/ast"
+	"go/format"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const fieldFilterTestSource = `package cfg
+
+import "time"
+
+// Config configures the client.
+type Config struct {
+	// ReadTimeout bounds reads.
+	ReadTimeout time.Duration
+	WriteTimeout time.Duration // bounds writes
+	Retries int
+	// Name and Alias identify the client.
+	Name, Alias string
+	time.Location
+	dialTimeout time.Duration
+}
+
+// Mode is not a struct.
+type Mode int
+`
+
+func TestFilterFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		filter     string
+		documented bool
+		unexported bool
+		shown      int
+		total      int
+		kept       []string
+		gone       []string
+	}{
+		{"substring", "timeout", false, false, 2, 6, []string{"ReadTimeout", "// bounds writes"}, []string{"Retries", "Name", "Location", "dialTimeout"}},
+		{"substring with unexported", "TIMEOUT", false, true, 3, 7, []string{"ReadTimeout", "dialTimeout"}, []string{"Retries"}},
+		{"documented", "", true, false, 4, 6, []string{"ReadTimeout bounds reads.", "WriteTimeout", "Name, Alias"}, []string{"Retries", "Location"}},
+		{"both", "alias", true, false, 2, 6, []string{"Name and Alias identify", "Name, Alias string"}, []string{"ReadTimeout", "WriteTimeout"}},
+		{"embedded", "loc", false, false, 1, 6, []string{"time.Location"}, []string{"ReadTimeout", "Name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeModule(t, map[string]string{
+				"go.mod": "module example.com/cfg\n\ngo 1.21\n",
+				"cfg.go": fieldFilterTestSource,
+			})
+			lp, err := parsePackage(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ts, _ := findTypeSpec(lp.files, "Config")
+			st := ts.Type.(*ast.StructType)
+			shown, total := filterFields(lp, st, tt.filter, tt.documented, tt.unexported)
+			if shown != tt.shown || total != tt.total {
+				t.Errorf("filterFields() = %d, %d; want %d, %d", shown, total, tt.shown, tt.total)
+			}
+
+			var buf bytes.Buffer
+			if err := format.Node(&buf, lp.fset, lp.files[0]); err != nil {
+				t.Fatal(err)
+			}
+			src := buf.String()
+			for _, kept := range tt.kept {
+				if !strings.Contains(src, kept) {
+					t.Errorf("filtered source lost %q:\n%s", kept, src)
+				}
+			}
+			for _, gone := range tt.gone {
+				if strings.Contains(src, gone) {
+					t.Errorf("filtered source still contains %q:\n%s", gone, src)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleGetDocFields(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/cfg\n\ngo 1.21\n",
+		"cfg.go": fieldFilterTestSource,
+	})
+	gs := newGodocServer()
+	defer gs.cleanup()
+
+	call := func(args map[string]any) (string, bool) {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	text, isErr := call(map[string]any{"path": ".", "working_dir": dir, "target": "Config", "fields": "timeout"})
+	if isErr {
+		t.Fatalf("unexpected error: %s", text)
+	}
+	for _, want := range []string{"type Config struct", "ReadTimeout  time.Duration\n", "WriteTimeout time.Duration // bounds writes\n}", "Config configures the client.", "showing 2 of 6 fields of Config, those with name containing \"timeout\""} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	for _, gone := range []string{"Retries", "Alias", "godoc-mcp.invalid"} {
+		if strings.Contains(text, gone) {
+			t.Errorf("output contains %q:\n%s", gone, text)
+		}
+	}
+
+	text, isErr = call(map[string]any{"path": ".", "working_dir": dir, "target": "Config", "documented_fields": true})
+	if isErr || !strings.Contains(text, "Name, Alias string") || strings.Contains(text, "Retries") || !strings.Contains(text, "those with a doc comment") {
+		t.Errorf("documented_fields output unexpected (error %v):\n%s", isErr, text)
+	}
+
+	text, isErr = call(map[string]any{"path": ".", "working_dir": dir, "target": "Mode", "fields": "x"})
+	if !isErr || !strings.Contains(text, "not a struct type") {
+		t.Errorf("non-struct target: want error, got %v: %s", isErr, text)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const listFilesDescription = `List the Go source files that make up a package, with a one-line
+synopsis of each. Files are grouped as go list reports them: source files,
+cgo files, test files (internal and external _test packages), and files
+excluded by build constraints. Each file is shown with its line count and a
+summary taken from its first doc comment, or the exported names it declares
+when it has none. Use this to decide which file to open with read_lines
+instead of reading files blindly.`
+
+const filePackageDescription = `Find the package a Go source file belongs to, given its absolute path: the
+package's import path and name, its synopsis, the file's role in it (source,
+cgo, test, external test, or excluded by build constraints), and its sibling
+files grouped as list_files groups them. Use this when you know the file
+being edited but not its package, to orient yourself before documenting the
+package or reading its source.`
+
+// maxFileNames caps how many declared names describe an undocumented file.
+const maxFileNames = 5
+
+// packageFiles is the subset of go list -json output that list_files uses.
+type packageFiles struct {
+	Dir            string
+	GoFiles        []string
+	CgoFiles       []string
+	TestGoFiles    []string
+	XTestGoFiles   []string
+	IgnoredGoFiles []string
+}
+
+func (gs *godocServer) handleListFiles(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+	cmd := gs.goCommand(listCtx, workingDir, "list", "-e", "-json=Dir,GoFiles,CgoFiles,TestGoFiles,XTestGoFiles,IgnoredGoFiles", pkgPath)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := gs.output(listCtx, cmd)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("go list %s failed: %v\noutput: %s", pkgPath, err, stderr.String())), nil
+	}
+	var pf packageFiles
+	if err := json.Unmarshal(out, &pf); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse go list output: %v", err)), nil
+	}
+	if pf.Dir == "" {
+		return mcp.NewToolResultError(fmt.Sprintf("no source directory found for %s", pkgPath)), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Files of %s in %s\n", pkgPath, pf.Dir)
+	groups := []struct {
+		title string
+		names []string
+	}{
+		{"SOURCE FILES", pf.GoFiles},
+		{"CGO FILES", pf.CgoFiles},
+		{"TEST FILES", append(append([]string{}, pf.TestGoFiles...), pf.XTestGoFiles...)},
+		{"EXCLUDED BY BUILD CONSTRAINTS", pf.IgnoredGoFiles},
+	}
+	total := 0
+	for _, g := range groups {
+		if len(g.names) == 0 {
+			continue
+		}
+		total += len(g.names)
+		fmt.Fprintf(&b, "\n%s\n\n", g.title)
+		for _, name := range g.names {
+			lines, synopsis := fileSynopsis(filepath.Join(pf.Dir, name))
+			line := fmt.Sprintf("%s (%d lines)", name, lines)
+			if synopsis != "" {
+				line += ": " + synopsis
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	if total == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No Go files in %s (%s)", pkgPath, pf.Dir)), nil
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// filePackage is the subset of go list -json output that file_package uses.
+type filePackage struct {
+	ImportPath string
+	Name       string
+	Doc        string
+	Module     *struct{ Path string }
+	Error      *struct{ Err string }
+	packageFiles
+}
+
+func (gs *godocServer) handleFilePackage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	file, err := request.RequireString("file")
+	if err != nil {
+		return mcp.NewToolResultError("file argument is required"), nil
+	}
+	if !filepath.IsAbs(file) {
+		return mcp.NewToolResultError(fmt.Sprintf("file must be an absolute path, got %q", file)), nil
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("cannot read %s: %v", file, err)), nil
+	}
+	if info.IsDir() {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is a directory; use list_files for a package directory", file)), nil
+	}
+	if real, err := filepath.EvalSymlinks(file); err == nil {
+		file = real
+	}
+	dir, name := filepath.Split(file)
+	if err := gs.checkPathsAllowed(dir, ""); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+	cmd := gs.goCommand(listCtx, dir, "list", "-e", "-json=ImportPath,Name,Doc,Module,Error,Dir,GoFiles,CgoFiles,TestGoFiles,XTestGoFiles,IgnoredGoFiles", ".")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := gs.output(listCtx, cmd)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("go list failed in %s: %v\noutput: %s", dir, err, stderr.String())), nil
+	}
+	var fp filePackage
+	if err := json.Unmarshal(out, &fp); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse go list output: %v", err)), nil
+	}
+	if fp.ImportPath == "" || (fp.Name == "" && fp.Error != nil) {
+		msg := fmt.Sprintf("no package found for %s", file)
+		if fp.Error != nil {
+			msg += ": " + fp.Error.Err
+		}
+		return mcp.NewToolResultError(msg), nil
+	}
+
+	groups := []struct {
+		title string
+		role  string
+		names []string
+	}{
+		{"SOURCE FILES", "source file", fp.GoFiles},
+		{"CGO FILES", "cgo file", fp.CgoFiles},
+		{"TEST FILES", "test file", fp.TestGoFiles},
+		{"EXTERNAL TEST FILES", "external test file (package " + fp.Name + "_test)", fp.XTestGoFiles},
+		{"EXCLUDED BY BUILD CONSTRAINTS", "excluded by build constraints", fp.IgnoredGoFiles},
+	}
+	role := "not a Go file of the package"
+	for _, g := range groups {
+		if slices.Contains(g.names, name) {
+			role = g.role
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "File: %s\n", file)
+	fmt.Fprintf(&b, "Package: %s (package %s)\n", fp.ImportPath, fp.Name)
+	if fp.Module != nil {
+		fmt.Fprintf(&b, "Module: %s\n", fp.Module.Path)
+	}
+	fmt.Fprintf(&b, "Role: %s\n", role)
+	if fp.Doc != "" {
+		fmt.Fprintf(&b, "Synopsis: %s\n", fp.Doc)
+	}
+	for _, g := range groups {
+		if len(g.names) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s\n\n", g.title)
+		for _, n := range g.names {
+			if n == name {
+				n += " (this file)"
+			}
+			b.WriteString(n + "\n")
+		}
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// fileSynopsis returns the number of lines in the Go file at path and a
+// one-line summary of it: the first sentence of its package comment or of
+// the first documented declaration, or else the exported names it declares.
+// Files that cannot be read or parsed get an empty summary.
+func fileSynopsis(path string) (int, string) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return 0, ""
+	}
+	lines := bytes.Count(src, []byte("\n"))
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		lines++
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), path, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return lines, ""
+	}
+	var dp doc.Package
+	if f.Doc != nil {
+		return lines, dp.Synopsis(f.Doc.Text())
+	}
+
+	var names []string
+	for _, decl := range f.Decls {
+		var docGroup *ast.CommentGroup
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			docGroup = d.Doc
+			switch {
+			case !d.Name.IsExported():
+			case d.Recv == nil:
+				names = append(names, d.Name.Name)
+			default:
+				names = append(names, embeddedName(d.Recv.List[0].Type)+"."+d.Name.Name)
+			}
+		case *ast.GenDecl:
+			docGroup = d.Doc
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if docGroup == nil {
+						docGroup = s.Doc
+					}
+					if s.Name.IsExported() {
+						names = append(names, s.Name.Name)
+					}
+				case *ast.ValueSpec:
+					if docGroup == nil {
+						docGroup = s.Doc
+					}
+					for _, id := range s.Names {
+						if id.IsExported() {
+							names = append(names, id.Name)
+						}
+					}
+				}
+			}
+		}
+		if docGroup != nil {
+			if s := dp.Synopsis(docGroup.Text()); s != "" {
+				return lines, s
+			}
+		}
+	}
+
+	switch {
+	case len(names) == 0:
+		return lines, ""
+	case len(names) > maxFileNames:
+		return lines, fmt.Sprintf("declares %s and %d more", strings.Join(names[:maxFileNames], ", "), len(names)-maxFileNames)
+	default:
+		return lines, "declares " + strings.Join(names, ", ")
+	}
+}
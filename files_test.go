@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleListFiles(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/kv\n\ngo 1.21\n",
+		"doc.go": `// Package kv stores values. It is not a database.
+package kv
+`,
+		"store.go": `package kv
+
+import "sync"
+
+var mu sync.Mutex
+
+// Store holds values in memory.
+type Store struct{}
+`,
+		"util.go": `package kv
+
+func Keys() []string { return nil }
+
+func (s *Store) Len() int { return 0 }
+`,
+		"store_test.go": `package kv
+
+import "testing"
+
+func TestStore(t *testing.T) {}
+`,
+		"store_windows.go": "package kv\n\nfunc lock() {}\n",
+	})
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "list_files"
+	req.Params.Arguments = map[string]any{"path": ".", "working_dir": dir}
+	result, err := gs.handleListFiles(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleListFiles returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleListFiles returned tool error: %+v", result.Content)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"Files of example.com/kv in " + dir,
+		"SOURCE FILES\n\ndoc.go (2 lines): Package kv stores values.\nstore.go (8 lines): Store holds values in memory.\nutil.go (5 lines): declares Keys, Store.Len\n",
+		"TEST FILES\n\nstore_test.go (5 lines): declares TestStore\n",
+		"EXCLUDED BY BUILD CONSTRAINTS\n\nstore_windows.go (3 lines)\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestHandleFilePackage(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":             "module example.com/app\n\ngo 1.21\n",
+		"kv/doc.go":          "// Package kv stores values. It is not a database.\npackage kv\n",
+		"kv/store.go":        "package kv\n\n// Get gets.\nfunc Get() {}\n",
+		"kv/store_test.go":   "package kv\n",
+		"kv/example_test.go": "package kv_test\n",
+		"kv/gen.go":          "//go:build ignore\n\npackage main\n",
+		"kv/README":          "notes\n",
+	})
+	kv := filepath.Join(dir, "kv")
+
+	gs := newGodocServer()
+	call := func(file string) (string, bool) {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "file_package"
+		req.Params.Arguments = map[string]any{"file": file}
+		result, err := gs.handleFilePackage(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleFilePackage returned protocol error: %v", err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	text, isErr := call(filepath.Join(kv, "store.go"))
+	if isErr {
+		t.Fatalf("unexpected tool error: %s", text)
+	}
+	for _, want := range []string{
+		"Package: example.com/app/kv (package kv)\n",
+		"Module: example.com/app\n",
+		"Role: source file\n",
+		"Synopsis: Package kv stores values.\n",
+		"SOURCE FILES\n\ndoc.go\nstore.go (this file)\n",
+		"TEST FILES\n\nstore_test.go\n",
+		"EXTERNAL TEST FILES\n\nexample_test.go\n",
+		"EXCLUDED BY BUILD CONSTRAINTS\n\ngen.go\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	roles := map[string]string{
+		"example_test.go": "Role: external test file (package kv_test)",
+		"gen.go":          "Role: excluded by build constraints",
+		"README":          "Role: not a Go file of the package",
+	}
+	for name, want := range roles {
+		if text, isErr := call(filepath.Join(kv, name)); isErr || !strings.Contains(text, want) {
+			t.Errorf("%s: want %q, got:\n%s", name, want, text)
+		}
+	}
+
+	for file, want := range map[string]string{
+		"kv/store.go":                "must be an absolute path",
+		kv:                           "is a directory",
+		filepath.Join(kv, "nope.go"): "cannot read",
+	} {
+		if text, isErr := call(file); !isErr || !strings.Contains(text, want) {
+			t.Errorf("%s: want error containing %q, got %q", file, want, text)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// declPrefixes are the line prefixes that start a declaration in go doc output.
+var declPrefixes = []string{"func ", "type ", "var ", "const "}
+
+// normalizeDoc rewrites go doc output so that declarations and code blocks
+// are consistently indented by four spaces and doc prose is flush left,
+// regardless of whether the output came from a package or symbol query.
+func normalizeDoc(doc string) string {
+	lines := strings.Split(doc, "\n")
+	out := make([]string, 0, len(lines))
+
+	depth := 0
+	inSymbolDoc := false
+	for _, line := range lines {
+		switch {
+		case depth > 0:
+			// Continuation of a multi-line declaration.
+			out = append(out, "    "+line)
+			depth = max(depth+bracketDelta(line), 0)
+
+		case line == "":
+			out = append(out, line)
+
+		case isDeclStart(line):
+			out = append(out, "    "+line)
+			depth = max(bracketDelta(line), 0)
+			inSymbolDoc = true
+
+		case inSymbolDoc && strings.HasPrefix(line, "    "):
+			// Symbol prose is indented one level; code within it two.
+			out = append(out, line[4:])
+
+		case strings.HasPrefix(line, "\t"):
+			out = append(out, "    "+line[1:])
+
+		default:
+			// Flush-left text is package prose or a section heading.
+			if !strings.HasPrefix(line, " ") {
+				inSymbolDoc = false
+			}
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// isDeclStart reports whether line begins a declaration or a source comment
+// at the top level of go doc output.
+func isDeclStart(line string) bool {
+	if strings.HasPrefix(line, "//") {
+		return true
+	}
+	for _, p := range declPrefixes {
+		if strings.HasPrefix(line, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// bracketDelta returns the net change in bracket nesting across line,
+// ignoring brackets inside string literals and line comments.
+func bracketDelta(line string) int {
+	delta := 0
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if quote != 0 {
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case '/':
+			if i+1 < len(line) && line[i+1] == '/' {
+				return delta
+			}
+		case '(', '{', '[':
+			delta++
+		case ')', '}', ']':
+			delta--
+		}
+	}
+	return delta
+}
+
+// dedupeMethodDocs drops method entries that appear more than once, verbatim,
+// in go doc output. An entry runs from its "func (" line up to the next
+// top-level declaration.
+func dedupeMethodDocs(doc string) string {
+	lines := strings.Split(doc, "\n")
+	var chunks [][]string
+	for _, line := range lines {
+		if len(chunks) == 0 || isDeclStart(line) {
+			chunks = append(chunks, nil)
+		}
+		chunks[len(chunks)-1] = append(chunks[len(chunks)-1], line)
+	}
+
+	seen := make(map[string]bool)
+	out := make([]string, 0, len(lines))
+	for _, chunk := range chunks {
+		if strings.HasPrefix(chunk[0], "func (") {
+			key := strings.TrimRight(strings.Join(chunk, "\n"), "\n")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		out = append(out, chunk...)
+	}
+	return strings.Join(out, "\n")
+}
+
+// Function signatures with at least wrapMinParams parameters (type
+// parameters included), or whose line is longer than wrapMaxLine, are
+// written one parameter per line by wrapSignatures.
+const (
+	wrapMinParams = 5
+	wrapMaxLine   = 100
+)
+
+// wrapSignatures rewrites the long func lines of go doc output with each
+// parameter and type parameter on its own line, gofmt style. Parameters that
+// share a type stay together, as they were declared. Lines that do not parse
+// as a signature are left alone.
+func wrapSignatures(doc string) string {
+	lines := strings.Split(doc, "\n")
+	for i, line := range lines {
+		if wrapped, ok := wrapSignature(line); ok {
+			lines[i] = wrapped
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapSignature returns line with its parameter lists wrapped, and whether
+// line is a function signature long enough to wrap with more than one
+// parameter declaration to split.
+func wrapSignature(line string) (string, bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(trimmed)]
+	if !strings.HasPrefix(trimmed, "func ") {
+		return line, false
+	}
+	src := "package p\n" + trimmed
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.SkipObjectResolution)
+	if err != nil || len(f.Decls) != 1 {
+		return line, false
+	}
+	fd, ok := f.Decls[0].(*ast.FuncDecl)
+	if !ok || fd.Body != nil {
+		return line, false
+	}
+	params, typeParams := fd.Type.Params, fd.Type.TypeParams
+	if params.NumFields()+typeParams.NumFields() < wrapMinParams && len(line) <= wrapMaxLine {
+		return line, false
+	}
+	if len(params.List) <= 1 && (typeParams == nil || len(typeParams.List) <= 1) {
+		return line, false
+	}
+
+	// Types are copied from the line so they read as go doc printed them.
+	file := fset.File(f.Pos())
+	text := func(n ast.Node) string {
+		return src[file.Offset(n.Pos()):file.Offset(n.End())]
+	}
+	var b strings.Builder
+	b.WriteString(indent + trimmed[:file.Offset(fd.Name.End())-len("package p\n")])
+	if typeParams != nil {
+		b.WriteString(wrapFields(typeParams, text, "[", "]", indent))
+	}
+	b.WriteString(wrapFields(params, text, "(", ")", indent))
+	if fd.Type.Results != nil {
+		b.WriteString(" " + text(fd.Type.Results))
+	}
+	return b.String(), true
+}
+
+// wrapFields writes fl between the brackets open and end, one field per
+// line when it has more than one, indented a tab deeper than indent. text
+// returns the source of a node.
+func wrapFields(fl *ast.FieldList, text func(ast.Node) string, open, end, indent string) string {
+	fields := make([]string, len(fl.List))
+	for i, field := range fl.List {
+		fields[i] = text(field)
+	}
+	if len(fields) <= 1 {
+		return open + strings.Join(fields, "") + end
+	}
+	return open + "\n" + indent + "\t" + strings.Join(fields, ",\n"+indent+"\t") + ",\n" + indent + end
+}
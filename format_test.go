@@ -0,0 +1,169 @@
+package main
+
+import "testing"
+
+func TestNormalizeDoc(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "symbol doc",
+			in: "package io // import \"io\"\n\n" +
+				"type Reader interface {\n" +
+				"\tRead(p []byte) (n int, err error)\n" +
+				"}\n" +
+				"    Reader wraps Read.\n" +
+				"\n" +
+				"        r.Read(buf)\n",
+			want: "package io // import \"io\"\n\n" +
+				"    type Reader interface {\n" +
+				"    \tRead(p []byte) (n int, err error)\n" +
+				"    }\n" +
+				"Reader wraps Read.\n" +
+				"\n" +
+				"    r.Read(buf)\n",
+		},
+		{
+			name: "package doc keeps code block",
+			in: "Package demo does things.\n\n" +
+				"    demo.Run()\n\n" +
+				"func Run()\n",
+			want: "Package demo does things.\n\n" +
+				"    demo.Run()\n\n" +
+				"    func Run()\n",
+		},
+		{
+			name: "section heading ends symbol context",
+			in: "func A()\n" +
+				"    A does a.\n" +
+				"\n" +
+				"TYPES\n" +
+				"\n" +
+				"    indented package text\n",
+			want: "    func A()\n" +
+				"A does a.\n" +
+				"\n" +
+				"TYPES\n" +
+				"\n" +
+				"    indented package text\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeDoc(tt.in); got != tt.want {
+				t.Errorf("normalizeDoc() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBracketDelta(t *testing.T) {
+	tests := []struct {
+		line string
+		want int
+	}{
+		{"type T struct {", 1},
+		{"}", -1},
+		{"func F(a int) (int, error)", 0},
+		{`const s = "{("`, 0},
+		{"var r = '{'", 0},
+		{"x := f( // (", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := bracketDelta(tt.line); got != tt.want {
+				t.Errorf("bracketDelta(%q) = %d, want %d", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupeMethodDocs(t *testing.T) {
+	in := "type T struct{ Base }\n\n" +
+		"func (b Base) Close() error\n" +
+		"    Close releases resources.\n\n" +
+		"func (t T) Run()\n" +
+		"    Run runs.\n\n" +
+		"func (b Base) Close() error\n" +
+		"    Close releases resources.\n\n" +
+		"func (b Base) Close() error\n" +
+		"    Close does something else.\n"
+	want := "type T struct{ Base }\n\n" +
+		"func (b Base) Close() error\n" +
+		"    Close releases resources.\n\n" +
+		"func (t T) Run()\n" +
+		"    Run runs.\n\n" +
+		"func (b Base) Close() error\n" +
+		"    Close does something else.\n"
+
+	if got := dedupeMethodDocs(in); got != want {
+		t.Errorf("dedupeMethodDocs() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWrapSignatures(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "short signature unchanged",
+			in:   "func Copy(dst Writer, src Reader) (written int64, err error)\n",
+			want: "func Copy(dst Writer, src Reader) (written int64, err error)\n",
+		},
+		{
+			name: "many parameters",
+			in: "func Dial(network, address string, timeout time.Duration, tls *tls.Config, retries int) (Conn, error)\n" +
+				"    Dial connects.\n",
+			want: "func Dial(\n" +
+				"\tnetwork, address string,\n" +
+				"\ttimeout time.Duration,\n" +
+				"\ttls *tls.Config,\n" +
+				"\tretries int,\n" +
+				") (Conn, error)\n" +
+				"    Dial connects.\n",
+		},
+		{
+			name: "generic constraints",
+			in:   "    func Merge[M ~map[K]V, K comparable, V interface{ Merge(V) V }](dst, src M) M\n",
+			want: "    func Merge[\n" +
+				"    \tM ~map[K]V,\n" +
+				"    \tK comparable,\n" +
+				"    \tV interface{ Merge(V) V },\n" +
+				"    ](dst, src M) M\n",
+		},
+		{
+			name: "method",
+			in:   "func (c *Client) Do(ctx context.Context, method, url string, body io.Reader, h Header) (*Response, error)\n",
+			want: "func (c *Client) Do(\n" +
+				"\tctx context.Context,\n" +
+				"\tmethod, url string,\n" +
+				"\tbody io.Reader,\n" +
+				"\th Header,\n" +
+				") (*Response, error)\n",
+		},
+		{
+			name: "long line with one parameter declaration",
+			in:   "func Sum[T interface{ ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64 }](values ...T) T\n",
+			want: "func Sum[T interface{ ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64 }](values ...T) T\n",
+		},
+		{
+			name: "not a signature",
+			in:   "    func is a keyword, and this sentence mentions it with many words, commas, and more words than fit.\n",
+			want: "    func is a keyword, and this sentence mentions it with many words, commas, and more words than fit.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapSignatures(tt.in); got != tt.want {
+				t.Errorf("wrapSignatures() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
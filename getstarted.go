@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const getStartedDescription = `Start learning a Go package. Returns the package overview followed by a
+reading roadmap: an ordered list of the exported symbols to look at first,
+each with its signature and one-line summary. Constructors (New...) come
+first, then the package's primary types (ranked by how many constructors and
+methods they have and whether the overview mentions them), then the methods
+of those types, then everything else. Deprecated symbols are left out. Use
+this instead of an alphabetical symbol dump when exploring an unfamiliar
+package, and fetch details for individual steps with get_doc.`
+
+// defaultRoadmapLimit is the number of roadmap steps get_started shows when
+// the request does not say.
+const defaultRoadmapLimit = 20
+
+// roadmapStep is one symbol in a get_started roadmap and why it is there.
+type roadmapStep struct {
+	sym    docSymbol
+	reason string
+}
+
+func (gs *godocServer) handleGetStarted(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	limit := request.GetInt("limit", defaultRoadmapLimit)
+	if limit < 1 {
+		limit = defaultRoadmapLimit
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s // import %q\n\n", dp.Name, pkgPath)
+	if dp.Doc != "" {
+		b.WriteString(dp.Doc + "\n")
+	}
+
+	steps := symbolRoadmap(dp)
+	if len(steps) == 0 {
+		b.WriteString("ROADMAP\n\nThe package has no exported symbols.\n")
+		return mcp.NewToolResultText(b.String()), nil
+	}
+	b.WriteString("ROADMAP\n\n")
+	for i, step := range steps {
+		if i == limit {
+			fmt.Fprintf(&b, "\n... and %d more; use list_symbols for the full list.\n", len(steps)-limit)
+			break
+		}
+		fmt.Fprintf(&b, "%2d. [%s] %s\n", i+1, step.reason, symbolSignature(lp.fset, step.sym))
+		if summary := dp.Synopsis(step.sym.doc); summary != "" {
+			fmt.Fprintf(&b, "    %s\n", summary)
+		}
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// symbolRoadmap orders the exported, non-deprecated symbols of dp into a
+// learning path: constructors, primary types, methods of primary types, and
+// then the remaining functions, types, constants, and variables. A type is
+// primary if it has a constructor or exported method, or the package
+// overview names it; primary types are ranked by those signals.
+func symbolRoadmap(dp *doc.Package) []roadmapStep {
+	score := make(map[string]int)
+	var types []*doc.Type
+	for _, t := range dp.Types {
+		if !ast.IsExported(t.Name) {
+			continue
+		}
+		types = append(types, t)
+		for _, f := range t.Funcs {
+			if isConstructor(f.Name) {
+				score[t.Name] += 3
+			}
+		}
+		for _, m := range t.Methods {
+			if ast.IsExported(m.Name) {
+				score[t.Name]++
+			}
+		}
+		if regexp.MustCompile(`\b` + t.Name + `\b`).MatchString(dp.Doc) {
+			score[t.Name] += 2
+		}
+	}
+	sort.SliceStable(types, func(i, j int) bool { return score[types[i].Name] > score[types[j].Name] })
+
+	var steps []roadmapStep
+	add := func(sym docSymbol, reason string) {
+		if !ast.IsExported(lastName(sym.name)) {
+			return
+		}
+		if _, deprecated := deprecationNotice(sym.doc); deprecated {
+			return
+		}
+		steps = append(steps, roadmapStep{sym: sym, reason: reason})
+	}
+	funcSym := func(f *doc.Func) docSymbol {
+		return docSymbol{name: f.Name, kind: "func", doc: f.Doc, decl: f.Decl}
+	}
+	typeSym := func(t *doc.Type) docSymbol {
+		return docSymbol{name: t.Name, kind: "type", doc: t.Doc, decl: t.Decl}
+	}
+
+	for _, t := range types {
+		for _, f := range t.Funcs {
+			if isConstructor(f.Name) {
+				add(funcSym(f), "constructor for "+t.Name)
+			}
+		}
+	}
+	for _, f := range dp.Funcs {
+		if isConstructor(f.Name) {
+			add(funcSym(f), "constructor")
+		}
+	}
+	for _, t := range types {
+		if score[t.Name] > 0 {
+			add(typeSym(t), "primary type")
+		}
+	}
+	for _, t := range types {
+		if score[t.Name] == 0 {
+			continue
+		}
+		for _, m := range t.Methods {
+			sym := funcSym(m)
+			sym.name, sym.kind = t.Name+"."+m.Name, "method"
+			add(sym, "method of "+t.Name)
+		}
+	}
+
+	for _, f := range dp.Funcs {
+		if !isConstructor(f.Name) {
+			add(funcSym(f), "function")
+		}
+	}
+	for _, t := range types {
+		for _, f := range t.Funcs {
+			if !isConstructor(f.Name) {
+				add(funcSym(f), "returns "+t.Name)
+			}
+		}
+		if score[t.Name] == 0 {
+			add(typeSym(t), "type")
+		}
+	}
+	for _, sym := range packageSymbols(dp) {
+		if sym.kind == "const" || sym.kind == "var" {
+			add(sym, sym.kind)
+		}
+	}
+	return steps
+}
+
+// isConstructor reports whether a function name follows the New... naming
+// convention for constructors.
+func isConstructor(name string) bool {
+	rest, ok := strings.CutPrefix(name, "New")
+	return ok && (rest == "" || ast.IsExported(rest))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestIsConstructor(t *testing.T) {
+	for name, want := range map[string]bool{
+		"New":         true,
+		"NewClient":   true,
+		"Newton":      false,
+		"MustNew":     false,
+		"NewFromFile": true,
+	} {
+		if got := isConstructor(name); got != want {
+			t.Errorf("isConstructor(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestHandleGetStarted(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/kv\n\ngo 1.21\n",
+		"kv.go": `// Package kv is a key-value store. Open a Store to begin.
+package kv
+
+// MaxKeys limits a Store.
+const MaxKeys = 100
+
+// Options configure a Store.
+type Options struct{ ReadOnly bool }
+
+// Store holds values.
+type Store struct{}
+
+// NewStore returns an empty Store.
+func NewStore(o Options) *Store { return &Store{} }
+
+// Get returns the value for key.
+func (s *Store) Get(key string) string { return "" }
+
+// Put sets key.
+func (s *Store) Put(key, value string) {}
+
+// Legacy does nothing.
+//
+// Deprecated: use Put.
+func (s *Store) Legacy() {}
+
+// Version reports the library version.
+func Version() string { return "1" }
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_started"
+		req.Params.Arguments = args
+		result, err := gs.handleGetStarted(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetStarted returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleGetStarted returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := call(map[string]any{"path": ".", "working_dir": dir})
+	order := []string{
+		"Package kv is a key-value store.",
+		" 1. [constructor for Store] func NewStore(o Options) *Store\n    NewStore returns an empty Store.",
+		" 2. [primary type] type Store struct{}",
+		" 3. [method of Store] func (s *Store) Get(key string) string",
+		" 4. [method of Store] func (s *Store) Put(key, value string)",
+		" 5. [function] func Version() string",
+		" 6. [type] type Options struct{ ReadOnly bool }",
+		" 7. [const] const MaxKeys = 100",
+	}
+	last := -1
+	for _, want := range order {
+		i := strings.Index(text, want)
+		if i < 0 {
+			t.Fatalf("missing %q in:\n%s", want, text)
+		}
+		if i < last {
+			t.Errorf("%q out of order in:\n%s", want, text)
+		}
+		last = i
+	}
+	if strings.Contains(text, "Legacy") {
+		t.Errorf("deprecated method included:\n%s", text)
+	}
+
+	text = call(map[string]any{"path": ".", "working_dir": dir, "limit": 2})
+	if !strings.Contains(text, "... and 5 more; use list_symbols") || strings.Contains(text, " 3. ") {
+		t.Errorf("limit not applied:\n%s", text)
+	}
+}
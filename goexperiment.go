@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// goExperimentKey carries the GOEXPERIMENT setting for a request's go
+// commands in a context.
+type goExperimentKey struct{}
+
+// withGoExperiment returns a context whose go commands run with
+// GOEXPERIMENT=exp and whose packages are parsed with the matching
+// goexperiment.* build tags.
+func withGoExperiment(ctx context.Context, exp string) context.Context {
+	return context.WithValue(ctx, goExperimentKey{}, exp)
+}
+
+// goExperiment returns the GOEXPERIMENT set by withGoExperiment, or "".
+func goExperiment(ctx context.Context) string {
+	exp, _ := ctx.Value(goExperimentKey{}).(string)
+	return exp
+}
+
+// knownExperiments returns the experiment names the installed toolchain
+// accepts, read from the fields of internal/goexperiment.Flags, or nil if
+// its source is unavailable.
+var knownExperiments = sync.OnceValue(func() []string {
+	file := filepath.Join(build.Default.GOROOT, "src", "internal", "goexperiment", "flags.go")
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	ts, _ := findTypeSpec([]*ast.File{f}, "Flags")
+	if ts == nil {
+		return nil
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, field := range st.Fields.List {
+		for _, id := range field.Names {
+			names = append(names, strings.ToLower(id.Name))
+		}
+	}
+	slices.Sort(names)
+	return names
+})
+
+// validateGoExperiment checks that every entry of a GOEXPERIMENT value names
+// an experiment of the installed toolchain, optionally negated with a "no"
+// prefix. Without the toolchain's list, any value is accepted and left to
+// the go command to reject.
+func validateGoExperiment(exp string) error {
+	known := knownExperiments()
+	if known == nil {
+		return nil
+	}
+	for _, item := range strings.Split(exp, ",") {
+		name := strings.ToLower(strings.TrimSpace(item))
+		if name == "" || name == "none" {
+			continue
+		}
+		if slices.Contains(known, name) {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(name, "no"); ok && slices.Contains(known, rest) {
+			continue
+		}
+		return fmt.Errorf("unknown GOEXPERIMENT %q (known to this toolchain: %s)", item, strings.Join(known, ", "))
+	}
+	return nil
+}
+
+// experimentContext returns a copy of the default build context with the
+// goexperiment.* tags enabled or disabled as exp says, so that parsed source
+// matches what go doc sees under GOEXPERIMENT=exp.
+func experimentContext(exp string) *build.Context {
+	ctxt := build.Default
+	tags := slices.Clone(ctxt.ToolTags)
+	for _, item := range strings.Split(exp, ",") {
+		name := strings.ToLower(strings.TrimSpace(item))
+		switch {
+		case name == "" || name == "none":
+		case strings.HasPrefix(name, "no") && slices.Contains(knownExperiments(), strings.TrimPrefix(name, "no")):
+			tags = slices.DeleteFunc(tags, func(t string) bool { return t == "goexperiment."+strings.TrimPrefix(name, "no") })
+		case !slices.Contains(tags, "goexperiment."+name):
+			tags = append(tags, "goexperiment."+name)
+		}
+	}
+	ctxt.ToolTags = tags
+	return &ctxt
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestValidateGoExperiment(t *testing.T) {
+	if !slices.Contains(knownExperiments(), "arenas") {
+		t.Skip("toolchain source does not list the arenas experiment")
+	}
+	for _, exp := range []string{"arenas", "Arenas", "arenas,noloopvar", "none"} {
+		if err := validateGoExperiment(exp); err != nil {
+			t.Errorf("validateGoExperiment(%q) = %v, want nil", exp, err)
+		}
+	}
+	for _, exp := range []string{"bogus", "arenas,nobogus"} {
+		err := validateGoExperiment(exp)
+		if err == nil || !strings.Contains(err.Error(), "known to this toolchain: ") {
+			t.Errorf("validateGoExperiment(%q) = %v, want an error listing known experiments", exp, err)
+		}
+	}
+}
+
+func TestExperimentContext(t *testing.T) {
+	ctxt := experimentContext("arenas")
+	if !slices.Contains(ctxt.ToolTags, "goexperiment.arenas") {
+		t.Errorf("ToolTags = %v, want goexperiment.arenas", ctxt.ToolTags)
+	}
+	if ctxt = experimentContext("noarenas"); slices.Contains(ctxt.ToolTags, "goexperiment.arenas") {
+		t.Errorf("ToolTags = %v, want goexperiment.arenas removed", ctxt.ToolTags)
+	}
+}
+
+func TestHandleGetDocGoExperiment(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+	if !stdPackageExists("arena") {
+		t.Skip("toolchain has no arena package")
+	}
+
+	gs := newGodocServer()
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		return result
+	}
+
+	if result := call(map[string]any{"path": "arena", "target": "NewArena"}); !result.IsError {
+		t.Errorf("arena documented without the experiment:\n%s", result.Content[0].(mcp.TextContent).Text)
+	}
+
+	for _, args := range []map[string]any{
+		{"path": "arena", "target": "NewArena", "goexperiment": "arenas"},
+		{"path": "arena", "target": "NewArena", "goexperiment": "arenas", "signature_only": true},
+	} {
+		result := call(args)
+		text := result.Content[0].(mcp.TextContent).Text
+		if result.IsError || !strings.Contains(text, "func NewArena() *Arena") {
+			t.Errorf("get_doc %v:\n%s", args, text)
+		}
+	}
+
+	result := call(map[string]any{"path": "arena", "goexperiment": "bogus"})
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "unknown GOEXPERIMENT") {
+		t.Errorf("expected an unknown experiment error, got %+v", result.Content)
+	}
+}
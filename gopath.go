@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"go/build"
+	"path/filepath"
+	"strings"
+)
+
+// gopathImportPath returns the import path of dir in a legacy GOPATH
+// workspace: the path of dir relative to the src directory of the GOPATH
+// entry containing it. It fails if dir is inside a module or outside every
+// GOPATH src tree.
+func gopathImportPath(dir string) (string, error) {
+	dir = filepath.Clean(dir)
+	if _, err := findModuleRoot(dir); err == nil {
+		return "", fmt.Errorf("%s is inside a module", dir)
+	}
+	for _, root := range filepath.SplitList(build.Default.GOPATH) {
+		if root == "" {
+			continue
+		}
+		src := filepath.Join(root, "src")
+		if real, err := filepath.EvalSymlinks(src); err == nil {
+			src = real
+		}
+		rel, err := filepath.Rel(src, dir)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return filepath.ToSlash(rel), nil
+	}
+	return "", fmt.Errorf("no go.mod found in %s or any parent directory, and it is not under a GOPATH src directory", dir)
+}
+
+// gopathMode reports whether go commands run in dir must use GOPATH mode:
+// dir has no go.mod at or above it and lies in a GOPATH src tree.
+func gopathMode(dir string) bool {
+	if dir == "" {
+		return false
+	}
+	_, err := gopathImportPath(dir)
+	return err == nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"go/build"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// setGOPATH points both this process and the go commands it runs at a
+// temporary GOPATH workspace for the duration of the test.
+func setGOPATH(t *testing.T, dir string) {
+	t.Helper()
+	old := build.Default.GOPATH
+	build.Default.GOPATH = dir
+	t.Cleanup(func() { build.Default.GOPATH = old })
+	t.Setenv("GOPATH", dir)
+}
+
+func TestGopathImportPath(t *testing.T) {
+	gopath := writeModule(t, map[string]string{
+		"src/example.com/legacy/util/util.go": "package util\n",
+	})
+	if _, err := findModuleRoot(gopath); err == nil {
+		t.Skip("a go.mod exists above the temporary directory")
+	}
+	setGOPATH(t, gopath)
+
+	got, err := gopathImportPath(filepath.Join(gopath, "src", "example.com", "legacy", "util"))
+	if err != nil || got != "example.com/legacy/util" {
+		t.Errorf("gopathImportPath = %q, %v; want example.com/legacy/util", got, err)
+	}
+	if _, err := gopathImportPath(filepath.Join(gopath, "src")); err == nil {
+		t.Error("expected error for the GOPATH src directory itself")
+	}
+	if _, err := gopathImportPath(t.TempDir()); err == nil {
+		t.Error("expected error for a directory outside GOPATH")
+	}
+
+	resolved, _, err := validatePath(".", filepath.Join(gopath, "src", "example.com", "legacy"))
+	if err != nil || resolved != "example.com/legacy" {
+		t.Errorf("validatePath = %q, %v; want example.com/legacy", resolved, err)
+	}
+}
+
+func TestHandleGetDocGOPATH(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+	gopath := writeModule(t, map[string]string{
+		"src/example.com/legacy/util/util.go": `// Package util predates modules.
+package util
+
+// Double returns twice n.
+func Double(n int) int { return 2 * n }
+`,
+	})
+	if _, err := findModuleRoot(gopath); err == nil {
+		t.Skip("a go.mod exists above the temporary directory")
+	}
+	setGOPATH(t, gopath)
+	dir := filepath.Join(gopath, "src", "example.com", "legacy", "util")
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_doc"
+	req.Params.Arguments = map[string]any{"path": ".", "target": "Double", "working_dir": dir}
+	result, err := gs.handleGetDoc(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleGetDoc returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "func Double(n int) int") || !strings.Contains(text, "Double returns twice n.") {
+		t.Errorf("unexpected documentation:\n%s", text)
+	}
+}
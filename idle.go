@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// idleTimer calls a function once no http request has been in flight for a
+// given duration. Requests in progress, including open SSE streams, hold it
+// off; the countdown restarts when the last one finishes.
+type idleTimer struct {
+	timeout time.Duration
+	onIdle  func()
+
+	mu     sync.Mutex
+	active int         // requests in flight
+	gen    int         // bumped on every reset, so stale timers do nothing
+	timer  *time.Timer // nil while requests are in flight
+	fired  bool
+}
+
+// newIdleTimer starts a timer that calls onIdle, at most once, after timeout
+// passes with no requests handled by its handler.
+func newIdleTimer(timeout time.Duration, onIdle func()) *idleTimer {
+	it := &idleTimer{timeout: timeout, onIdle: onIdle}
+	it.mu.Lock()
+	it.resetLocked()
+	it.mu.Unlock()
+	return it
+}
+
+// handler wraps next so that each request it serves resets the timer.
+func (it *idleTimer) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		it.mu.Lock()
+		it.active++
+		it.gen++
+		if it.timer != nil {
+			it.timer.Stop()
+			it.timer = nil
+		}
+		it.mu.Unlock()
+
+		defer func() {
+			it.mu.Lock()
+			it.active--
+			if it.active == 0 {
+				it.resetLocked()
+			}
+			it.mu.Unlock()
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// resetLocked restarts the countdown. it.mu must be held.
+func (it *idleTimer) resetLocked() {
+	if it.fired {
+		return
+	}
+	it.gen++
+	gen := it.gen
+	// A timer that already fired may be waiting on the lock; the generation
+	// check below makes it a no-op.
+	it.timer = time.AfterFunc(it.timeout, func() {
+		it.mu.Lock()
+		if gen != it.gen || it.active > 0 || it.fired {
+			it.mu.Unlock()
+			return
+		}
+		it.fired = true
+		it.timer = nil
+		it.mu.Unlock()
+		it.onIdle()
+	})
+}
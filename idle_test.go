@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIdleTimerFiresWhenIdle(t *testing.T) {
+	fired := make(chan struct{}, 2)
+	newIdleTimer(20*time.Millisecond, func() { fired <- struct{}{} })
+
+	select {
+	case <-fired:
+	case <-time.After(2 * time.Second):
+		t.Fatal("idle timer did not fire")
+	}
+	select {
+	case <-fired:
+		t.Fatal("idle timer fired twice")
+	case <-time.After(60 * time.Millisecond):
+	}
+}
+
+func TestIdleTimerResetByRequests(t *testing.T) {
+	var fired atomic.Bool
+	it := newIdleTimer(80*time.Millisecond, func() { fired.Store(true) })
+	h := it.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// Requests arriving more often than the timeout keep the server alive.
+	for i := 0; i < 5; i++ {
+		time.Sleep(30 * time.Millisecond)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/mcp", nil))
+	}
+	if fired.Load() {
+		t.Fatal("idle timer fired despite regular requests")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if !fired.Load() {
+		t.Fatal("idle timer did not fire after requests stopped")
+	}
+}
+
+func TestIdleTimerHeldOffByInFlightRequest(t *testing.T) {
+	var fired atomic.Bool
+	it := newIdleTimer(20*time.Millisecond, func() { fired.Store(true) })
+	release := make(chan struct{})
+	h := it.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-release }))
+
+	done := make(chan struct{})
+	go func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/sse", nil))
+		close(done)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if fired.Load() {
+		t.Fatal("idle timer fired while a request was in flight")
+	}
+
+	close(release)
+	<-done
+	time.Sleep(100 * time.Millisecond)
+	if !fired.Load() {
+		t.Fatal("idle timer did not fire after the request finished")
+	}
+}
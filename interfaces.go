@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const describeInterfaceDescription = `Explain the contract of a Go interface.
+Returns the interface definition, each method with its doc comment (methods
+of embedded interfaces, including ones from other packages such as
+io.Reader, are marked with the interface that declares them), the
+sentences of the documentation that state requirements (must, should, never,
+returns ... error, safe for concurrent use, ...), and the exported types in the
+standard library and the interface's own module that implement it. Use this
+before implementing or consuming an interface.`
+
+// maxImplementers caps how many implementing types describe_interface lists.
+const maxImplementers = 50
+
+// contractPattern matches sentences that state part of an interface contract.
+var contractPattern = regexp.MustCompile(`(?i)\b(must|should|never|always|may not|must not|is not allowed|implementations?|callers?|panics?|blocks?|concurrent(ly)?|safe for|returns? (a )?(non-nil )?(error|nil|EOF|io\.EOF|false|true|zero))\b`)
+
+func (gs *godocServer) handleDescribeInterface(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	target, err := request.RequireString("target")
+	if err != nil {
+		return mcp.NewToolResultError("target argument is required"), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	dt, ts, it := findInterface(dp, target)
+	if it == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("interface %s not found in %s", target, pkgPath)), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s // import %q\n\n", dp.Name, pkgPath)
+	writeTypeDecl(&b, lp.fset, ts, dt.Doc)
+	writeContract(&b, "", dt.Doc)
+
+	b.WriteString("METHODS\n\n")
+	// Interfaces embedded from other packages are only visible through
+	// go/types; the package is type-checked the first time one is found.
+	resolve := func(expr ast.Expr) types.Type {
+		if gs.typeCheck(ctx, lp) != nil {
+			return nil
+		}
+		var id *ast.Ident
+		switch e := expr.(type) {
+		case *ast.Ident:
+			id = e
+		case *ast.SelectorExpr:
+			id = e.Sel
+		}
+		if obj, ok := lp.info.Uses[id].(*types.TypeName); ok && id != nil {
+			if _, isIface := obj.Type().Underlying().(*types.Interface); isIface {
+				return obj.Type()
+			}
+		}
+		return nil
+	}
+	writeInterfaceMethods(&b, lp, dp, it, "", map[string]bool{target: true}, resolve)
+
+	impls, err := gs.findImplementers(ctx, workingDir, pkgPath, target)
+	switch {
+	case err != nil:
+		fmt.Fprintf(&b, "IMPLEMENTATIONS\n\ncould not be determined: %v\n", err)
+	case len(impls) == 0:
+		b.WriteString("IMPLEMENTATIONS\n\nno exported implementations found in the standard library or this module\n")
+	default:
+		fmt.Fprintf(&b, "IMPLEMENTATIONS (standard library and this module)\n\n")
+		for i, impl := range impls {
+			if i == maxImplementers {
+				fmt.Fprintf(&b, "... and %d more\n", len(impls)-maxImplementers)
+				break
+			}
+			b.WriteString(impl + "\n")
+		}
+	}
+
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// findInterface returns the documented type named name in dp along with its
+// spec and interface type, or a nil interface if name is not an interface.
+func findInterface(dp *doc.Package, name string) (*doc.Type, *ast.TypeSpec, *ast.InterfaceType) {
+	for _, t := range dp.Types {
+		if t.Name != name {
+			continue
+		}
+		for _, spec := range t.Decl.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok || ts.Name.Name != name {
+				continue
+			}
+			if it, ok := ts.Type.(*ast.InterfaceType); ok {
+				return t, ts, it
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+// writeInterfaceMethods writes each method of it with its doc comment and
+// contract. Methods of embedded interfaces are included and attributed to
+// the interface that declares them: from the AST, with their documentation,
+// when it is exported from the same package, and otherwise as signatures
+// from the go/types interface resolve returns for the embedded expression.
+// Embedded interfaces resolve cannot find are only named.
+func writeInterfaceMethods(b *strings.Builder, lp *loadedPackage, dp *doc.Package, it *ast.InterfaceType, from string, seen map[string]bool, resolve func(ast.Expr) types.Type) {
+	fset := lp.fset
+	for _, field := range it.Methods.List {
+		if len(field.Names) == 0 {
+			name := nodeString(fset, field.Type)
+			if seen[name] {
+				continue
+			}
+			if id, ok := field.Type.(*ast.Ident); ok {
+				if _, _, embedded := findInterface(dp, id.Name); embedded != nil {
+					seen[name] = true
+					writeInterfaceMethods(b, lp, dp, embedded, id.Name, seen, resolve)
+					continue
+				}
+			}
+			if t := resolve(field.Type); t != nil {
+				seen[name] = true
+				writeEmbeddedMethods(b, t, name, types.RelativeTo(lp.pkg), seen)
+				continue
+			}
+			fmt.Fprintf(b, "embeds %s (see its documentation for its methods)\n\n", name)
+			continue
+		}
+		ft, ok := field.Type.(*ast.FuncType)
+		if !ok {
+			continue
+		}
+
+		sig := field.Names[0].Name + strings.TrimPrefix(nodeString(fset, ft), "func")
+		if from != "" {
+			sig += "  // from " + from
+		}
+		b.WriteString(sig + "\n")
+		methodDoc := field.Doc.Text()
+		for _, line := range strings.Split(strings.TrimRight(methodDoc, "\n"), "\n") {
+			if line != "" {
+				b.WriteString("    " + line)
+			}
+			b.WriteString("\n")
+		}
+		writeContract(b, "    ", methodDoc)
+	}
+}
+
+// writeEmbeddedMethods writes the signatures of the methods of the embedded
+// interface t, named from, attributing each to the interface declaring it.
+// Export data carries no doc comments, so only signatures are shown.
+func writeEmbeddedMethods(b *strings.Builder, t types.Type, from string, qual types.Qualifier, seen map[string]bool) {
+	iface := t.Underlying().(*types.Interface)
+	for i := 0; i < iface.NumExplicitMethods(); i++ {
+		m := iface.ExplicitMethod(i)
+		fmt.Fprintf(b, "%s%s  // from %s\n\n", m.Name(), strings.TrimPrefix(types.TypeString(m.Type(), qual), "func"), from)
+	}
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		e := iface.EmbeddedType(i)
+		name := types.TypeString(e, qual)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if _, isIface := e.Underlying().(*types.Interface); isIface {
+			if _, named := e.(*types.Named); named {
+				writeEmbeddedMethods(b, e, name, qual, seen)
+				continue
+			}
+		}
+		fmt.Fprintf(b, "embeds %s\n\n", name)
+	}
+}
+
+// writeContract lists the sentences of docText that state requirements on
+// implementations or callers, indented by indent.
+func writeContract(b *strings.Builder, indent, docText string) {
+	var contract []string
+	for _, s := range sentences(docText) {
+		if contractPattern.MatchString(s) {
+			contract = append(contract, s)
+		}
+	}
+	if len(contract) == 0 {
+		if indent != "" {
+			b.WriteString("\n")
+		}
+		return
+	}
+	b.WriteString(indent + "Contract:\n")
+	for _, s := range contract {
+		b.WriteString(indent + "  - " + s + "\n")
+	}
+	b.WriteString("\n")
+}
+
+// sentences splits prose into sentences, joining wrapped lines. List items
+// are kept as single entries and indented code blocks are skipped.
+func sentences(text string) []string {
+	var prose []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			// List items stand alone.
+			prose = append(prose, "", trimmed[2:], "")
+		case strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "  "):
+			continue
+		default:
+			prose = append(prose, trimmed)
+		}
+	}
+
+	var out []string
+	for _, para := range strings.Split(strings.Join(prose, "\n"), "\n\n") {
+		words := strings.Fields(para)
+		start := 0
+		for i, w := range words {
+			last := i == len(words)-1
+			if last || (strings.HasSuffix(w, ".") || strings.HasSuffix(w, "?") || strings.HasSuffix(w, "!")) &&
+				!strings.HasSuffix(w, "e.g.") && !strings.HasSuffix(w, "i.e.") && isSentenceStart(words[i+1]) {
+				out = append(out, strings.Join(words[start:i+1], " "))
+				start = i + 1
+			}
+		}
+	}
+	return out
+}
+
+// isSentenceStart reports whether word can begin a new sentence.
+func isSentenceStart(word string) bool {
+	r := word[0]
+	return r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// findImplementers lists the exported types in the standard library and in
+// the module providing importPath that implement the interface name, as
+// "pkg.Type" or "*pkg.Type" when only the pointer type does.
+func (gs *godocServer) findImplementers(ctx context.Context, workingDir, importPath, name string) ([]string, error) {
+	patterns := []string{"std"}
+	if mod := gs.modulePath(ctx, workingDir, importPath); mod != "" {
+		patterns = append(patterns, mod+"/...")
+	}
+	exports, roots, err := gs.listExports(ctx, workingDir, append(patterns, importPath)...)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := exportImporter(token.NewFileSet(), exports)
+	ifacePkg, err := imp.Import(importPath)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := ifacePkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a type in %s", name, importPath)
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an interface", name)
+	}
+	if iface.NumMethods() == 0 {
+		return nil, fmt.Errorf("%s has no methods, so every type implements it", name)
+	}
+
+	var impls []string
+	for _, path := range roots {
+		if isInternalPath(path) && path != importPath {
+			continue
+		}
+		pkg, err := imp.Import(path)
+		if err != nil || pkg.Name() == "main" {
+			continue
+		}
+		for _, n := range pkg.Scope().Names() {
+			tn, ok := pkg.Scope().Lookup(n).(*types.TypeName)
+			if !ok || !tn.Exported() || tn.IsAlias() || tn == obj {
+				continue
+			}
+			if named, ok := tn.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+				continue
+			}
+			if _, isIface := tn.Type().Underlying().(*types.Interface); isIface {
+				continue
+			}
+			switch {
+			case types.Implements(tn.Type(), iface):
+				impls = append(impls, pkg.Path()+"."+tn.Name())
+			case types.Implements(types.NewPointer(tn.Type()), iface):
+				impls = append(impls, "*"+pkg.Path()+"."+tn.Name())
+			}
+		}
+	}
+	slices.SortFunc(impls, func(a, b string) int {
+		return strings.Compare(strings.TrimPrefix(a, "*"), strings.TrimPrefix(b, "*"))
+	})
+	return impls, nil
+}
+
+// isInternalPath reports whether importPath is an internal or vendored
+// package that other modules cannot import.
+func isInternalPath(importPath string) bool {
+	for _, elem := range strings.Split(importPath, "/") {
+		if elem == "internal" || elem == "vendor" {
+			return true
+		}
+	}
+	return strings.HasPrefix(importPath, "cmd/")
+}
+
+// modulePath returns the path of the module providing importPath, or "" for
+// standard library packages.
+func (gs *godocServer) modulePath(ctx context.Context, workingDir, importPath string) string {
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	out, err := gs.output(listCtx, gs.goCommand(listCtx, workingDir, "list", "-e", "-f", "{{with .Module}}{{.Path}}{{end}}", importPath))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// nodeString prints node as Go source.
+func nodeString(fset *token.FileSet, node any) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, node)
+	return buf.String()
+}
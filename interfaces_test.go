@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSentences(t *testing.T) {
+	text := "Get returns the value for key. It returns ErrNotFound if\n" +
+		"the key is missing, e.g. after Delete.\n\n" +
+		"Rules:\n" +
+		"  - keys must be non-empty\n" +
+		"  - values may be nil\n\n" +
+		"\tstore.Get(\"k\")\n"
+	want := []string{
+		"Get returns the value for key.",
+		"It returns ErrNotFound if the key is missing, e.g. after Delete.",
+		"Rules:",
+		"keys must be non-empty",
+		"values may be nil",
+	}
+	if got := sentences(text); !slices.Equal(got, want) {
+		t.Errorf("sentences() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestHandleDescribeInterface(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/kv\n\ngo 1.21\n",
+		"kv.go": `package kv
+
+// Closer releases resources.
+type Closer interface {
+	// Close must be called exactly once.
+	Close() error
+}
+
+// Store is a key-value store. Implementations must be safe for concurrent use.
+type Store interface {
+	Closer
+
+	// Get returns the value for key. It returns a nil slice if the key is missing.
+	Get(key string) []byte
+}
+
+type notAnInterface struct{}
+`,
+		"mem/mem.go": `package mem
+
+// Map is an in-memory store.
+type Map struct{}
+
+func (*Map) Get(key string) []byte { return nil }
+func (*Map) Close() error          { return nil }
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(target string) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "describe_interface"
+		req.Params.Arguments = map[string]any{"path": ".", "target": target, "working_dir": dir}
+		result, err := gs.handleDescribeInterface(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleDescribeInterface returned protocol error: %v", err)
+		}
+		return result
+	}
+
+	result := call("Store")
+	if result.IsError {
+		t.Fatalf("handleDescribeInterface returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"type Store interface {",
+		"Contract:\n  - Implementations must be safe for concurrent use.",
+		"Close() error  // from Closer\n    Close must be called exactly once.",
+		"Get(key string) []byte\n",
+		"      - It returns a nil slice if the key is missing.",
+		"IMPLEMENTATIONS (standard library and this module)\n\n*example.com/kv/mem.Map\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+
+	if result := call("notAnInterface"); !result.IsError {
+		t.Error("expected error for a non-interface type")
+	}
+}
+
+func TestDescribeInterfaceForeignEmbeds(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/stream\n\ngo 1.21\n",
+		"stream.go": `package stream
+
+import (
+	"fmt"
+	"io"
+)
+
+// Stream is a named byte stream.
+type Stream interface {
+	io.ReadWriteCloser
+	fmt.Stringer
+
+	// Flush writes buffered data.
+	Flush() error
+}
+`,
+	})
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "describe_interface"
+	req.Params.Arguments = map[string]any{"path": ".", "target": "Stream", "working_dir": dir}
+	result, err := gs.handleDescribeInterface(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleDescribeInterface returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleDescribeInterface returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"Read(p []byte) (n int, err error)  // from io.Reader\n",
+		"Write(p []byte) (n int, err error)  // from io.Writer\n",
+		"Close() error  // from io.Closer\n",
+		"String() string  // from fmt.Stringer\n",
+		"Flush() error\n    Flush writes buffered data.",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "see its documentation") {
+		t.Errorf("embedded interface left unresolved:\n%s", text)
+	}
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// jsonSchema is the subset of JSON Schema that describe_struct's json_schema
+// format produces.
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Properties           *schemaProperties      `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	Defs                 map[string]*jsonSchema `json:"$defs,omitempty"`
+}
+
+// schemaProperties are the properties of an object schema, kept in field
+// order when encoded.
+type schemaProperties struct {
+	names   []string
+	schemas map[string]*jsonSchema
+}
+
+func (p *schemaProperties) set(name string, s *jsonSchema) {
+	if p.schemas == nil {
+		p.schemas = make(map[string]*jsonSchema)
+	}
+	if _, ok := p.schemas[name]; !ok {
+		p.names = append(p.names, name)
+	}
+	p.schemas[name] = s
+}
+
+func (p *schemaProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range p.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(p.schemas[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// schemaBuilder derives JSON schemas from the struct declarations of one
+// package, following encoding/json's rules for field names, omitted fields,
+// and embedded structs. Named types of the package are described once under
+// $defs and referenced from there.
+type schemaBuilder struct {
+	files []*ast.File
+	root  string // the type being described, referenced as "#"
+	defs  map[string]*jsonSchema
+}
+
+// structSchema returns the JSON schema of the struct type named name, whose
+// declaration is st, in the package made of files.
+func structSchema(files []*ast.File, name, docText string, st *ast.StructType) *jsonSchema {
+	sb := &schemaBuilder{files: files, root: name, defs: make(map[string]*jsonSchema)}
+	s := sb.object(st)
+	s.Title = name
+	s.Description = strings.TrimSpace(docText)
+	if len(sb.defs) > 0 {
+		s.Defs = sb.defs
+	}
+	return s
+}
+
+// object returns the schema of a struct's JSON object.
+func (sb *schemaBuilder) object(st *ast.StructType) *jsonSchema {
+	s := &jsonSchema{Type: "object", Properties: &schemaProperties{}}
+	sb.addFields(s, st, false)
+	return s
+}
+
+// addFields adds the JSON properties of st's fields to s. Fields of an
+// embedded struct without a json name are promoted into s, as encoding/json
+// does; under an embedded pointer they are never required, since a nil
+// pointer omits them.
+func (sb *schemaBuilder) addFields(s *jsonSchema, st *ast.StructType, optional bool) {
+	for _, field := range st.Fields.List {
+		name, omit, quoted, skip := jsonFieldTag(field)
+		if skip {
+			continue
+		}
+		embedded := len(field.Names) == 0
+		if embedded && name == "" {
+			typ, ptr := field.Type, false
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ, ptr = star.X, true
+			}
+			if id, ok := typ.(*ast.Ident); ok {
+				if ts, _ := findTypeSpec(sb.files, id.Name); ts != nil {
+					if inner, ok := ts.Type.(*ast.StructType); ok {
+						sb.addFields(s, inner, optional || ptr)
+						continue
+					}
+				}
+			}
+			if _, ok := typ.(*ast.SelectorExpr); ok {
+				note := "Also has the fields of the embedded " + nodeString(token.NewFileSet(), typ) + "."
+				s.Description = strings.TrimSpace(s.Description + " " + note)
+				continue
+			}
+		}
+
+		names := make([]string, 0, len(field.Names))
+		for _, id := range field.Names {
+			names = append(names, id.Name)
+		}
+		if embedded {
+			names = []string{embeddedName(field.Type)}
+		}
+		for _, fieldName := range names {
+			if !token.IsExported(fieldName) {
+				continue
+			}
+			prop := sb.typeSchema(field.Type)
+			if prop == nil {
+				// Channels, functions, and complex numbers cannot be encoded.
+				continue
+			}
+			if quoted && (prop.Type == "integer" || prop.Type == "number" || prop.Type == "boolean") {
+				prop = &jsonSchema{Type: "string", Description: prop.Type + " encoded as a JSON string"}
+			}
+			if desc := fieldDescription(field); desc != "" {
+				switch {
+				case prop.Ref != "":
+					// The referenced definition is shared; describe the use.
+					prop = &jsonSchema{Ref: prop.Ref, Description: desc}
+				case prop.Description != "":
+					prop.Description = desc + " (" + prop.Description + ")"
+				default:
+					prop.Description = desc
+				}
+			}
+			key := name
+			if key == "" {
+				key = fieldName
+			}
+			s.Properties.set(key, prop)
+			if !omit && !optional && !slices.Contains(s.Required, key) {
+				s.Required = append(s.Required, key)
+			}
+		}
+	}
+}
+
+// typeSchema returns the schema of values of the type expr, or nil if
+// encoding/json cannot encode them.
+func (sb *schemaBuilder) typeSchema(expr ast.Expr) *jsonSchema {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "bool":
+			return &jsonSchema{Type: "boolean"}
+		case "string":
+			return &jsonSchema{Type: "string"}
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+			return &jsonSchema{Type: "integer"}
+		case "float32", "float64":
+			return &jsonSchema{Type: "number"}
+		case "complex64", "complex128":
+			return nil
+		case "any", "error":
+			return &jsonSchema{}
+		}
+		return sb.namedSchema(t.Name)
+	case *ast.StarExpr:
+		return sb.typeSchema(t.X)
+	case *ast.ArrayType:
+		if elem, ok := t.Elt.(*ast.Ident); ok && (elem.Name == "byte" || elem.Name == "uint8") && t.Len == nil {
+			return &jsonSchema{Type: "string", ContentEncoding: "base64"}
+		}
+		items := sb.typeSchema(t.Elt)
+		if items == nil {
+			return nil
+		}
+		return &jsonSchema{Type: "array", Items: items}
+	case *ast.MapType:
+		values := sb.typeSchema(t.Value)
+		if values == nil {
+			return nil
+		}
+		return &jsonSchema{Type: "object", AdditionalProperties: values}
+	case *ast.StructType:
+		return sb.object(t)
+	case *ast.InterfaceType:
+		return &jsonSchema{}
+	case *ast.SelectorExpr:
+		name := nodeString(token.NewFileSet(), t)
+		switch name {
+		case "time.Time":
+			return &jsonSchema{Type: "string", Format: "date-time"}
+		case "time.Duration":
+			return &jsonSchema{Type: "integer", Description: "nanoseconds"}
+		case "json.RawMessage", "json.Number":
+			return &jsonSchema{Description: "any JSON value (" + name + ")"}
+		}
+		return &jsonSchema{Description: name + " (defined in another package; see its documentation)"}
+	case *ast.ChanType, *ast.FuncType:
+		return nil
+	}
+	return &jsonSchema{}
+}
+
+// namedSchema returns a reference to the schema of the package's type name,
+// adding it to $defs on first use.
+func (sb *schemaBuilder) namedSchema(name string) *jsonSchema {
+	if name == sb.root {
+		return &jsonSchema{Ref: "#"}
+	}
+	ref := &jsonSchema{Ref: "#/$defs/" + name}
+	if _, ok := sb.defs[name]; ok {
+		return ref
+	}
+	ts, docText := findTypeSpec(sb.files, name)
+	if ts == nil {
+		return &jsonSchema{}
+	}
+	// Reserve the entry first so recursive types terminate.
+	sb.defs[name] = &jsonSchema{}
+	def := sb.typeSchema(ts.Type)
+	if def == nil {
+		delete(sb.defs, name)
+		return nil
+	}
+	if def.Type == "string" || def.Type == "integer" {
+		def.Enum = constValues(sb.files, name)
+	}
+	def.Description = strings.TrimSpace(docText)
+	sb.defs[name] = def
+	return ref
+}
+
+// jsonFieldTag interprets the json key of field's struct tag: the property
+// name ("" to use the field name), whether omitempty or omitzero may leave
+// it out, whether ",string" quotes it, and whether "-" excludes it.
+func jsonFieldTag(field *ast.Field) (name string, omit, quoted, skip bool) {
+	if field.Tag == nil {
+		return "", false, false, false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", false, false, false
+	}
+	for _, kv := range structTags(tag) {
+		if kv[0] != "json" {
+			continue
+		}
+		if kv[1] == "-" {
+			return "", false, false, true
+		}
+		parts := strings.Split(kv[1], ",")
+		for _, opt := range parts[1:] {
+			switch opt {
+			case "omitempty", "omitzero":
+				omit = true
+			case "string":
+				quoted = true
+			}
+		}
+		return parts[0], omit, quoted, false
+	}
+	return "", false, false, false
+}
+
+// fieldDescription joins a field's doc and line comments into one line.
+func fieldDescription(field *ast.Field) string {
+	text := strings.TrimSpace(field.Doc.Text() + " " + field.Comment.Text())
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// constValues returns the values of the constants declared with the type
+// name, for use as an enum, or nil if there are none or any is not a
+// literal (such as an iota expression).
+func constValues(files []*ast.File, name string) []any {
+	var values []any
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if id, ok := vs.Type.(*ast.Ident); !ok || id.Name != name {
+					continue
+				}
+				if len(vs.Values) != len(vs.Names) {
+					return nil
+				}
+				for _, v := range vs.Values {
+					lit, ok := v.(*ast.BasicLit)
+					if !ok {
+						return nil
+					}
+					switch lit.Kind {
+					case token.STRING:
+						s, _ := strconv.Unquote(lit.Value)
+						values = append(values, s)
+					case token.INT:
+						n, err := strconv.ParseInt(lit.Value, 0, 64)
+						if err != nil {
+							return nil
+						}
+						values = append(values, n)
+					default:
+						return nil
+					}
+				}
+			}
+		}
+	}
+	return values
+}
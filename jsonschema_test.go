@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleDescribeStructJSONSchema(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/api\n\ngo 1.21\n",
+		"api.go": `package api
+
+import "time"
+
+// Status is the state of an order.
+type Status string
+
+const (
+	Pending Status = "pending"
+	Shipped Status = "shipped"
+)
+
+// Meta is shared by all resources.
+type Meta struct {
+	Created time.Time ` + "`json:\"created\"`" + `
+}
+
+// Item is one line of an order.
+type Item struct {
+	SKU string ` + "`json:\"sku\"`" + `
+}
+
+// Order is a customer order.
+type Order struct {
+	Meta
+	ID     int64             ` + "`json:\"id,string\"`" + `
+	Status Status            ` + "`json:\"status\"`" + ` // current state
+	Items  []Item            ` + "`json:\"items\"`" + `
+	Notes  *string           ` + "`json:\"notes,omitempty\"`" + `
+	Labels map[string]string ` + "`json:\"labels,omitempty\"`" + `
+	Parent *Order            ` + "`json:\"parent,omitempty\"`" + `
+	Blob   []byte
+	Secret string ` + "`json:\"-\"`" + `
+	done   chan struct{}
+}
+`,
+	})
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "describe_struct"
+	req.Params.Arguments = map[string]any{"path": ".", "target": "Order", "format": "json_schema", "working_dir": dir}
+	result, err := gs.handleDescribeStruct(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleDescribeStruct returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleDescribeStruct returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(text), &schema); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, text)
+	}
+	if strings.Index(text, `"created"`) > strings.Index(text, `"id"`) || strings.Index(text, `"id"`) > strings.Index(text, `"Blob"`) {
+		t.Errorf("properties not in field order:\n%s", text)
+	}
+	for _, want := range []string{
+		`"title": "Order"`,
+		`"description": "Order is a customer order."`,
+		`"created": {
+      "type": "string",
+      "format": "date-time"
+    }`,
+		`"id": {
+      "description": "integer encoded as a JSON string",
+      "type": "string"
+    }`,
+		`"status": {
+      "$ref": "#/$defs/Status",
+      "description": "current state"
+    }`,
+		`"$ref": "#/$defs/Item"`,
+		`"parent": {
+      "$ref": "#"
+    }`,
+		`"Blob": {
+      "type": "string",
+      "contentEncoding": "base64"
+    }`,
+		`"required": [
+    "created",
+    "id",
+    "status",
+    "items",
+    "Blob"
+  ]`,
+		`"enum": [
+        "pending",
+        "shipped"
+      ]`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %s in:\n%s", want, text)
+		}
+	}
+	for _, absent := range []string{"Secret", "done", `"Meta"`} {
+		if strings.Contains(text, absent) {
+			t.Errorf("unexpected %s in:\n%s", absent, text)
+		}
+	}
+
+	req.Params.Arguments = map[string]any{"path": ".", "target": "Order", "format": "yaml", "working_dir": dir}
+	if result, _ := gs.handleDescribeStruct(context.Background(), req); !result.IsError {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/build"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const languageHelpDescription = `Explain a Go language construct that belongs to no package: a keyword
+(select, defer, range, go, ...), a built-in function (append, make, len,
+recover, ...), or a topic such as "method sets", "iota", "type switches",
+"zero value", or "generics". Returns a short summary followed by the relevant
+sections of the Go specification shipped with the installed toolchain, and for
+built-ins their go doc entry. Any section title of the spec also works as a
+keyword (e.g. "order of evaluation").`
+
+// languageTopic is a curated entry for language_help: a one-paragraph
+// summary and the spec sections (by heading id) that define the construct.
+type languageTopic struct {
+	summary  string
+	sections []string
+}
+
+var languageTopics = map[string]languageTopic{
+	"break":       {"break ends the innermost for, switch, or select statement, or the labeled one it names, and continues after it.", []string{"Break_statements"}},
+	"case":        {"case introduces a clause of a switch or select statement. Switch cases are tried top to bottom and do not fall through unless the clause ends with fallthrough.", []string{"Expression_switches", "Select_statements"}},
+	"chan":        {"chan declares a channel type: chan T (bidirectional), chan<- T (send-only), or <-chan T (receive-only). Sends to and receives from a nil channel block forever; sending on a closed channel panics; receiving from a closed channel yields the zero value immediately.", []string{"Channel_types", "Send_statements", "Receive_operator"}},
+	"const":       {"const declares named constants. Untyped constants have arbitrary precision until used; iota numbers successive constants in a group.", []string{"Constant_declarations", "Iota", "Constants"}},
+	"continue":    {"continue skips to the next iteration of the innermost for loop, or the labeled one it names.", []string{"Continue_statements"}},
+	"default":     {"default is the clause a switch runs when no case matches, or that a select runs immediately when no communication is ready (making the select non-blocking).", []string{"Expression_switches", "Select_statements"}},
+	"defer":       {"defer schedules a function call to run when the surrounding function returns, in last-in-first-out order. The call's function value and arguments are evaluated when the defer statement executes, not when the call runs. Deferred functions can read and modify named results and can recover from panics.", []string{"Defer_statements"}},
+	"else":        {"else introduces the alternative branch of an if statement.", []string{"If_statements"}},
+	"fallthrough": {"fallthrough, as the last statement of an expression switch clause, transfers control to the first statement of the next clause without testing its case. It is not allowed in type switches.", []string{"Fallthrough_statements"}},
+	"for":         {"for is Go's only loop: for cond { }, for init; cond; post { }, for { }, and for ... range. Since Go 1.22 each iteration has its own copy of variables declared by the loop.", []string{"For_statements", "For_condition", "For_clause"}},
+	"func":        {"func declares functions and methods, and writes function types and function literals (closures), which capture variables from the enclosing scope by reference.", []string{"Function_declarations", "Method_declarations", "Function_literals"}},
+	"go":          {"go starts a function call in a new goroutine. The function value and arguments are evaluated in the calling goroutine; the program does not wait for the goroutine, and its results are discarded.", []string{"Go_statements"}},
+	"goto":        {"goto transfers control to a label in the same function. It may not jump over variable declarations or into a block.", []string{"Goto_statements"}},
+	"if":          {"if runs a block when a boolean condition holds, optionally after a short statement whose variables are scoped to the if and its else branches.", []string{"If_statements"}},
+	"import":      {"import makes another package's exported identifiers available, by package name, a chosen name, . (into the file block), or _ (for side effects only).", []string{"Import_declarations"}},
+	"interface":   {"interface types specify method sets; a type implements an interface implicitly by having its methods. General interfaces (with type terms such as ~int | ~string) can only be used as type constraints.", []string{"Interface_types", "Basic_interfaces", "Embedded_interfaces", "General_interfaces", "Implementing_an_interface"}},
+	"map":         {"map[K]V is a hash table from comparable keys to values. Reading a missing key yields the zero value (use v, ok := m[k] to tell); writing to a nil map panics; iteration order is unspecified.", []string{"Map_types", "Deletion_of_map_elements"}},
+	"package":     {"package names the package a source file belongs to; all files in a directory share it. Package main with func main is a program.", []string{"Package_clause", "Packages"}},
+	"range":       {"range iterates over arrays, slices, strings (by rune), maps (in unspecified order), channels (until closed), integers (Go 1.22), and iterator functions (Go 1.23).", []string{"For_range"}},
+	"return":      {"return ends the current function, providing its results. With named results a bare return returns their current values; deferred calls run after results are set.", []string{"Return_statements"}},
+	"select":      {"select waits on several channel operations and runs the case of one that can proceed, chosen uniformly at random if several can. With a default clause it never blocks; an empty select{} blocks forever.", []string{"Select_statements"}},
+	"struct":      {"struct types are sequences of named fields. Embedded fields promote their fields and methods to the outer struct; struct tags are metadata read via reflection.", []string{"Struct_types"}},
+	"switch":      {"switch compares an expression (or true) against cases top to bottom, running the first match only. A type switch, switch v := x.(type), branches on the dynamic type of an interface value.", []string{"Switch_statements", "Expression_switches", "Type_switches"}},
+	"type":        {"type declares a new defined type (type T U, with its own method set) or an alias (type T = U, identical to U), optionally with type parameters.", []string{"Type_declarations", "Alias_declarations", "Type_definitions"}},
+	"var":         {"var declares variables, initialized to the given values or to their zero values. Inside functions, x := v is the short form.", []string{"Variable_declarations", "Short_variable_declarations"}},
+
+	"iota":                {"iota is the index of the current constant specification in a const group, starting at 0; repeated implicit expressions make enumerations and bit flags concise.", []string{"Iota"}},
+	"method sets":         {"The method set of a type T holds the methods declared with receiver T; the method set of *T also holds those with receiver *T. This decides which interfaces a value or pointer implements.", []string{"Method_sets"}},
+	"type switches":       {"A type switch branches on the dynamic type of an interface value; in each single-type case the bound variable has that type.", []string{"Type_switches"}},
+	"type assertions":     {"x.(T) asserts that interface value x holds T (or implements interface T). It panics on failure unless written v, ok := x.(T).", []string{"Type_assertions"}},
+	"generics":            {"Functions and types can declare type parameters constrained by interfaces, e.g. func Map[T, U any](s []T, f func(T) U) []U. Type arguments are usually inferred from the call.", []string{"Type_parameter_declarations", "Type_constraints", "Instantiations"}},
+	"zero value":          {"Every variable without an explicit initializer holds its type's zero value: false, 0, \"\", nil for pointers, functions, interfaces, slices, channels, and maps, and recursively zeroed arrays and structs.", []string{"The_zero_value"}},
+	"init":                {"func init() runs automatically during package initialization, after all package-level variables are initialized; a package may declare several, and they cannot be called or referenced.", []string{"Package_initialization"}},
+	"embedding":           {"An embedded field (a type name without a field name) promotes the embedded type's fields and methods to the outer struct; interfaces embed other interfaces to union their method sets.", []string{"Struct_types", "Embedded_interfaces"}},
+	"slices":              {"A slice is a descriptor of a contiguous segment of an underlying array (pointer, length, capacity). Slicing shares the array; append reallocates when capacity is exceeded.", []string{"Slice_types", "Slice_expressions", "Appending_and_copying_slices"}},
+	"conversions":         {"T(x) converts x to type T when their underlying types are compatible, between numeric types, or between strings and byte or rune slices.", []string{"Conversions", "Conversions_to_and_from_a_string_type"}},
+	"closures":            {"Function literals are closures: they may refer to variables of the surrounding function, which are shared with it and live as long as they are accessible.", []string{"Function_literals"}},
+	"labels":              {"Labels name statements for break, continue, and goto; they are scoped to the function body and must be used.", []string{"Labeled_statements", "Label_scopes"}},
+	"strings":             {"A string is an immutable sequence of bytes, conventionally UTF-8. Indexing yields bytes; ranging yields runes.", []string{"String_types", "String_literals"}},
+	"runes":               {"A rune is an alias for int32 holding a Unicode code point; rune literals are written in single quotes.", []string{"Rune_literals"}},
+	"operators":           {"Go has five precedence levels of binary operators: * / % << >> & &^, then + - | ^, then comparisons, then &&, then ||.", []string{"Operator_precedence"}},
+	"order of evaluation": {"Function calls, method calls, receive operations, and logical operations are evaluated in lexical left-to-right order; the order of other operand evaluations is unspecified.", []string{"Order_of_evaluation"}},
+}
+
+// builtinSections maps built-in functions and predeclared identifiers to the
+// spec sections that define them; their go doc builtin entries are appended.
+var builtinSections = map[string][]string{
+	"append": {"Appending_and_copying_slices"}, "copy": {"Appending_and_copying_slices"},
+	"cap": {"Length_and_capacity"}, "len": {"Length_and_capacity"},
+	"clear": {"Clear"}, "close": {"Close"},
+	"complex": {"Complex_numbers"}, "real": {"Complex_numbers"}, "imag": {"Complex_numbers"},
+	"delete": {"Deletion_of_map_elements"}, "make": {"Making_slices_maps_and_channels"},
+	"min": {"Min_and_max"}, "max": {"Min_and_max"}, "new": {"Allocation"},
+	"panic": {"Handling_panics"}, "recover": {"Handling_panics"},
+	"print": {"Bootstrapping"}, "println": {"Bootstrapping"},
+	"any": {"Interface_types"}, "comparable": {"Type_constraints"}, "error": {"Errors"},
+	"nil": {"Predeclared_identifiers"}, "true": {"Boolean_types"}, "false": {"Boolean_types"},
+}
+
+// specHeading matches a section heading of the HTML spec.
+var specHeading = regexp.MustCompile(`<h([2-4]) id="([^"]+)">(.*?)</h[2-4]>`)
+
+var (
+	specPre       = regexp.MustCompile(`(?s)<pre[^>]*>(.*?)</pre>`)
+	specBlockTag  = regexp.MustCompile(`(?i)</?(p|ul|ol|table|tr|blockquote)[^>]*>`)
+	specListItem  = regexp.MustCompile(`(?i)<li[^>]*>`)
+	specAnyTag    = regexp.MustCompile(`<[^>]+>`)
+	specBlankLine = regexp.MustCompile(`\n\s*\n`)
+)
+
+func (gs *godocServer) handleLanguageHelp(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	keyword, err := request.RequireString("keyword")
+	if err != nil {
+		return mcp.NewToolResultError("keyword argument is required"), nil
+	}
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+
+	spec, specErr := os.ReadFile(filepath.Join(build.Default.GOROOT, "doc", "go_spec.html"))
+
+	var b strings.Builder
+	var sections []string
+	topic, isTopic := languageTopics[keyword]
+	builtin, isBuiltin := builtinSections[keyword]
+	switch {
+	case isTopic:
+		b.WriteString(topic.summary + "\n")
+		sections = topic.sections
+	case isBuiltin:
+		sections = builtin
+	case specErr == nil:
+		if id := specSectionID(string(spec), keyword); id != "" {
+			sections = []string{id}
+		}
+	}
+	if len(sections) == 0 {
+		names := make([]string, 0, len(languageTopics)+len(builtinSections))
+		for name := range languageTopics {
+			names = append(names, name)
+		}
+		for name := range builtinSections {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return mcp.NewToolResultError(fmt.Sprintf("no language help for %q; known keywords: %s (or any Go spec section title)",
+			keyword, strings.Join(names, ", "))), nil
+	}
+
+	if isBuiltin {
+		listCtx, cancel := commandContext(ctx)
+		out, err := gs.output(listCtx, gs.goCommand(listCtx, "", "doc", "builtin", keyword))
+		cancel()
+		if err == nil {
+			b.WriteString(stripPackageHeader(string(out)))
+		}
+	}
+
+	if specErr != nil {
+		fmt.Fprintf(&b, "\n(the Go specification is not installed with this toolchain: %v)\n", specErr)
+		return mcp.NewToolResultText(b.String()), nil
+	}
+	for _, id := range sections {
+		if text := specSection(string(spec), id); text != "" {
+			b.WriteString("\n" + text)
+		}
+	}
+	return mcp.NewToolResultText(strings.TrimLeft(b.String(), "\n")), nil
+}
+
+// specSectionID returns the heading id of the spec section whose title is
+// title, ignoring case, or "".
+func specSectionID(spec, title string) string {
+	want := strings.ReplaceAll(title, " ", "_")
+	for _, m := range specHeading.FindAllStringSubmatch(spec, -1) {
+		if strings.EqualFold(m[2], want) || strings.EqualFold(specAnyTag.ReplaceAllString(m[3], ""), title) {
+			return m[2]
+		}
+	}
+	return ""
+}
+
+// specSection renders the spec section with heading id as plain text: its
+// title, then its prose up to the next heading, with grammar and code blocks
+// indented. Subsections are not included.
+func specSection(spec, id string) string {
+	locs := specHeading.FindAllStringSubmatchIndex(spec, -1)
+	for i, loc := range locs {
+		if spec[loc[4]:loc[5]] != id {
+			continue
+		}
+		end := len(spec)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		title := html.UnescapeString(specAnyTag.ReplaceAllString(spec[loc[6]:loc[7]], ""))
+		return fmt.Sprintf("Go specification: %s (https://go.dev/ref/spec#%s)\n\n%s", title, id, specText(spec[loc[1]:end]))
+	}
+	return ""
+}
+
+// specText converts a fragment of the HTML spec to plain text.
+func specText(fragment string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range specPre.FindAllStringSubmatchIndex(fragment, -1) {
+		b.WriteString(specProse(fragment[last:m[0]]))
+		code := html.UnescapeString(specAnyTag.ReplaceAllString(fragment[m[2]:m[3]], ""))
+		for _, line := range strings.Split(strings.Trim(code, "\n"), "\n") {
+			if line != "" {
+				b.WriteString("    " + line)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		last = m[1]
+	}
+	b.WriteString(specProse(fragment[last:]))
+	return b.String()
+}
+
+// specProse converts HTML prose to paragraphs of plain text, one per line.
+func specProse(fragment string) string {
+	fragment = specBlockTag.ReplaceAllString(fragment, "\n\n")
+	fragment = specListItem.ReplaceAllString(fragment, "\n\n- ")
+	fragment = html.UnescapeString(specAnyTag.ReplaceAllString(fragment, ""))
+
+	var b strings.Builder
+	for _, para := range specBlankLine.Split(fragment, -1) {
+		if text := strings.Join(strings.Fields(para), " "); text != "" {
+			b.WriteString(text + "\n\n")
+		}
+	}
+	return b.String()
+}
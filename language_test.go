@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const specFixture = `<h2 id="Statements">Statements</h2>
+<h3 id="Defer_statements">Defer statements</h3>
+
+<p>
+A "defer" statement invokes a function whose execution is deferred
+to the moment the surrounding function <a href="#Return_statements">returns</a>.
+</p>
+
+<pre class="ebnf">
+DeferStmt = "defer" Expression .
+</pre>
+
+<ul>
+<li>first &amp; foremost</li>
+</ul>
+
+<pre>
+lock(l)
+
+defer unlock(l)
+</pre>
+
+<h2 id="Built-in_functions">Built-in functions</h2>
+`
+
+func TestSpecSection(t *testing.T) {
+	want := "Go specification: Defer statements (https://go.dev/ref/spec#Defer_statements)\n\n" +
+		"A \"defer\" statement invokes a function whose execution is deferred to the moment the surrounding function returns.\n\n" +
+		"    DeferStmt = \"defer\" Expression .\n\n" +
+		"- first & foremost\n\n" +
+		"    lock(l)\n\n    defer unlock(l)\n\n"
+	if got := specSection(specFixture, "Defer_statements"); got != want {
+		t.Errorf("specSection() =\n%q\nwant\n%q", got, want)
+	}
+	if got := specSection(specFixture, "Missing"); got != "" {
+		t.Errorf("expected no section, got %q", got)
+	}
+	if got := specSectionID(specFixture, "defer statements"); got != "Defer_statements" {
+		t.Errorf("specSectionID() = %q, want Defer_statements", got)
+	}
+}
+
+func TestHandleLanguageHelp(t *testing.T) {
+	if _, err := os.Stat(filepath.Join(build.Default.GOROOT, "doc", "go_spec.html")); err != nil {
+		t.Skip("Go specification not installed")
+	}
+
+	gs := newGodocServer()
+	call := func(keyword string) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "language_help"
+		req.Params.Arguments = map[string]any{"keyword": keyword}
+		result, err := gs.handleLanguageHelp(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleLanguageHelp returned protocol error: %v", err)
+		}
+		return result
+	}
+
+	tests := map[string][]string{
+		"select":              {"select waits on several channel operations", "Go specification: Select statements", "SelectStmt"},
+		"Defer":               {"Go specification: Defer statements"},
+		"recover":             {"func recover() any", "Go specification: Handling panics"},
+		"order of evaluation": {"Go specification: Order of evaluation"},
+		"Labeled statements":  {"Go specification: Labeled statements"},
+	}
+	for keyword, wants := range tests {
+		result := call(keyword)
+		if result.IsError {
+			t.Errorf("%s: unexpected error: %+v", keyword, result.Content)
+			continue
+		}
+		text := result.Content[0].(mcp.TextContent).Text
+		for _, want := range wants {
+			if !strings.Contains(text, want) {
+				t.Errorf("%s: expected %q in output:\n%s", keyword, want, text)
+			}
+		}
+	}
+
+	if result := call("frobnicate"); !result.IsError {
+		t.Error("expected error for an unknown keyword")
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// defaultMaxArgLength caps string arguments such as path, target, and
+	// working_dir, in bytes, unless configured otherwise. It matches the
+	// usual PATH_MAX.
+	defaultMaxArgLength = 4096
+
+	// defaultMaxArrayLength caps the items of array arguments such as
+	// cmd_flags or a target list unless configured otherwise.
+	defaultMaxArrayLength = 64
+
+	// maxFreeTextLength caps arguments that carry pasted text rather than a
+	// name or path, such as explain_error's error, which may be a full stack
+	// trace.
+	maxFreeTextLength = 1 << 20
+)
+
+// freeTextArgs are the arguments held to maxFreeTextLength instead of the
+// configured argument length.
+var freeTextArgs = map[string]bool{"error": true}
+
+// limitArgs wraps handler so that requests whose arguments exceed the
+// server's length and array limits are rejected before handler runs.
+func (gs *godocServer) limitArgs(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err := gs.checkArgLimits(request.GetArguments()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return handler(ctx, request)
+	}
+}
+
+// checkArgLimits reports the first argument, in name order, that is longer
+// than the server allows or is an array with too many items.
+func (gs *godocServer) checkArgLimits(args map[string]any) error {
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		maxLength := gs.maxArgLength
+		if freeTextArgs[name] {
+			maxLength = maxFreeTextLength
+		}
+		switch v := args[name].(type) {
+		case string:
+			if len(v) > maxLength {
+				return fmt.Errorf("%s argument is %d bytes long; the limit is %d", name, len(v), maxLength)
+			}
+		case []any:
+			if len(v) > gs.maxArrayLength {
+				return fmt.Errorf("%s argument has %d items; the limit is %d", name, len(v), gs.maxArrayLength)
+			}
+			for i, item := range v {
+				if s, ok := item.(string); ok && len(s) > maxLength {
+					return fmt.Errorf("%s[%d] is %d bytes long; the limit is %d", name, i, len(s), maxLength)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestArgLimits(t *testing.T) {
+	gs := newGodocServer(withArgLimits(64, 3))
+	call := func(tool string, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = tool
+		req.Params.Arguments = args
+		result, err := gs.mcpServer.GetTool(tool).Handler(context.Background(), req)
+		if err != nil {
+			t.Fatalf("%s returned protocol error: %v", tool, err)
+		}
+		return result
+	}
+
+	for _, tt := range []struct {
+		tool string
+		args map[string]any
+		want string
+	}{
+		{"get_doc", map[string]any{"path": strings.Repeat("a", 65)}, "path argument is 65 bytes long; the limit is 64"},
+		{"get_doc", map[string]any{"path": "io", "working_dir": "/" + strings.Repeat("d", 100)}, "working_dir argument is 101 bytes long"},
+		{"get_doc", map[string]any{"path": "io", "cmd_flags": []any{"-u", "-u", "-u", "-u"}}, "cmd_flags argument has 4 items; the limit is 3"},
+		{"get_doc", map[string]any{"path": "io", "target": []any{"Reader", strings.Repeat("W", 65)}}, "target[1] is 65 bytes long"},
+		{"list_symbols", map[string]any{"path": strings.Repeat("p/", 40)}, "path argument is 80 bytes long"},
+	} {
+		result := call(tt.tool, tt.args)
+		text := result.Content[0].(mcp.TextContent).Text
+		if !result.IsError || !strings.Contains(text, tt.want) {
+			t.Errorf("%s %v: got %q, want error containing %q", tt.tool, tt.args, text, tt.want)
+		}
+	}
+
+	// A stack trace may be far longer than any path.
+	if err := gs.checkArgLimits(map[string]any{"error": strings.Repeat("goroutine 1 [running]:\n", 100)}); err != nil {
+		t.Errorf("error argument rejected: %v", err)
+	}
+	if err := gs.checkArgLimits(map[string]any{"error": strings.Repeat("x", maxFreeTextLength+1)}); err == nil {
+		t.Error("expected an oversized error argument to be rejected")
+	}
+	if err := newGodocServer().checkArgLimits(map[string]any{"path": strings.Repeat("a", 65), "cmd_flags": []any{"-u", "-u", "-u", "-u"}}); err != nil {
+		t.Errorf("default limits rejected ordinary arguments: %v", err)
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"runtime"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/mark3labs/mcp-go/server"
@@ -15,16 +18,112 @@ import (
 
 const version = "1.1.0"
 
+// mcpEndpoint is the path the streamable http transport serves.
+const mcpEndpoint = "/mcp"
+
 func main() {
 	transport := flag.String("transport", "stdio", "Transport type: stdio, sse, or http")
 	addr := flag.String("addr", ":8080", "Listen address for sse/http transport")
+	tempModule := flag.String("temp-module", defaultTempModule, "Module path used for temporary projects")
+	tempGoVersion := flag.String("temp-go-version", "", "go directive for temporary projects (default: match the fetched dependency)")
+	sessionCache := flag.Bool("session-cache", false, "Isolate the documentation cache per client session")
+	denyPackages := flag.String("deny-packages", "", "Comma-separated import path patterns to refuse to document (globs; a trailing /... matches sub-packages)")
+	allowedRoots := flag.String("allowed-roots", "", "Comma-separated directories that working_dir and local package paths must be inside (default: any)")
+	cacheShards := flag.Int("cache-shards", defaultCacheShards, "Number of independently locked shards the documentation cache is split into")
+	parseCacheSize := flag.Int("parse-cache-size", defaultParseCacheSize, "Number of parsed packages the parse-based tools share until their source changes (0 disables)")
+	maxConcurrent := flag.Int("max-concurrent", runtime.NumCPU(), "Maximum number of go subprocesses running at once")
+	maxTempProjects := flag.Int("max-temp-projects", defaultMaxTempProjects, "Maximum number of temporary project directories on disk; the least recently used idle one is removed to make room")
+	corsOrigins := flag.String("cors-origins", "", "Comma-separated origins allowed to call the sse/http transport from a browser, or * for any")
+	cleanEnv := flag.Bool("clean-env", false, "Run go subprocesses with only PATH, HOME, GOPATH, GOCACHE, and GOMODCACHE set")
+	compress := flag.Bool("compress", false, "Compress JSON responses of the http transport with gzip or deflate when the client accepts it")
+	idleTimeout := flag.Duration("idle-timeout", 0, "Shut down the sse/http server gracefully after this long without requests (0 disables)")
+	disassembly := flag.Bool("enable-disassembly", false, "Enable the disassemble tool, which compiles local packages to show the assembly of a function")
+	gitTools := flag.Bool("enable-git", false, "Enable the api_diff tool, which runs git to compare the exported API of a local package between revisions")
+	testTools := flag.Bool("enable-test", false, "Enable the test_coverage tool, which runs go test -cover on local packages")
+	goGet := flag.Bool("enable-go-get", false, "Let get_doc with mod_mode=mod run go get, editing the working_dir module's go.mod and go.sum, for modules only in the pruned module graph")
+	tools := flag.String("tools", "", "Comma-separated names of the tools to register, e.g. get_doc,list_symbols (default: all)")
+	goToolchain := flag.String("gotoolchain", "", "GOTOOLCHAIN for go subprocesses: local, auto, or a version such as go1.22.5 (default: inherit the environment)")
+	noToolchainDownload := flag.Bool("no-toolchain-download", false, "Set GOTOOLCHAIN=local for go subprocesses so no toolchain is ever downloaded; modules needing a newer Go fail with an error")
+	modCache := flag.String("modcache", "", "GOMODCACHE for go subprocesses, e.g. a disposable directory for CI (default: inherit the environment)")
+	includeStderr := flag.Bool("include-stderr", false, "Append warnings go doc writes to stderr to documentation results instead of only logging them")
+	responsePreamble := flag.String("response-preamble", "", "Line prepended to every get_doc result, e.g. \"godoc-mcp {version}\" ({version} is replaced with the server version)")
+	maxArgLength := flag.Int("max-arg-length", defaultMaxArgLength, "Longest string argument, such as path, target, or working_dir, a tool accepts, in bytes")
+	maxArrayLength := flag.Int("max-array-length", defaultMaxArrayLength, "Most items an array argument, such as cmd_flags or a target list, may have")
+	warmup := flag.String("warmup", "", "Comma-separated module directories in which to warm the build cache at startup, in the background")
+	warmupCommand := flag.String("warmup-command", "build", "go command run as ./... in each -warmup directory: build or vet")
 	flag.Parse()
 
+	if *noToolchainDownload && *goToolchain != "" && *goToolchain != "local" {
+		fmt.Fprintf(os.Stderr, "-no-toolchain-download conflicts with -gotoolchain=%s\n", *goToolchain)
+		os.Exit(1)
+	}
+
 	log.SetOutput(os.Stderr)
 	log.Printf("Starting godoc-mcp server v%s (%s transport)...", version, *transport)
+	if n := sweepStaleProjects(); n > 0 {
+		log.Printf("Removed %d temporary projects left by earlier runs", n)
+	}
 
-	gs := newGodocServer()
+	gs := newGodocServer(
+		withTempModule(*tempModule, *tempGoVersion),
+		withSessionCache(*sessionCache),
+		withDenyPackages(splitList(*denyPackages)),
+		withAllowedRoots(splitList(*allowedRoots)),
+		withMaxConcurrent(*maxConcurrent),
+		withMaxTempProjects(*maxTempProjects),
+		withCacheShards(*cacheShards),
+		withParseCacheSize(*parseCacheSize),
+		withResponsePreamble(*responsePreamble),
+		withCleanEnv(*cleanEnv),
+		withIncludeStderr(*includeStderr),
+		withDisassembly(*disassembly),
+		withGitTools(*gitTools),
+		withTestTools(*testTools),
+		withGoGet(*goGet),
+		withTools(splitList(*tools)),
+		withGoToolchain(*goToolchain),
+		withNoToolchainDownload(*noToolchainDownload),
+		withArgLimits(*maxArgLength, *maxArrayLength),
+		withModCache(*modCache),
+		withWarmup(splitList(*warmup), *warmupCommand),
+	)
 	defer gs.cleanup()
+	gs.startWarmup()
+
+	origins := splitList(*corsOrigins)
+	if len(origins) > 0 && *transport == "stdio" {
+		log.Printf("Ignoring -cors-origins: it only applies to the sse and http transports")
+	}
+	if *idleTimeout > 0 && *transport == "stdio" {
+		log.Printf("Ignoring -idle-timeout: it only applies to the sse and http transports")
+	}
+	// The sse transport sends results over its event stream, which is never
+	// compressed, so only http benefits.
+	if *compress && *transport != "http" {
+		log.Printf("Ignoring -compress: it only applies to the http transport")
+		*compress = false
+	}
+
+	// wrap adds compression, CORS, and idle tracking to a networked
+	// transport's handler; customServer reports whether any is in use, which
+	// requires serving the transport from our own http.Server.
+	customServer := len(origins) > 0 || *idleTimeout > 0 || *compress
+	wrap := func(h http.Handler, shutdown func()) http.Handler {
+		if *compress {
+			h = compressHandler(h)
+		}
+		if len(origins) > 0 {
+			h = corsHandler(origins, h)
+		}
+		if *idleTimeout > 0 {
+			idle := newIdleTimer(*idleTimeout, func() {
+				log.Printf("No requests for %s; shutting down...", *idleTimeout)
+				shutdown()
+			})
+			h = idle.handler(h)
+		}
+		return h
+	}
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -46,15 +145,24 @@ func main() {
 		if strings.HasPrefix(host, ":") {
 			host = "localhost" + host
 		}
-		sseServer := server.NewSSEServer(gs.mcpServer,
-			server.WithBaseURL("http://"+host),
+		sseOpts := []server.SSEOption{
+			server.WithBaseURL("http://" + host),
 			server.WithKeepAlive(true),
-		)
+		}
+		var srv *http.Server
+		if customServer {
+			srv = &http.Server{}
+			sseOpts = append(sseOpts, server.WithHTTPServer(srv))
+		}
+		sseServer := server.NewSSEServer(gs.mcpServer, sseOpts...)
+		shutdown := onceShutdown(gs, sseServer.Shutdown)
+		if srv != nil {
+			srv.Handler = wrap(sseServer, shutdown)
+		}
 		go func() {
 			<-sigCh
 			log.Printf("Shutting down...")
-			gs.cleanup()
-			sseServer.Shutdown(context.Background())
+			shutdown()
 		}()
 		log.Printf("SSE server listening on %s", *addr)
 		if err := sseServer.Start(*addr); err != nil {
@@ -62,12 +170,24 @@ func main() {
 		}
 
 	case "http":
-		httpServer := server.NewStreamableHTTPServer(gs.mcpServer)
+		// As for sse; a custom http.Server must also route the endpoint.
+		var httpOpts []server.StreamableHTTPOption
+		var srv *http.Server
+		if customServer {
+			srv = &http.Server{}
+			httpOpts = append(httpOpts, server.WithStreamableHTTPServer(srv))
+		}
+		httpServer := server.NewStreamableHTTPServer(gs.mcpServer, httpOpts...)
+		shutdown := onceShutdown(gs, httpServer.Shutdown)
+		if srv != nil {
+			mux := http.NewServeMux()
+			mux.Handle(mcpEndpoint, httpServer)
+			srv.Handler = wrap(mux, shutdown)
+		}
 		go func() {
 			<-sigCh
 			log.Printf("Shutting down...")
-			gs.cleanup()
-			httpServer.Shutdown(context.Background())
+			shutdown()
 		}()
 		log.Printf("HTTP server listening on %s", *addr)
 		if err := httpServer.Start(*addr); err != nil {
@@ -79,3 +199,26 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// onceShutdown returns a function that cleans up gs and gracefully stops a
+// networked transport, doing so only on its first call.
+func onceShutdown(gs *godocServer, stop func(context.Context) error) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			gs.cleanup()
+			stop(context.Background())
+		})
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
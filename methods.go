@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const methodSetDescription = `List the full method set of a Go type, including methods promoted from
+embedded fields. Each method is shown with the receiver it is declared on and,
+when promoted, the embedded type it comes from and the field path that reaches
+it. Methods are grouped by whether they are callable on a value of the type or
+only through a pointer. Use this to see what is actually callable on a struct
+that embeds other types; go doc does not list promoted methods.`
+
+// methodEntry is one exported method in the method set of a named type.
+type methodEntry struct {
+	decl    string // "func (recv) Name(params) results"
+	from    string // embedded type the method is promoted from; "" if declared on the type
+	via     string // embedded field path to from, e.g. "Base.Conn"
+	ptrOnly bool   // only in the method set of the pointer type
+}
+
+func (gs *godocServer) handleMethodSet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	target, err := request.RequireString("target")
+	if err != nil {
+		return mcp.NewToolResultError("target argument is required"), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := gs.typeCheck(ctx, lp); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	obj, ok := lp.pkg.Scope().Lookup(target).(*types.TypeName)
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("type %s not found in %s", target, pkgPath)), nil
+	}
+	if _, isIface := obj.Type().Underlying().(*types.Interface); isIface {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is an interface; use describe_interface for its methods", target)), nil
+	}
+
+	var value, pointer []string
+	for _, m := range methodSet(lp.pkg, obj) {
+		line := m.decl
+		if m.from != "" {
+			line += "  // promoted from " + m.from
+			if strings.Contains(m.via, ".") {
+				line += " via " + m.via
+			}
+		}
+		if m.ptrOnly {
+			pointer = append(pointer, line)
+		} else {
+			value = append(value, line)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Method set of %s.%s\n", pkgPath, target)
+	if len(value)+len(pointer) == 0 {
+		b.WriteString("\nno exported methods\n")
+		return mcp.NewToolResultText(b.String()), nil
+	}
+	if len(value) > 0 {
+		fmt.Fprintf(&b, "\nCALLABLE ON %s AND *%s\n\n%s\n", target, target, strings.Join(value, "\n"))
+	}
+	if len(pointer) > 0 {
+		fmt.Fprintf(&b, "\nCALLABLE ON *%s ONLY\n\n%s\n", target, strings.Join(pointer, "\n"))
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// methodSet returns the exported methods of the pointer to the type named by
+// obj, which include those of the type itself, in go/types order. Receivers
+// and signatures are qualified relative to pkg.
+func methodSet(pkg *types.Package, obj *types.TypeName) []methodEntry {
+	qual := types.RelativeTo(pkg)
+	valueSet := types.NewMethodSet(obj.Type())
+	ptrSet := types.NewMethodSet(types.NewPointer(obj.Type()))
+
+	var methods []methodEntry
+	for i := 0; i < ptrSet.Len(); i++ {
+		sel := ptrSet.At(i)
+		fn := sel.Obj().(*types.Func)
+		if !fn.Exported() {
+			continue
+		}
+		sig := fn.Type().(*types.Signature)
+		recv := types.TypeString(sig.Recv().Type(), qual)
+		m := methodEntry{
+			decl:    fmt.Sprintf("func (%s) %s%s", recv, fn.Name(), strings.TrimPrefix(types.TypeString(sig, qual), "func")),
+			ptrOnly: valueSet.Lookup(fn.Pkg(), fn.Name()) == nil,
+		}
+		if len(sel.Index()) > 1 {
+			m.from = strings.TrimPrefix(recv, "*")
+			m.via = embeddingPath(obj.Type(), sel.Index())
+		}
+		methods = append(methods, m)
+	}
+	return methods
+}
+
+// embeddingPath names the embedded fields a selection with the given index
+// path walks through, e.g. "Base.Conn".
+func embeddingPath(t types.Type, index []int) string {
+	var names []string
+	for _, i := range index[:len(index)-1] {
+		if p, ok := t.Underlying().(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		st, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			break
+		}
+		field := st.Field(i)
+		names = append(names, field.Name())
+		t = field.Type()
+	}
+	return strings.Join(names, ".")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleMethodSet(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/embed\n\ngo 1.21\n",
+		"embed.go": `package embed
+
+import "sync"
+
+type Inner struct{}
+
+func (Inner) ID() int          { return 0 }
+func (*Inner) Reset()          {}
+
+type Base struct {
+	Inner
+	sync.Mutex
+}
+
+func (Base) Name() string { return "" }
+
+type Conn struct {
+	Base
+}
+
+func (*Conn) Dial() error { return nil }
+func (c Conn) helper()    {}
+
+type Reader interface{ Read() }
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(target string) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "method_set"
+		req.Params.Arguments = map[string]any{"path": ".", "target": target, "working_dir": dir}
+		result, err := gs.handleMethodSet(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleMethodSet returned protocol error: %v", err)
+		}
+		return result
+	}
+
+	result := call("Conn")
+	if result.IsError {
+		t.Fatalf("handleMethodSet returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	value, pointer, ok := strings.Cut(text, "CALLABLE ON *Conn ONLY")
+	if !ok {
+		t.Fatalf("missing pointer-only section:\n%s", text)
+	}
+	for _, want := range []string{
+		"func (Inner) ID() int  // promoted from Inner via Base.Inner",
+		"func (Base) Name() string  // promoted from Base",
+	} {
+		if !strings.Contains(value, want) {
+			t.Errorf("expected %q among value methods:\n%s", want, text)
+		}
+	}
+	for _, want := range []string{
+		"func (*Conn) Dial() error\n",
+		"func (*Inner) Reset()  // promoted from Inner via Base.Inner",
+		"func (*sync.Mutex) Lock()  // promoted from sync.Mutex via Base.Mutex",
+	} {
+		if !strings.Contains(pointer, want) {
+			t.Errorf("expected %q among pointer methods:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "helper") {
+		t.Errorf("unexported method should be omitted:\n%s", text)
+	}
+
+	if result := call("Reader"); !result.IsError {
+		t.Error("expected error for an interface")
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	goversion "go/version"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// sinceDirective matches doc comments that state the Go release a symbol
+// needs, e.g. "Available since go1.21" or "Requires Go 1.22".
+var sinceDirective = regexp.MustCompile(`(?i)\b(?:available since|added in|requires) go ?(1\.\d+(?:\.\d+)?)\b`)
+
+var (
+	stdAPIOnce     sync.Once
+	stdAPIVersions map[string]string // "importPath Symbol" -> release that added it
+)
+
+// minGoVersionNote reports the minimum Go version target in importPath needs,
+// when it can be determined: from the API files in GOROOT/api for the standard
+// library, and otherwise from a go1.N build constraint on the declaring file
+// or an "Available since go1.N" line in the doc comment. It returns "" when
+// nothing is known or the symbol is available in every Go 1 release.
+func (gs *godocServer) minGoVersionNote(ctx context.Context, workingDir, importPath, target string) string {
+	if isStdLib(importPath) {
+		if v := stdAPIVersion(importPath, target); v != "" && v != "go1" {
+			return "\nMinimum Go version: " + v + " (added to the standard library in " + v + ")\n"
+		}
+		return ""
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, importPath)
+	if err != nil {
+		return ""
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return ""
+	}
+	sym, ok := findSymbol(dp, target)
+	if !ok {
+		return ""
+	}
+
+	version, source := "", ""
+	if m := sinceDirective.FindStringSubmatch(sym.doc); m != nil {
+		version, source = "go"+m[1], "stated in its doc comment"
+	}
+	file := lp.fset.File(sym.decl.Pos())
+	for _, f := range lp.files {
+		if lp.fset.File(f.Pos()) != file {
+			continue
+		}
+		if v := buildGoVersion(f); v != "" && goversion.Compare(v, version) > 0 {
+			version, source = v, "its file has a //go:build "+v+" constraint"
+		}
+	}
+	if version == "" {
+		return ""
+	}
+	return "\nMinimum Go version: " + version + " (" + source + ")\n"
+}
+
+// buildGoVersion returns the minimum Go version implied by file's //go:build
+// constraint, or "" if it has none.
+func buildGoVersion(file *ast.File) string {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, c := range group.List {
+			if !constraint.IsGoBuild(c.Text) {
+				continue
+			}
+			if expr, err := constraint.Parse(c.Text); err == nil {
+				return constraint.GoVersion(expr)
+			}
+		}
+	}
+	return ""
+}
+
+// stdAPIVersion returns the Go release that added target to the standard
+// library package importPath, such as "go1.21", according to the API files
+// shipped in GOROOT/api. It returns "" if the symbol is not listed.
+func stdAPIVersion(importPath, target string) string {
+	stdAPIOnce.Do(func() {
+		stdAPIVersions = make(map[string]string)
+		files, _ := filepath.Glob(filepath.Join(build.Default.GOROOT, "api", "go1*.txt"))
+		for _, name := range files {
+			f, err := os.Open(name)
+			if err != nil {
+				continue
+			}
+			readAPIFile(f, strings.TrimSuffix(filepath.Base(name), ".txt"), stdAPIVersions)
+			f.Close()
+		}
+	})
+	return stdAPIVersions[importPath+" "+target]
+}
+
+// readAPIFile records in versions each symbol listed in an API file for the
+// given release, keeping the earliest release when a symbol appears in more
+// than one (as platform-specific ones do).
+func readAPIFile(r io.Reader, version string, versions map[string]string) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		pkg, name, ok := apiSymbol(sc.Text())
+		if !ok {
+			continue
+		}
+		key := pkg + " " + name
+		if old, ok := versions[key]; !ok || goversion.Compare(version, old) < 0 {
+			versions[key] = version
+		}
+	}
+}
+
+// apiSymbol extracts the package and symbol name from one line of an API
+// file, e.g. "pkg net/http, method (*Client) CloseIdleConnections()" yields
+// "net/http" and "Client.CloseIdleConnections". Struct fields and interface
+// methods are named Type.Member.
+func apiSymbol(line string) (pkg, name string, ok bool) {
+	rest, ok := strings.CutPrefix(line, "pkg ")
+	if !ok {
+		return "", "", false
+	}
+	pkg, rest, ok = strings.Cut(rest, ", ")
+	if !ok {
+		return "", "", false
+	}
+	pkg, _, _ = strings.Cut(pkg, " ") // drop a "(goos-goarch)" qualifier
+
+	kind, rest, _ := strings.Cut(rest, " ")
+	switch kind {
+	case "const", "var", "func":
+		name = identPrefix(rest)
+	case "type":
+		name = identPrefix(rest)
+		if _, member, ok := strings.Cut(rest, ", "); ok {
+			if embedded, ok := strings.CutPrefix(member, "embedded "); ok {
+				// The field is named after the type, without package or pointer.
+				member = embedded[strings.LastIndexByte(embedded, '.')+1:]
+				member = strings.TrimPrefix(member, "*")
+			}
+			name += "." + identPrefix(member)
+		}
+	case "method":
+		recv, sig, ok := strings.Cut(strings.TrimPrefix(rest, "("), ") ")
+		if !ok {
+			return "", "", false
+		}
+		name = identPrefix(strings.TrimPrefix(recv, "*")) + "." + identPrefix(sig)
+	default:
+		return "", "", false
+	}
+	return pkg, name, name != "" && !strings.HasSuffix(name, ".")
+}
+
+// identPrefix returns the identifier at the start of s.
+func identPrefix(s string) string {
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return r != '_' && !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	})
+	if end < 0 {
+		return s
+	}
+	return s[:end]
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestAPISymbol(t *testing.T) {
+	tests := []struct {
+		line     string
+		wantPkg  string
+		wantName string
+		wantOK   bool
+	}{
+		{"pkg slices, func Clip[$0 interface{ ~[]$1 }, $1 interface{}]($0) $0 #57433", "slices", "Clip", true},
+		{"pkg net/http, method (*Client) CloseIdleConnections()", "net/http", "Client.CloseIdleConnections", true},
+		{"pkg sync, method (*Map[$0, $1]) Load($0) ($1, bool)", "sync", "Map.Load", true},
+		{"pkg archive/tar, const FormatGNU = 8", "archive/tar", "FormatGNU", true},
+		{"pkg syscall (linux-386), const AF_INET = 2", "syscall", "AF_INET", true},
+		{"pkg io, type OffsetWriter struct", "io", "OffsetWriter", true},
+		{"pkg archive/tar, type Header struct, Format Format", "archive/tar", "Header.Format", true},
+		{"pkg runtime, type BlockProfileRecord struct, embedded StackRecord", "runtime", "BlockProfileRecord.StackRecord", true},
+		{"pkg go/ast, type Foo struct, embedded *token.Pos", "go/ast", "Foo.Pos", true},
+		{"pkg io, type Reader interface, Read([]uint8) (int, error)", "io", "Reader.Read", true},
+		{"pkg os, var ErrProcessDone error", "os", "ErrProcessDone", true},
+		{"# comment", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			pkg, name, ok := apiSymbol(tt.line)
+			if pkg != tt.wantPkg || name != tt.wantName || ok != tt.wantOK {
+				t.Errorf("apiSymbol(%q) = (%q, %q, %v), want (%q, %q, %v)", tt.line, pkg, name, ok, tt.wantPkg, tt.wantName, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestReadAPIFileKeepsEarliest(t *testing.T) {
+	versions := make(map[string]string)
+	readAPIFile(strings.NewReader("pkg syscall (linux-arm), const X = 1\n"), "go1.2", versions)
+	readAPIFile(strings.NewReader("pkg syscall (linux-386), const X = 1\npkg slices, func Max\n"), "go1.21", versions)
+	readAPIFile(strings.NewReader("pkg syscall, const X = 1\n"), "go1.1", versions)
+	if got := versions["syscall X"]; got != "go1.1" {
+		t.Errorf("syscall X = %q, want go1.1", got)
+	}
+	if got := versions["slices Max"]; got != "go1.21" {
+		t.Errorf("slices Max = %q, want go1.21", got)
+	}
+}
+
+func TestHandleGetDocMinGoVersion(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":  "module example.com/compat\n\ngo 1.21\n",
+		"old.go":  "package compat\n\n// Old works everywhere.\nfunc Old() {}\n\n// Since is new.\n//\n// Available since go1.22.\nfunc Since() {}\n",
+		"iter.go": "//go:build go1.23\n\npackage compat\n\n// All iterates.\nfunc All() {}\n",
+	})
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		args["show_metadata"] = false
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	if text := call(map[string]any{"path": "slices", "target": "Clip"}); !strings.Contains(text, "Minimum Go version: go1.21 (added to the standard library in go1.21)") {
+		t.Errorf("expected go1.21 for slices.Clip:\n%s", text)
+	}
+	if text := call(map[string]any{"path": "strings", "target": "Index"}); strings.Contains(text, "Minimum Go version") {
+		t.Errorf("Go 1.0 API should not be annotated:\n%s", text)
+	}
+	if text := call(map[string]any{"path": ".", "target": "All", "working_dir": dir}); !strings.Contains(text, "Minimum Go version: go1.23 (its file has a //go:build go1.23 constraint)") {
+		t.Errorf("expected go1.23 from the build constraint:\n%s", text)
+	}
+	if text := call(map[string]any{"path": ".", "target": "Since", "working_dir": dir}); !strings.Contains(text, "Minimum Go version: go1.22 (stated in its doc comment)") {
+		t.Errorf("expected go1.22 from the doc comment:\n%s", text)
+	}
+	if text := call(map[string]any{"path": ".", "target": "Old", "working_dir": dir}); strings.Contains(text, "Minimum Go version") {
+		t.Errorf("unconstrained symbol should not be annotated:\n%s", text)
+	}
+}
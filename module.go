@@ -0,0 +1,667 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const moduleInfoDescription = `Describe the Go module in a working directory.
+Returns the module path, go and toolchain directives, required modules with
+versions (direct and indirect), replace and exclude directives, retractions,
+and a summary of go.sum. Use this to understand a project's dependencies
+without reading go.mod yourself.`
+
+const moduleLinksDescription = `Find where to read about a Go module and its releases.
+Returns the module's repository URL, its pkg.go.dev page, and the likely
+CHANGELOG, releases, and version tag URLs for GitHub, GitLab, and Bitbucket
+hosted modules. Use this when reasoning about an upgrade. The changelog and
+release locations follow host conventions and are not checked to exist.`
+
+const latestVersionDescription = `List the published versions of a Go module and report the latest.
+Runs go list -m -versions through the module proxy. Pre-releases (e.g.
+v2.0.0-rc.1) are hidden unless include_prerelease is set, matching how @latest
+prefers releases; retracted versions are never listed. With working_dir, the
+version that module currently requires is shown alongside, with the newer
+versions available. Use this before pinning or upgrading a dependency.`
+
+const selectedVersionDescription = `Explain which version of a dependency the build of a module uses, and why.
+Minimal version selection picks the highest version any module in the
+dependency graph requires, so the selected version (go list -m) can differ
+from the one the working_dir go.mod names. Returns the selected version, the
+go.mod requirement (direct or // indirect, or none), whether the selection
+was raised above it, and the modules in the graph requiring each version,
+starting with those that require the selected one. Replacements are noted.
+Use this to answer "why is this version being used?".`
+
+// maxRequirers caps how many requiring modules selected_version lists.
+const maxRequirers = 20
+
+// majorSuffix matches the major version element of a module path, e.g. "v2".
+var majorSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// pseudoVersionPattern matches the timestamp and revision suffix of a
+// pseudo-version such as v0.0.0-20240101120000-abcdef123456.
+var pseudoVersionPattern = regexp.MustCompile(`[-.]\d{14}-[0-9a-f]{12}(?:\+incompatible)?$`)
+
+// modFile mirrors the JSON printed by "go mod edit -json".
+type modFile struct {
+	Module    modVersion
+	Go        string
+	Toolchain string
+	Require   []modRequire
+	Exclude   []modVersion
+	Replace   []modReplace
+	Retract   []modRetract
+}
+
+type modVersion struct {
+	Path    string
+	Version string
+}
+
+type modRequire struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+type modReplace struct {
+	Old modVersion
+	New modVersion
+}
+
+type modRetract struct {
+	Low       string
+	High      string
+	Rationale string
+}
+
+func (v modVersion) String() string {
+	if v.Version == "" {
+		return v.Path
+	}
+	return v.Path + " " + v.Version
+}
+
+func (gs *godocServer) handleModuleInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	workingDir, err := request.RequireString("working_dir")
+	if err != nil {
+		return mcp.NewToolResultError("working_dir argument is required"), nil
+	}
+
+	info, err := os.Stat(workingDir)
+	if err != nil || !info.IsDir() {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid working directory: %s", workingDir)), nil
+	}
+	if err := gs.checkPathsAllowed("", workingDir); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	mf, err := gs.readModFile(ctx, workingDir)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// go.sum sits beside the go.mod that go mod edit found, which may be
+	// above workingDir.
+	text := formatModFile(mf)
+	if root, err := findModuleRoot(workingDir); err == nil {
+		if sum, err := summarizeGoSum(filepath.Join(root, "go.sum")); err == nil {
+			text += "\n" + sum
+		}
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// readModFile parses the go.mod in dir using "go mod edit -json".
+func (gs *godocServer) readModFile(ctx context.Context, dir string) (*modFile, error) {
+	editCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	cmd := gs.goCommand(editCtx, dir, "mod", "edit", "-json")
+
+	out, err := gs.output(editCtx, cmd)
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to read go.mod: %w\noutput: %s", err, ee.Stderr)
+		}
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+
+	var mf modFile
+	if err := json.Unmarshal(out, &mf); err != nil {
+		return nil, fmt.Errorf("failed to parse go mod edit output: %w", err)
+	}
+	return &mf, nil
+}
+
+// formatModFile renders a parsed go.mod as structured text.
+func formatModFile(mf *modFile) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "module %s\n", mf.Module.Path)
+	if mf.Go != "" {
+		fmt.Fprintf(&b, "go %s\n", mf.Go)
+	}
+	if mf.Toolchain != "" {
+		fmt.Fprintf(&b, "toolchain %s\n", mf.Toolchain)
+	}
+
+	var direct, indirect []string
+	for _, r := range mf.Require {
+		line := r.Path + " " + r.Version
+		if r.Indirect {
+			indirect = append(indirect, line)
+		} else {
+			direct = append(direct, line)
+		}
+	}
+	writeSection(&b, "Requires (direct)", direct)
+	writeSection(&b, "Requires (indirect)", indirect)
+
+	var replaces []string
+	for _, r := range mf.Replace {
+		replaces = append(replaces, r.Old.String()+" => "+r.New.String())
+	}
+	writeSection(&b, "Replaces", replaces)
+
+	var excludes []string
+	for _, e := range mf.Exclude {
+		excludes = append(excludes, e.String())
+	}
+	writeSection(&b, "Excludes", excludes)
+
+	var retracts []string
+	for _, r := range mf.Retract {
+		line := r.Low
+		if r.High != r.Low {
+			line = "[" + r.Low + ", " + r.High + "]"
+		}
+		if r.Rationale != "" {
+			line += " // " + r.Rationale
+		}
+		retracts = append(retracts, line)
+	}
+	writeSection(&b, "Retracts", retracts)
+
+	return b.String()
+}
+
+// writeSection appends a titled list to b, skipping empty lists.
+func writeSection(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n%s (%d):\n", title, len(items))
+	for _, item := range items {
+		fmt.Fprintf(b, "  %s\n", item)
+	}
+}
+
+// summarizeGoSum reports how many modules and hashes a go.sum lists.
+func summarizeGoSum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	modules := make(map[string]bool)
+	hashes := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		hashes++
+		modules[fields[0]+" "+strings.TrimSuffix(fields[1], "/go.mod")] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("go.sum: %d hashes covering %d module versions\n", hashes, len(modules)), nil
+}
+
+// moduleListing is the subset of "go list -m -json" output the module tools
+// use.
+type moduleListing struct {
+	Path     string
+	Version  string
+	Versions []string // only with -versions
+	Main     bool
+	Replace  *modVersion
+	Origin   *struct {
+		VCS string
+		URL string
+	}
+}
+
+func (gs *godocServer) handleModuleLinks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	modPath, err := request.RequireString("module")
+	if err != nil {
+		return mcp.NewToolResultError("module argument is required"), nil
+	}
+	workingDir := request.GetString("working_dir", "")
+	if workingDir != "" {
+		info, err := os.Stat(workingDir)
+		if err != nil || !info.IsDir() {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid working directory: %s", workingDir)), nil
+		}
+	}
+	if err := gs.checkPathsAllowed("", workingDir); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	bare, ver := splitVersion(modPath)
+	if err := gs.checkPackageAllowed(bare); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	listing := moduleListing{Path: bare, Version: ver}
+	if l, err := gs.listModule(ctx, workingDir, modPath); err == nil {
+		listing = *l
+	} else {
+		log.Printf("module_links: %v", err)
+	}
+	return mcp.NewToolResultText(formatModuleLinks(listing)), nil
+}
+
+func (gs *godocServer) handleLatestVersion(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	modPath, err := request.RequireString("module")
+	if err != nil {
+		return mcp.NewToolResultError("module argument is required"), nil
+	}
+	includePrerelease := request.GetBool("include_prerelease", false)
+	workingDir := request.GetString("working_dir", "")
+	if workingDir != "" {
+		info, err := os.Stat(workingDir)
+		if err != nil || !info.IsDir() {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid working directory: %s", workingDir)), nil
+		}
+	}
+	if err := gs.checkPathsAllowed("", workingDir); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	modPath, _ = splitVersion(modPath)
+	if err := gs.checkPackageAllowed(modPath); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+	cmd := gs.goCommand(listCtx, workingDir, "list", "-m", "-json", "-versions", modPath+"@latest")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := gs.output(listCtx, cmd)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("go list -m -versions %s failed: %v\noutput: %s", modPath, err, stderr.String())), nil
+	}
+	var l moduleListing
+	if err := json.Unmarshal(out, &l); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse go list -m output: %v", err)), nil
+	}
+
+	versions := l.Versions
+	if !includePrerelease {
+		versions = slices.DeleteFunc(slices.Clone(versions), isPrerelease)
+	}
+	latest := l.Version
+	if includePrerelease && len(versions) > 0 {
+		// go list sorts versions in semver order, so the last is the newest.
+		latest = versions[len(versions)-1]
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "module %s\n", l.Path)
+	if isPseudoVersion(latest) {
+		fmt.Fprintf(&b, "Latest: %s (pseudo-version; the module has no tagged releases)\n", latest)
+	} else {
+		fmt.Fprintf(&b, "Latest: %s\n", latest)
+	}
+	if cur := gs.requiredVersion(ctx, workingDir, modPath); cur == latest {
+		fmt.Fprintf(&b, "Required by working_dir: %s (up to date)\n", cur)
+	} else if cur != "" {
+		newer := 0
+		if i := slices.Index(versions, cur); i >= 0 {
+			newer = len(versions) - 1 - i
+		}
+		fmt.Fprintf(&b, "Required by working_dir: %s (%d newer versions listed)\n", cur, newer)
+	}
+	if len(versions) == 0 {
+		b.WriteString("Versions: none tagged\n")
+	} else {
+		fmt.Fprintf(&b, "Versions (%d): %s\n", len(versions), strings.Join(versions, " "))
+	}
+	if hidden := len(l.Versions) - len(versions); hidden > 0 {
+		fmt.Fprintf(&b, "(%d pre-release versions hidden; set include_prerelease to list them)\n", hidden)
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+func (gs *godocServer) handleSelectedVersion(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	modPath, err := request.RequireString("module")
+	if err != nil {
+		return mcp.NewToolResultError("module argument is required"), nil
+	}
+	workingDir, err := request.RequireString("working_dir")
+	if err != nil {
+		return mcp.NewToolResultError("working_dir argument is required"), nil
+	}
+	info, err := os.Stat(workingDir)
+	if err != nil || !info.IsDir() {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid working directory: %s", workingDir)), nil
+	}
+	if err := gs.checkPathsAllowed("", workingDir); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	modPath, _ = splitVersion(modPath)
+
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+	cmd := gs.goCommand(listCtx, workingDir, "list", "-m", "-json", modPath)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := gs.output(listCtx, cmd)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is not in the build list of the module in %s: %v\noutput: %s", modPath, workingDir, err, stderr.String())), nil
+	}
+	var l moduleListing
+	if err := json.Unmarshal(out, &l); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse go list -m output: %v", err)), nil
+	}
+	if l.Main {
+		return mcp.NewToolResultText(fmt.Sprintf("%s is the main module in %s; it has no selected version.", modPath, workingDir)), nil
+	}
+
+	mf, err := gs.readModFile(ctx, workingDir)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	requirers, err := gs.moduleRequirers(ctx, workingDir, modPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "module %s\n", modPath)
+	fmt.Fprintf(&b, "Selected: %s\n", l.Version)
+	var req *modRequire
+	for i := range mf.Require {
+		if mf.Require[i].Path == modPath {
+			req = &mf.Require[i]
+		}
+	}
+	switch {
+	case req == nil:
+		b.WriteString("go.mod: not required; it is selected only through other modules' requirements\n")
+	case req.Version == l.Version:
+		kind := "direct"
+		if req.Indirect {
+			kind = "// indirect"
+		}
+		fmt.Fprintf(&b, "go.mod: %s (%s), the selected version\n", req.Version, kind)
+	default:
+		fmt.Fprintf(&b, "go.mod: %s, raised to %s because another module in the graph requires it (run go mod tidy to record the selected version)\n", req.Version, l.Version)
+	}
+	if l.Replace != nil {
+		if l.Replace.Version == "" {
+			fmt.Fprintf(&b, "Replaced by the directory %s\n", l.Replace.Path)
+		} else {
+			fmt.Fprintf(&b, "Replaced by %s\n", l.Replace)
+		}
+	}
+
+	if len(requirers) == 0 {
+		return mcp.NewToolResultText(b.String()), nil
+	}
+	// Requirers of the selected version explain the selection; list them first.
+	selecting := slices.DeleteFunc(slices.Clone(requirers), func(r modRequire) bool { return r.Version != l.Version })
+	requirers = append(selecting, slices.DeleteFunc(requirers, func(r modRequire) bool { return r.Version == l.Version })...)
+	fmt.Fprintf(&b, "\nREQUIRED BY (%d)\n\n", len(requirers))
+	for i, r := range requirers {
+		if i == maxRequirers {
+			fmt.Fprintf(&b, "... and %d more\n", len(requirers)-maxRequirers)
+			break
+		}
+		fmt.Fprintf(&b, "%s requires %s\n", r.Path, r.Version)
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// moduleRequirers returns the modules in the module graph of workingDir that
+// require modPath, each with the version of modPath it requires, in "go mod
+// graph" order. The Path of each result is the requiring module and version.
+func (gs *godocServer) moduleRequirers(ctx context.Context, workingDir, modPath string) ([]modRequire, error) {
+	graphCtx, cancel := commandContext(ctx)
+	defer cancel()
+	out, err := gs.output(graphCtx, gs.goCommand(graphCtx, workingDir, "mod", "graph"))
+	if err != nil {
+		return nil, fmt.Errorf("go mod graph failed: %w", err)
+	}
+	var requirers []modRequire
+	for _, line := range strings.Split(string(out), "\n") {
+		from, to, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		if path, version, _ := strings.Cut(to, "@"); path == modPath {
+			requirers = append(requirers, modRequire{Path: from, Version: version})
+		}
+	}
+	return requirers, nil
+}
+
+// requiredVersion returns the version of modPath in the build list of the
+// module in workingDir, or "" if it is not required there or workingDir is
+// empty.
+func (gs *godocServer) requiredVersion(ctx context.Context, workingDir, modPath string) string {
+	if workingDir == "" {
+		return ""
+	}
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+	out, err := gs.output(listCtx, gs.goCommand(listCtx, workingDir, "list", "-m", "-f", "{{.Version}}", modPath))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// isPrerelease reports whether the semantic version v has a pre-release
+// suffix, such as v1.2.0-rc.1. Build metadata like +incompatible is ignored.
+func isPrerelease(v string) bool {
+	v, _, _ = strings.Cut(v, "+")
+	return strings.Contains(v, "-")
+}
+
+// isPseudoVersion reports whether v is a pseudo-version, which the go
+// command reports as @latest for modules without tagged releases.
+func isPseudoVersion(v string) bool {
+	return pseudoVersionPattern.MatchString(v)
+}
+
+// headQuery is the version query for the latest commit on a module's
+// default branch. get_doc accepts it in any case, as in "pkg@head".
+const headQuery = "HEAD"
+
+// headNote reports the version the go command resolved a "@HEAD" query for
+// importPath to in workingDir: a pseudo-version naming the commit, or a tag
+// when HEAD is tagged.
+func (gs *godocServer) headNote(ctx context.Context, workingDir, importPath string) string {
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+	out, err := gs.output(listCtx, gs.goCommand(listCtx, workingDir, "list", "-f", "{{with .Module}}{{.Path}}\t{{.Version}}{{end}}", importPath))
+	if err != nil {
+		return ""
+	}
+	modPath, version, ok := strings.Cut(strings.TrimSpace(string(out)), "\t")
+	if !ok || version == "" {
+		return ""
+	}
+	if isPseudoVersion(version) {
+		return fmt.Sprintf("\nNOTE: documented at the HEAD of the default branch of %s, resolved to the pseudo-version %s (unreleased; not a tagged version).\n", modPath, version)
+	}
+	return fmt.Sprintf("\nNOTE: documented at the HEAD of the default branch of %s, which is tagged %s.\n", modPath, version)
+}
+
+// replacedModuleNote reports, for a package whose module a replace directive
+// in workingDir's go.mod (or, in a go.work workspace, in go.work or any
+// workspace module's go.mod) substitutes, which version or directory the
+// documentation actually comes from. It returns "" when no replacement
+// applies.
+func (gs *godocServer) replacedModuleNote(ctx context.Context, workingDir, importPath string) string {
+	if workingDir == "" || isStdLib(importPath) {
+		return ""
+	}
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	cmd := gs.goCommand(listCtx, workingDir, "list", "-e", "-f",
+		"{{with .Module}}{{with .Replace}}{{$.Module.Path}}\t{{$.Module.Version}}\t{{.Path}}\t{{.Version}}{{end}}{{end}}", importPath)
+	out, err := gs.output(listCtx, cmd)
+	if err != nil {
+		return ""
+	}
+	fields := strings.Split(strings.TrimRight(string(out), "\r\n"), "\t")
+	if len(fields) != 4 {
+		return ""
+	}
+	original := modVersion{Path: fields[0], Version: fields[1]}
+	replacement := modVersion{Path: fields[2], Version: fields[3]}
+	// In workspace mode the replace directive may come from any workspace
+	// module's go.mod, or go.work itself; relative directories are then
+	// reported relative to the go.work file.
+	if work := workspaceFile(cmd.Env, workingDir); work != "" {
+		if replacement.Version == "" {
+			dir := replacement.Path
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(filepath.Dir(work), dir)
+			}
+			return fmt.Sprintf("\nNOTE: %s is replaced by the directory %s in the workspace %s; the documentation reflects that source.\n", original, dir, work)
+		}
+		return fmt.Sprintf("\nNOTE: %s is replaced by %s in the workspace %s; the documentation reflects the effective version %s.\n", original, replacement, work, replacement.Version)
+	}
+	if replacement.Version == "" {
+		return fmt.Sprintf("\nNOTE: %s is replaced by the directory %s in this module; the documentation reflects that source.\n", original, replacement.Path)
+	}
+	return fmt.Sprintf("\nNOTE: %s is replaced by %s in this module; the documentation reflects the effective version %s.\n", original, replacement, replacement.Version)
+}
+
+// listModule describes modPath with "go list -m -json". The version in the
+// build list of workingDir is used when modPath is required there, and the
+// latest version otherwise.
+func (gs *godocServer) listModule(ctx context.Context, workingDir, modPath string) (*moduleListing, error) {
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	query := modPath
+	if workingDir == "" && !strings.Contains(modPath, "@") {
+		query += "@latest"
+	}
+	out, err := gs.output(listCtx, gs.goCommand(listCtx, workingDir, "list", "-m", "-json", query))
+	if err != nil && workingDir != "" && !strings.Contains(modPath, "@") {
+		// Not in the build list; fall back to the latest version.
+		out, err = gs.output(listCtx, gs.goCommand(listCtx, workingDir, "list", "-m", "-json", modPath+"@latest"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("go list -m %s failed: %w", modPath, err)
+	}
+
+	var l moduleListing
+	if err := json.Unmarshal(out, &l); err != nil {
+		return nil, fmt.Errorf("failed to parse go list -m output: %w", err)
+	}
+	return &l, nil
+}
+
+// formatModuleLinks renders the repository, documentation, and release URLs
+// for a module. Repository URLs come from the module's VCS origin when known
+// and are otherwise derived from its path.
+func formatModuleLinks(l moduleListing) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "module %s\n", modVersion{Path: l.Path, Version: l.Version})
+
+	docURL := "https://pkg.go.dev/" + l.Path
+	if l.Version != "" {
+		docURL += "@" + l.Version
+	}
+	fmt.Fprintf(&b, "Documentation: %s\n", docURL)
+
+	repo, subdir := repoURL(l.Path)
+	if l.Origin != nil && l.Origin.URL != "" {
+		repo = strings.TrimSuffix(l.Origin.URL, ".git")
+	}
+	if repo == "" {
+		b.WriteString("Repository: unknown (not a recognized code host)\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Repository: %s\n", repo)
+
+	tag := l.Version
+	if subdir != "" && tag != "" {
+		tag = subdir + "/" + tag
+	}
+
+	host, _, _ := strings.Cut(strings.TrimPrefix(repo, "https://"), "/")
+	var changelog, releases, tagURL string
+	switch host {
+	case "github.com":
+		changelog = repo + "/blob/HEAD/" + path.Join(subdir, "CHANGELOG.md")
+		releases = repo + "/releases"
+		tagURL = repo + "/releases/tag/" + tag
+	case "gitlab.com":
+		changelog = repo + "/-/blob/HEAD/" + path.Join(subdir, "CHANGELOG.md")
+		releases = repo + "/-/releases"
+		tagURL = repo + "/-/tags/" + tag
+	case "bitbucket.org":
+		changelog = repo + "/src/HEAD/" + path.Join(subdir, "CHANGELOG.md")
+		tagURL = repo + "/src/" + tag
+	default:
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Changelog (guessed): %s\n", changelog)
+	if releases != "" {
+		fmt.Fprintf(&b, "Releases: %s\n", releases)
+	}
+	if tag != "" {
+		fmt.Fprintf(&b, "Release tag: %s\n", tagURL)
+	}
+	return b.String()
+}
+
+// repoURL derives a repository URL from a module path on a well-known code
+// host, along with the module's subdirectory within the repository (which
+// also prefixes its version tags). A trailing major version suffix is not
+// part of the subdirectory.
+func repoURL(modPath string) (repo, subdir string) {
+	parts := strings.Split(modPath, "/")
+	if len(parts) > 1 && majorSuffix.MatchString(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+	}
+	switch parts[0] {
+	case "github.com", "gitlab.com", "bitbucket.org":
+		if len(parts) < 3 {
+			return "", ""
+		}
+		return "https://" + strings.Join(parts[:3], "/"), strings.Join(parts[3:], "/")
+	case "golang.org":
+		if len(parts) < 3 || parts[1] != "x" {
+			return "", ""
+		}
+		return "https://github.com/golang/" + parts[2], strings.Join(parts[3:], "/")
+	}
+	return "", ""
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestFormatModFile(t *testing.T) {
+	mf := &modFile{
+		Module: modVersion{Path: "example.com/app"},
+		Go:     "1.22",
+		Require: []modRequire{
+			{Path: "example.com/a", Version: "v1.0.0"},
+			{Path: "example.com/b", Version: "v0.2.0", Indirect: true},
+		},
+		Replace: []modReplace{
+			{Old: modVersion{Path: "example.com/a"}, New: modVersion{Path: "../a"}},
+		},
+		Exclude: []modVersion{{Path: "example.com/c", Version: "v1.1.0"}},
+	}
+
+	got := formatModFile(mf)
+	for _, want := range []string{
+		"module example.com/app\n",
+		"go 1.22\n",
+		"Requires (direct) (1):\n  example.com/a v1.0.0\n",
+		"Requires (indirect) (1):\n  example.com/b v0.2.0\n",
+		"Replaces (1):\n  example.com/a => ../a\n",
+		"Excludes (1):\n  example.com/c v1.1.0\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Retracts") {
+		t.Errorf("empty section should be omitted:\n%s", got)
+	}
+}
+
+func TestSummarizeGoSum(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.sum": "example.com/a v1.0.0 h1:abc=\n" +
+			"example.com/a v1.0.0/go.mod h1:def=\n" +
+			"example.com/b v0.2.0/go.mod h1:ghi=\n",
+	})
+
+	got, err := summarizeGoSum(filepath.Join(dir, "go.sum"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(got, "3 hashes covering 2 module versions") {
+		t.Errorf("unexpected summary: %s", got)
+	}
+}
+
+func TestHandleModuleInfo(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":         "module example.com/app\n\ngo 1.21\n\nrequire example.com/dep v1.2.3\n\nreplace example.com/dep => ./dep\n",
+		"go.sum":         "example.com/a v1.0.0 h1:abc=\n",
+		"internal/db.go": "package internal\n",
+	})
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "module_info"
+	req.Params.Arguments = map[string]any{
+		"working_dir": filepath.Join(dir, "internal"),
+	}
+
+	result, err := gs.handleModuleInfo(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleModuleInfo returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleModuleInfo returned tool error: %+v", result.Content)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"module example.com/app", "example.com/dep v1.2.3", "example.com/dep => ./dep", "1 hashes covering 1 module versions"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestHandleModuleInfoMissingDir(t *testing.T) {
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "module_info"
+	req.Params.Arguments = map[string]any{}
+
+	result, err := gs.handleModuleInfo(context.Background(), req)
+	if err != nil {
+		t.Fatalf("returned protocol error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected tool error for missing working_dir")
+	}
+}
+
+func TestRepoURL(t *testing.T) {
+	tests := []struct {
+		mod, repo, subdir string
+	}{
+		{"github.com/user/repo", "https://github.com/user/repo", ""},
+		{"github.com/user/repo/v2", "https://github.com/user/repo", ""},
+		{"github.com/user/repo/sub/v3", "https://github.com/user/repo", "sub"},
+		{"gitlab.com/group/proj", "https://gitlab.com/group/proj", ""},
+		{"golang.org/x/tools/gopls", "https://github.com/golang/tools", "gopls"},
+		{"github.com/user", "", ""},
+		{"example.com/mod", "", ""},
+	}
+	for _, tt := range tests {
+		repo, subdir := repoURL(tt.mod)
+		if repo != tt.repo || subdir != tt.subdir {
+			t.Errorf("repoURL(%q) = (%q, %q), want (%q, %q)", tt.mod, repo, subdir, tt.repo, tt.subdir)
+		}
+	}
+}
+
+func TestFormatModuleLinks(t *testing.T) {
+	got := formatModuleLinks(moduleListing{Path: "golang.org/x/tools/gopls", Version: "v0.16.0"})
+	for _, want := range []string{
+		"module golang.org/x/tools/gopls v0.16.0\n",
+		"Documentation: https://pkg.go.dev/golang.org/x/tools/gopls@v0.16.0\n",
+		"Repository: https://github.com/golang/tools\n",
+		"Changelog (guessed): https://github.com/golang/tools/blob/HEAD/gopls/CHANGELOG.md\n",
+		"Releases: https://github.com/golang/tools/releases\n",
+		"Release tag: https://github.com/golang/tools/releases/tag/gopls/v0.16.0\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+
+	got = formatModuleLinks(moduleListing{Path: "example.com/mod"})
+	if !strings.Contains(got, "Repository: unknown") || strings.Contains(got, "Changelog") {
+		t.Errorf("unexpected links for unknown host:\n%s", got)
+	}
+}
+
+func TestHandleModuleLinksFromBuildList(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	// This repository requires mcp-go, so its version resolves locally.
+	wd, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "module_links"
+	req.Params.Arguments = map[string]any{
+		"module":      "github.com/mark3labs/mcp-go",
+		"working_dir": wd,
+	}
+
+	result, err := gs.handleModuleLinks(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleModuleLinks returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleModuleLinks returned tool error: %+v", result.Content)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"module github.com/mark3labs/mcp-go v0.",
+		"Repository: https://github.com/mark3labs/mcp-go\n",
+		"Release tag: https://github.com/mark3labs/mcp-go/releases/tag/v0.",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestIsPrerelease(t *testing.T) {
+	for v, want := range map[string]bool{
+		"v1.2.3":                             false,
+		"v1.2.3-rc.1":                        true,
+		"v2.0.0+incompatible":                false,
+		"v2.0.0-beta+incompatible":           true,
+		"v0.0.0-20240101120000-abcdef123456": true,
+	} {
+		if got := isPrerelease(v); got != want {
+			t.Errorf("isPrerelease(%q) = %v, want %v", v, got, want)
+		}
+	}
+	if !isPseudoVersion("v0.0.0-20240101120000-abcdef123456") || isPseudoVersion("v1.2.3-rc.1") {
+		t.Error("isPseudoVersion misclassified a version")
+	}
+}
+
+func TestHandleLatestVersion(t *testing.T) {
+	fakeGo(t, `case "$*" in
+"list -m -json -versions example.com/m@latest")
+	echo '{"Path": "example.com/m", "Version": "v1.2.0", "Versions": ["v1.0.0", "v1.1.0", "v1.2.0", "v1.3.0-rc.1"]}' ;;
+"list -m -f {{.Version}} example.com/m")
+	echo v1.0.0 ;;
+*)
+	echo "unexpected: $*" >&2; exit 1 ;;
+esac
+`)
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "latest_version"
+		req.Params.Arguments = args
+		result, err := gs.handleLatestVersion(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleLatestVersion returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleLatestVersion returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := call(map[string]any{"module": "example.com/m"})
+	for _, want := range []string{
+		"Latest: v1.2.0\n",
+		"Versions (3): v1.0.0 v1.1.0 v1.2.0\n",
+		"(1 pre-release versions hidden; set include_prerelease to list them)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Required by") {
+		t.Errorf("no working_dir, so no required version expected:\n%s", text)
+	}
+
+	text = call(map[string]any{"module": "example.com/m", "include_prerelease": true, "working_dir": t.TempDir()})
+	for _, want := range []string{
+		"Latest: v1.3.0-rc.1\n",
+		"Required by working_dir: v1.0.0 (3 newer versions listed)\n",
+		"Versions (4): v1.0.0 v1.1.0 v1.2.0 v1.3.0-rc.1\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestReplacedModuleNote(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":         "module example.com/app\n\ngo 1.21\n\nrequire example.com/dep v1.0.0\n\nreplace example.com/dep v1.0.0 => ./patched\n",
+		"app.go":         "package app\n\nimport _ \"example.com/dep\"\n",
+		"patched/go.mod": "module example.com/dep\n\ngo 1.21\n",
+		"patched/dep.go": "// Package dep is the patched copy.\npackage dep\n",
+	})
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_doc"
+	req.Params.Arguments = map[string]any{"path": "example.com/dep", "working_dir": dir}
+	result, err := gs.handleGetDoc(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleGetDoc returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"Package dep is the patched copy.", "NOTE: example.com/dep v1.0.0 is replaced by the directory ./patched"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	if note := gs.replacedModuleNote(context.Background(), dir, "example.com/app"); note != "" {
+		t.Errorf("main module has no replacement, got %q", note)
+	}
+}
+
+func TestReplacedModuleNoteVersion(t *testing.T) {
+	fakeGo(t, `printf 'example.com/dep\tv1.0.0\texample.com/dep\tv1.0.1\n'`)
+	note := newGodocServer().replacedModuleNote(context.Background(), t.TempDir(), "example.com/dep")
+	want := "NOTE: example.com/dep v1.0.0 is replaced by example.com/dep v1.0.1 in this module; the documentation reflects the effective version v1.0.1."
+	if !strings.Contains(note, want) {
+		t.Errorf("note = %q, want %q", note, want)
+	}
+}
+
+func TestHandleSelectedVersion(t *testing.T) {
+	dir := t.TempDir()
+	fakeGo(t, `case "$*" in
+"list -m -json example.com/dep")
+	echo '{"Path": "example.com/dep", "Version": "v1.4.0"}' ;;
+"list -m -json example.com/app")
+	echo '{"Path": "example.com/app", "Main": true}' ;;
+"mod edit -json")
+	echo '{"Module": {"Path": "example.com/app"}, "Require": [{"Path": "example.com/dep", "Version": "v1.2.0"}, {"Path": "example.com/lib", "Version": "v0.3.0"}]}' ;;
+"mod graph")
+	printf '%s\n' "example.com/app example.com/dep@v1.2.0" "example.com/app example.com/lib@v0.3.0" "example.com/lib@v0.3.0 example.com/dep@v1.4.0" "example.com/lib@v0.3.0 example.com/other@v1.0.0" ;;
+*)
+	echo "unexpected: $*" >&2; exit 1 ;;
+esac
+`)
+	gs := newGodocServer()
+	call := func(module string) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "selected_version"
+		req.Params.Arguments = map[string]any{"module": module, "working_dir": dir}
+		result, err := gs.handleSelectedVersion(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleSelectedVersion returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleSelectedVersion returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := call("example.com/dep")
+	for _, want := range []string{
+		"Selected: v1.4.0\n",
+		"go.mod: v1.2.0, raised to v1.4.0 because another module in the graph requires it",
+		"REQUIRED BY (2)\n\nexample.com/lib@v0.3.0 requires v1.4.0\nexample.com/app requires v1.2.0\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	if text := call("example.com/app"); !strings.Contains(text, "is the main module") {
+		t.Errorf("main module not recognized:\n%s", text)
+	}
+}
+
+func TestHandleGetDocHead(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "log")
+	fakeGo(t, `echo "$*" >> "`+log+`"
+case "$*" in
+"get example.com/dep@HEAD")
+	;;
+"doc example.com/dep")
+	echo "package dep // import \"example.com/dep\""
+	echo
+	echo "Package dep is unreleased." ;;
+"list -f "*" example.com/dep")
+	printf 'example.com/dep\tv0.4.1-0.20261014093000-0123456789ab\n' ;;
+esac
+`)
+	gs := newGodocServer()
+	defer gs.cleanup()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_doc"
+	req.Params.Arguments = map[string]any{"path": "example.com/dep@head"}
+	result, err := gs.handleGetDoc(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleGetDoc returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"Package dep is unreleased.", "NOTE: documented at the HEAD of the default branch of example.com/dep, resolved to the pseudo-version v0.4.1-0.20261014093000-0123456789ab"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+	calls, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(calls), "get example.com/dep@HEAD\n") {
+		t.Errorf("expected go get at HEAD, got calls:\n%s", calls)
+	}
+}
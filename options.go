@@ -0,0 +1,251 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+// serverOption configures optional godocServer behavior.
+type serverOption func(*godocServer)
+
+// withTempModule sets the module path used for temporary projects and,
+// when goVersion is non-empty, pins their go directive instead of deriving
+// it from the fetched dependency.
+func withTempModule(name, goVersion string) serverOption {
+	return func(gs *godocServer) {
+		if name != "" {
+			gs.tempModule = name
+		}
+		gs.tempGoVersion = goVersion
+	}
+}
+
+// withSessionCache scopes cached documentation to the requesting client
+// session rather than sharing it across all clients.
+func withSessionCache(enabled bool) serverOption {
+	return func(gs *godocServer) {
+		gs.sessionCache = enabled
+	}
+}
+
+// withMaxConcurrent caps how many go subprocesses may run at once across all
+// requests. Values below 1 keep the default of one per CPU.
+func withMaxConcurrent(n int) serverOption {
+	return func(gs *godocServer) {
+		if n > 0 {
+			gs.pool = newProcessPool(n)
+		}
+	}
+}
+
+// withMaxTempProjects caps how many temporary project directories may exist
+// on disk at once. Values below 1 keep the default.
+func withMaxTempProjects(n int) serverOption {
+	return func(gs *godocServer) {
+		if n > 0 {
+			gs.projectSlots = make(chan struct{}, n)
+		}
+	}
+}
+
+// withDenyPackages rejects requests for import paths matching any of the
+// given patterns before any go command runs.
+func withDenyPackages(patterns []string) serverOption {
+	return func(gs *godocServer) {
+		gs.denyPackages = patterns
+	}
+}
+
+// withAllowedRoots restricts working directories and local package paths to
+// the given directories and their subdirectories. Roots are canonicalized
+// once here; one that does not exist yet is kept as given, so a typo denies
+// access rather than allowing it.
+func withAllowedRoots(roots []string) serverOption {
+	return func(gs *godocServer) {
+		for _, root := range roots {
+			abs, err := filepath.Abs(root)
+			if err != nil {
+				abs = filepath.Clean(root)
+			}
+			if real, err := filepath.EvalSymlinks(abs); err == nil {
+				abs = real
+			} else {
+				log.Printf("Allowed root %s cannot be resolved: %v", root, err)
+			}
+			gs.allowedRoots = append(gs.allowedRoots, abs)
+		}
+	}
+}
+
+// withResponsePreamble prefixes every get_doc text result with preamble as
+// its own line. "{version}" in preamble is replaced with the server version.
+func withResponsePreamble(preamble string) serverOption {
+	return func(gs *godocServer) {
+		gs.responsePreamble = strings.ReplaceAll(preamble, "{version}", version)
+	}
+}
+
+// withCleanEnv runs go subprocesses with a minimal environment (PATH, HOME,
+// GOPATH, GOCACHE, GOMODCACHE) instead of inheriting the server's, so stray
+// GO* variables cannot change the output.
+func withCleanEnv(enabled bool) serverOption {
+	return func(gs *godocServer) {
+		if !enabled {
+			return
+		}
+		gs.cleanEnv = buildCleanEnv()
+		log.Printf("Running go commands with a clean environment: %s", strings.Join(gs.cleanEnv, " "))
+	}
+}
+
+// withIncludeStderr appends anything go doc writes to standard error, such
+// as warnings, to the documentation it returns, rather than only logging it.
+func withIncludeStderr(enabled bool) serverOption {
+	return func(gs *godocServer) {
+		gs.includeStderr = enabled
+	}
+}
+
+// withDisassembly registers the disassemble tool, which compiles local
+// packages to show their generated assembly.
+func withDisassembly(enabled bool) serverOption {
+	return func(gs *godocServer) {
+		gs.disassembly = enabled
+	}
+}
+
+// withGitTools registers the api_diff tool, which runs git to read other
+// revisions of local packages.
+func withGitTools(enabled bool) serverOption {
+	return func(gs *godocServer) {
+		gs.gitTools = enabled
+	}
+}
+
+// withTestTools registers the test_coverage tool, which runs the tests of
+// local packages.
+func withTestTools(enabled bool) serverOption {
+	return func(gs *godocServer) {
+		gs.testTools = enabled
+	}
+}
+
+// withGoGet lets get_doc run go get under mod_mode=mod to require a module
+// that is only in the pruned module graph, editing the working_dir module's
+// go.mod and go.sum. get_doc is then no longer advertised as read-only.
+func withGoGet(enabled bool) serverOption {
+	return func(gs *godocServer) {
+		gs.goGet = enabled
+	}
+}
+
+// withTools registers only the named tools, so an instance can expose a
+// subset such as get_doc and list_symbols. An empty list registers all.
+func withTools(names []string) serverOption {
+	return func(gs *godocServer) {
+		if len(names) == 0 {
+			return
+		}
+		gs.tools = make(map[string]bool, len(names))
+		for _, name := range names {
+			gs.tools[name] = true
+		}
+	}
+}
+
+// withCacheShards splits the documentation cache into n independently locked
+// shards. Values below 1 keep the default.
+func withCacheShards(n int) serverOption {
+	return func(gs *godocServer) {
+		if n > 0 {
+			gs.cache = newDocCache(n)
+		}
+	}
+}
+
+// withParseCacheSize keeps the parses of up to n package directories for the
+// parse-based tools to share. 0 disables the cache; negative values keep the
+// default.
+func withParseCacheSize(n int) serverOption {
+	return func(gs *godocServer) {
+		if n >= 0 {
+			gs.parseCache = newParseCache(n)
+		}
+	}
+}
+
+// withGoToolchain sets GOTOOLCHAIN for go subprocesses, e.g. "local" to
+// never switch toolchains, "auto" to follow each module's go and toolchain
+// lines, or "go1.22.5" to pin one. Empty inherits the server's environment.
+func withGoToolchain(toolchain string) serverOption {
+	return func(gs *godocServer) {
+		gs.goToolchain = toolchain
+	}
+}
+
+// withNoToolchainDownload, when on, sets GOTOOLCHAIN=local for go
+// subprocesses, so the go command never downloads a toolchain and a module
+// needing a newer one fails with errToolchain instead. It overrides
+// withGoToolchain.
+func withNoToolchainDownload(on bool) serverOption {
+	return func(gs *godocServer) {
+		if on {
+			gs.goToolchain = "local"
+		}
+	}
+}
+
+// withArgLimits caps the length in bytes of string arguments and the number
+// of items in array arguments that tools accept. Values below 1 keep the
+// defaults.
+func withArgLimits(maxLength, maxItems int) serverOption {
+	return func(gs *godocServer) {
+		if maxLength > 0 {
+			gs.maxArgLength = maxLength
+		}
+		if maxItems > 0 {
+			gs.maxArrayLength = maxItems
+		}
+	}
+}
+
+// withModCache points GOMODCACHE for go subprocesses at dir, so modules that
+// temporary projects fetch land in a dedicated cache instead of the user's.
+// A relative dir is made absolute, as the go command requires. Empty keeps
+// the environment's cache.
+func withModCache(dir string) serverOption {
+	return func(gs *godocServer) {
+		if dir == "" {
+			return
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			log.Printf("Ignoring -modcache %q: %v", dir, err)
+			return
+		}
+		gs.modCache = abs
+		log.Printf("Using module cache %s", abs)
+	}
+}
+
+// withWarmup warms the build cache at startup by running go command ./...
+// in each of dirs, where command is build or vet. An unknown command falls
+// back to build.
+func withWarmup(dirs []string, command string) serverOption {
+	return func(gs *godocServer) {
+		if !warmupCommands[command] {
+			log.Printf("Unknown -warmup-command %q; using build", command)
+			command = "build"
+		}
+		gs.warmupCommand = command
+		for _, dir := range dirs {
+			abs, err := filepath.Abs(dir)
+			if err != nil {
+				log.Printf("Ignoring warmup directory %s: %v", dir, err)
+				continue
+			}
+			gs.warmupDirs = append(gs.warmupDirs, abs)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const listPanicsDescription = `List the exported functions and methods of a Go package that may panic:
+those whose doc comment says so ("panics if", "will panic", ...) and those
+named by the MustXxx convention, which panic instead of returning an error.
+Each is shown with its signature, the sentence documenting the panic, and the
+non-panicking variant when the package has one (Compile for MustCompile).
+Use this to choose the variant that returns an error, or to know where a
+recover or input validation is needed.`
+
+// panicDocPattern matches doc comment phrases stating that a function panics,
+// including a sentence that ends with it, as in "If n is negative, Grow
+// panics.", or goes on to name the panic value, as in "panics with ErrTooLarge".
+var panicDocPattern = regexp.MustCompile(`(?i)\b(?:panics?\s+(?:if|when|unless|on|for|with)\b|panics\s*(?:[.;:]|$)|(?:will|may|might|can|could|would)\s+panic\b|it\s+panics\b|causes?\s+a\s+panic\b)`)
+
+// panicInfo explains why a function may panic.
+type panicInfo struct {
+	reason      string // the documenting sentence, or the naming convention
+	alternative string // the non-panicking variant, or ""
+}
+
+func (gs *godocServer) handleListPanics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var b strings.Builder
+	count := 0
+	for _, sym := range packageSymbols(dp) {
+		if !ast.IsExported(lastName(sym.name)) {
+			continue
+		}
+		info, ok := panicProne(dp, sym)
+		if !ok {
+			continue
+		}
+		count++
+		b.WriteString(symbolSignature(lp.fset, sym) + "\n")
+		b.WriteString("    " + info.reason + "\n")
+		if info.alternative != "" {
+			fmt.Fprintf(&b, "    Non-panicking variant: %s\n", info.alternative)
+		}
+		b.WriteString("\n")
+	}
+	if count == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No exported functions in %s are documented or named as panicking", pkgPath)), nil
+	}
+	header := fmt.Sprintf("%d exported functions in %s may panic\n\n", count, pkgPath)
+	return mcp.NewToolResultText(header + strings.TrimRight(b.String(), "\n")), nil
+}
+
+// panicNote warns that the function or method target of the package at
+// importPath may panic, and names its non-panicking variant. It returns ""
+// for other symbols.
+func (gs *godocServer) panicNote(ctx context.Context, workingDir, importPath, target string) string {
+	lp, err := gs.loadPackage(ctx, workingDir, importPath)
+	if err != nil {
+		return ""
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return ""
+	}
+	sym, ok := findSymbol(dp, target)
+	if !ok {
+		return ""
+	}
+	info, ok := panicProne(dp, sym)
+	if !ok {
+		return ""
+	}
+	note := fmt.Sprintf("\nWARNING: %s may panic: %s\n", target, info.reason)
+	if info.alternative != "" {
+		note += fmt.Sprintf("Use %s to get an error instead.\n", info.alternative)
+	}
+	return note
+}
+
+// panicProne reports whether the function or method sym may panic, either
+// because its doc comment says so or because its name follows the MustXxx
+// convention.
+func panicProne(dp *doc.Package, sym docSymbol) (panicInfo, bool) {
+	if sym.kind != "func" && sym.kind != "method" {
+		return panicInfo{}, false
+	}
+	var info panicInfo
+	info.reason = panicSentence(sym.doc)
+
+	name := lastName(sym.name)
+	if rest, ok := strings.CutPrefix(name, "Must"); ok && (rest == "" || startsUpper(rest)) {
+		if info.reason == "" {
+			info.reason = "Named by the MustXxx convention: it panics instead of returning an error."
+		}
+		if rest != "" {
+			alt := strings.TrimSuffix(sym.name, name) + rest
+			if other, ok := findSymbol(dp, alt); ok && (other.kind == "func" || other.kind == "method") {
+				info.alternative = alt
+			}
+		}
+	}
+	return info, info.reason != ""
+}
+
+// panicSentence returns the first sentence of docText stating that the
+// function panics, or "". Sentences about recovering from panics are not
+// statements that it panics.
+func panicSentence(docText string) string {
+	for _, sentence := range sentences(docText) {
+		if panicDocPattern.MatchString(sentence) && !strings.Contains(strings.ToLower(sentence), "recover") {
+			return sentence
+		}
+	}
+	return ""
+}
+
+// startsUpper reports whether s begins with an upper-case letter or a digit,
+// as the word after "Must" in a MustXxx name does.
+func startsUpper(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsUpper(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPanicSentence(t *testing.T) {
+	tests := map[string]string{
+		"Sqrt returns the square root. It panics if x is negative.":          "It panics if x is negative.",
+		"Get returns the value.\n\nGet will panic\nwhen the key is missing.": "Get will panic when the key is missing.",
+		"Wrap returns a handler. Panics in h are recovered when they occur.": "",
+		"Do does it.":                            "",
+		"MustDo is like Do but panics on error.": "MustDo is like Do but panics on error.",
+		"Grow grows b's capacity, if necessary, to guarantee space for another n bytes. After Grow(n), at least n bytes can be written to b without another allocation. If n is negative, Grow panics.": "If n is negative, Grow panics.",
+		"Write appends p. If the buffer becomes too large, Write panics with ErrTooLarge.":                                                                                                              "If the buffer becomes too large, Write panics with ErrTooLarge.",
+	}
+	for in, want := range tests {
+		if got := panicSentence(in); got != want {
+			t.Errorf("panicSentence(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestListPanics(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/risky\n\ngo 1.21\n",
+		"risky.go": `// Package risky has functions that panic.
+package risky
+
+// Parse parses s.
+func Parse(s string) (int, error) { return 0, nil }
+
+// MustParse is like Parse but does not return an error.
+func MustParse(s string) int { return 0 }
+
+// Must returns v, and panics if err is non-nil.
+func Must(v int, err error) int { return v }
+
+// Div divides a by b. It panics when b is zero.
+func Div(a, b int) int { return a / b }
+
+// Mustang is a car, not a Must function.
+func Mustang() {}
+
+// Safe never fails.
+func Safe() {}
+
+// Pool holds values.
+type Pool struct{}
+
+// Get returns a value.
+func (p *Pool) Get() (int, error) { return 0, nil }
+
+// MustGet returns a value.
+func (p *Pool) MustGet() int { return 0 }
+`,
+	})
+
+	gs := newGodocServer()
+	defer gs.cleanup()
+	ctx := context.Background()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "list_panics"
+	req.Params.Arguments = map[string]any{"path": ".", "working_dir": dir}
+	result, err := gs.handleListPanics(ctx, req)
+	if err != nil {
+		t.Fatalf("handleListPanics returned protocol error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %s", text)
+	}
+	for _, want := range []string{
+		"4 exported functions in example.com/risky may panic",
+		"func MustParse(s string) int\n    Named by the MustXxx convention: it panics instead of returning an error.\n    Non-panicking variant: Parse",
+		"func Must(v int, err error) int\n    Must returns v, and panics if err is non-nil.\n",
+		"func Div(a, b int) int\n    It panics when b is zero.",
+		"func (p *Pool) MustGet() int\n    Named by the MustXxx convention: it panics instead of returning an error.\n    Non-panicking variant: Pool.Get",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"Mustang", "Safe", "Non-panicking variant: Must\n"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("unexpected %q in:\n%s", unwanted, text)
+		}
+	}
+
+	// list_symbols flags the same functions.
+	req.Params.Name = "list_symbols"
+	result, err = gs.handleListSymbols(ctx, req)
+	if err != nil {
+		t.Fatalf("handleListSymbols returned protocol error: %v", err)
+	}
+	text = result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"(0 deprecated, 0 experimental, 4 may panic)", "func Div(a, b int) int  [may panic]"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("list_symbols: missing %q in:\n%s", want, text)
+		}
+	}
+
+	// get_doc warns about a panicking target and names the variant.
+	if note := gs.panicNote(ctx, dir, "example.com/risky", "MustParse"); !strings.Contains(note, "WARNING: MustParse may panic") || !strings.Contains(note, "Use Parse to get an error instead.") {
+		t.Errorf("panicNote(MustParse) = %q", note)
+	}
+	for _, target := range []string{"Safe", "Pool", "Mustang"} {
+		if note := gs.panicNote(ctx, dir, "example.com/risky", target); note != "" {
+			t.Errorf("panicNote(%s) = %q, want none", target, note)
+		}
+	}
+}
+
+func TestGetDocPanicWarning(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_doc"
+	req.Params.Arguments = map[string]any{"path": "regexp", "target": "MustCompile"}
+	result, err := gs.handleGetDoc(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleGetDoc returned protocol error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "WARNING: MustCompile may panic") || !strings.Contains(text, "Use Compile to get an error instead.") {
+		t.Errorf("expected a panic warning naming Compile, got:\n%s", text)
+	}
+}
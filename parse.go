@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// loadedPackage is the parsed (and optionally type-checked) source of a
+// single package directory.
+type loadedPackage struct {
+	path       string
+	dir        string
+	workingDir string
+	fset       *token.FileSet
+	files      []*ast.File
+	sFiles     []string
+	pkg        *types.Package
+	info       *types.Info
+	doc        *doc.Package
+	generated  map[string]bool // files with a "Code generated ... DO NOT EDIT." marker
+	shared     *sharedDoc      // set when the AST is shared with the parse cache
+}
+
+// packageDir returns the source directory of importPath as seen from workingDir.
+func (gs *godocServer) packageDir(ctx context.Context, workingDir, importPath string) (string, error) {
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	// -e keeps go list from failing on packages whose files are all excluded
+	// by build constraints; their directory is still reported.
+	cmd := gs.goCommand(listCtx, workingDir, "list", "-e", "-f", "{{.Dir}}", importPath)
+
+	out, err := gs.combinedOutput(listCtx, cmd)
+	if err != nil {
+		return "", fmt.Errorf("go list failed: %w\noutput: %s", err, string(out))
+	}
+
+	dir := strings.TrimSpace(string(out))
+	if dir == "" {
+		return "", fmt.Errorf("no source directory found for %s", importPath)
+	}
+	return dir, nil
+}
+
+// loadPackage locates and parses importPath as seen from workingDir.
+func (gs *godocServer) loadPackage(ctx context.Context, workingDir, importPath string) (*loadedPackage, error) {
+	dir, err := gs.packageDir(ctx, workingDir, importPath)
+	if err != nil {
+		return nil, err
+	}
+	lp, err := gs.parseDir(ctx, workingDir, importPath, dir)
+	if err != nil {
+		return nil, err
+	}
+	lp.path = importPath
+	lp.workingDir = workingDir
+	gs.rememberPackage(ctx, workingDir, importPath, dir)
+	return lp, nil
+}
+
+// allFilesExcluded reports whether importPath has Go files but build
+// constraints exclude all of them for the current platform.
+func (gs *godocServer) allFilesExcluded(ctx context.Context, workingDir, importPath string) bool {
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	cmd := gs.goCommand(listCtx, workingDir, "list", "-e", "-f", "{{len .GoFiles}} {{len .CgoFiles}} {{len .IgnoredGoFiles}}", importPath)
+	out, err := gs.output(listCtx, cmd)
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(out)) != "0 0 0" && strings.HasPrefix(string(out), "0 0 ")
+}
+
+// parsePackage parses the non-test Go files in dir that match the current
+// build context, keeping comments.
+func parsePackage(dir string) (*loadedPackage, error) {
+	return parsePackageContext(&build.Default, dir)
+}
+
+// parsePackageContext is parsePackage for the build context ctxt.
+func parsePackageContext(ctxt *build.Context, dir string) (*loadedPackage, error) {
+	bp, err := ctxt.ImportDir(dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package in %s: %w", dir, err)
+	}
+
+	lp := &loadedPackage{dir: dir, fset: token.NewFileSet(), sFiles: bp.SFiles}
+	names := append(append([]string{}, bp.GoFiles...), bp.CgoFiles...)
+	for _, name := range names {
+		f, err := parser.ParseFile(lp.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		lp.files = append(lp.files, f)
+	}
+	lp.generated = generatedFiles(lp.fset, lp.files)
+	return lp, nil
+}
+
+// parsePackageIgnoringConstraints parses every non-test Go file in dir,
+// regardless of build constraints. When files disagree on the package name,
+// the most common name wins and the others (typically ignored generators) are
+// dropped.
+func parsePackageIgnoringConstraints(dir string) (*loadedPackage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	fset := token.NewFileSet()
+	byName := make(map[string][]*ast.File)
+	best := ""
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		pkgName := f.Name.Name
+		byName[pkgName] = append(byName[pkgName], f)
+		if best == "" || len(byName[pkgName]) > len(byName[best]) {
+			best = pkgName
+		}
+	}
+	if best == "" {
+		return nil, fmt.Errorf("no parseable Go files in %s", dir)
+	}
+
+	files := byName[best]
+	return &loadedPackage{dir: dir, fset: fset, files: files, generated: generatedFiles(fset, files)}, nil
+}
+
+// generatedFiles returns the names of the files carrying the standard
+// "Code generated ... DO NOT EDIT." marker.
+func generatedFiles(fset *token.FileSet, files []*ast.File) map[string]bool {
+	generated := make(map[string]bool)
+	for _, f := range files {
+		if ast.IsGenerated(f) {
+			generated[fset.Position(f.Package).Filename] = true
+		}
+	}
+	return generated
+}
+
+// isGenerated reports whether pos lies in a generated file.
+func (lp *loadedPackage) isGenerated(pos token.Pos) bool {
+	return lp.generated[lp.fset.Position(pos).Filename]
+}
+
+// docPackage returns the go/doc view of the package, computing it on first
+// use. go/doc removes unexported declarations and fields from lp.files even
+// with PreserveAST, which only keeps function bodies and comments, so code
+// that needs them must read the files first. Type-checking the filtered
+// files afterwards, as describe_interface does, tolerates the errors this
+// causes and still resolves the exported API.
+func (lp *loadedPackage) docPackage() (*doc.Package, error) {
+	if lp.doc != nil {
+		return lp.doc, nil
+	}
+	if lp.shared != nil {
+		files, dp, err := lp.shared.get(lp)
+		if err != nil {
+			return nil, err
+		}
+		// As when go/doc filters a private parse, the files from now on are
+		// the ones the documentation's declarations point into.
+		lp.files, lp.doc, lp.pkg, lp.info = files, dp, nil, nil
+		return dp, nil
+	}
+	dp, err := doc.NewFromFiles(lp.fset, lp.files, lp.path, doc.PreserveAST)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute documentation for %s: %w", lp.path, err)
+	}
+	lp.doc = dp
+	return dp, nil
+}
+
+// typeCheck type-checks the package from source, importing dependencies from
+// the export data the go command produces for them. Errors are tolerated so
+// that partially broken packages still yield usable type information.
+func (gs *godocServer) typeCheck(ctx context.Context, lp *loadedPackage) error {
+	if lp.pkg != nil {
+		return nil
+	}
+	if len(lp.files) == 0 {
+		return fmt.Errorf("no Go files in %s", lp.dir)
+	}
+
+	exports, err := gs.exportData(ctx, lp.workingDir, lp.path)
+	if err != nil {
+		return err
+	}
+	lp.info = &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{
+		Importer:    exportImporter(lp.fset, exports),
+		FakeImportC: true,
+		Error:       func(error) {},
+	}
+	pkg, _ := conf.Check(lp.path, lp.fset, lp.files, lp.info)
+	if pkg == nil {
+		return fmt.Errorf("failed to type-check %s", lp.dir)
+	}
+	lp.pkg = pkg
+	return nil
+}
+
+// exportImporter returns an importer that reads packages from the export data
+// files in exports, as produced by listExports.
+func exportImporter(fset *token.FileSet, exports map[string]string) types.Importer {
+	return importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		file, ok := exports[path]
+		if !ok || file == "" {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(file)
+	})
+}
+
+// exportData maps each dependency of importPath to its compiled export data
+// file, building dependencies as needed.
+func (gs *godocServer) exportData(ctx context.Context, workingDir, importPath string) (map[string]string, error) {
+	exports, _, err := gs.listExports(ctx, workingDir, importPath)
+	return exports, err
+}
+
+// listExports maps every package matching patterns, and all their
+// dependencies, to its compiled export data file. It also returns the import
+// paths that matched the patterns themselves.
+func (gs *godocServer) listExports(ctx context.Context, workingDir string, patterns ...string) (map[string]string, []string, error) {
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	args := append([]string{"list", "-e", "-export", "-deps", "-f", "{{.ImportPath}}\t{{.Export}}\t{{.DepOnly}}"}, patterns...)
+	out, err := gs.output(listCtx, gs.goCommand(listCtx, workingDir, args...))
+	if err != nil {
+		return nil, nil, fmt.Errorf("go list -export failed: %w", err)
+	}
+
+	exports := make(map[string]string)
+	var roots []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		exports[fields[0]] = fields[1]
+		if fields[2] == "false" {
+			roots = append(roots, fields[0])
+		}
+	}
+	return exports, roots, nil
+}
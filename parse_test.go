@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+)
+
+func TestParsePackage(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"a.go":      "package demo\n\n// A is exported.\nfunc A() {}\n",
+		"b_test.go": "package demo\n\nfunc helper() {}\n",
+		"c.go":      "//go:build ignore\n\npackage demo\n\nfunc C() {}\n",
+	})
+
+	lp, err := parsePackage(dir)
+	if err != nil {
+		t.Fatalf("parsePackage: %v", err)
+	}
+	if len(lp.files) != 1 {
+		t.Fatalf("got %d files, want 1 (tests and ignored files excluded)", len(lp.files))
+	}
+	if lp.files[0].Comments == nil {
+		t.Error("expected comments to be retained")
+	}
+}
+
+func TestParsePackageNoGoFiles(t *testing.T) {
+	if _, err := parsePackage(t.TempDir()); err == nil {
+		t.Fatal("expected error for directory without Go files")
+	}
+}
+
+func TestTypeCheckStdlib(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer()
+	ctx := context.Background()
+
+	lp, err := gs.loadPackage(ctx, "", "bufio")
+	if err != nil {
+		t.Fatalf("loadPackage: %v", err)
+	}
+	if err := gs.typeCheck(ctx, lp); err != nil {
+		t.Fatalf("typeCheck: %v", err)
+	}
+	if lp.pkg.Scope().Lookup("Reader") == nil {
+		t.Error("expected bufio.Reader in package scope")
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultParseCacheSize is how many parsed package directories the server
+// keeps unless configured otherwise.
+const defaultParseCacheSize = 100
+
+// parseCache holds parses of package directories so the parse-based tools
+// share one parse of a package until its source changes. Callers receive
+// shallow copies that share the cached AST, which must therefore not be
+// modified: go/doc works on a parse of its own (see sharedDoc), and tools
+// that edit the AST take a private parse (see loadedPackage.editable).
+type parseCache struct {
+	mu       sync.Mutex
+	entries  map[string]*cachedParse
+	capacity int
+}
+
+type cachedParse struct {
+	lp       *loadedPackage // handed out as shallow copies
+	lastUsed time.Time
+}
+
+// newParseCache returns an empty cache holding up to capacity packages, or
+// nil, which disables caching, when capacity is below 1.
+func newParseCache(capacity int) *parseCache {
+	if capacity < 1 {
+		return nil
+	}
+	return &parseCache{entries: make(map[string]*cachedParse), capacity: capacity}
+}
+
+// get returns a copy of the package cached under key.
+func (c *parseCache) get(key string) (*loadedPackage, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok {
+		entry.lastUsed = time.Now()
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	cp := *entry.lp
+	return &cp, true
+}
+
+// put caches lp under key, evicting the least recently used entry of a full
+// cache. lp's AST must not be modified afterwards.
+func (c *parseCache) put(key string, lp *loadedPackage) {
+	entry := &cachedParse{lp: lp, lastUsed: time.Now()}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.capacity {
+		var oldestKey string
+		var oldestTime time.Time
+		for k, v := range c.entries {
+			if oldestKey == "" || v.lastUsed.Before(oldestTime) {
+				oldestKey = k
+				oldestTime = v.lastUsed
+			}
+		}
+		delete(c.entries, oldestKey)
+	}
+	c.entries[key] = entry
+}
+
+// len returns the number of cached packages.
+func (c *parseCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// parseDir parses the package importPath found in dir under the request's
+// build context, reusing the cached parse when the package's source is
+// unchanged. The key covers the import path, the directory (which, in the
+// module cache, names the module version), the -mod mode and GOEXPERIMENT,
+// and the source fingerprint, so edits are never served stale.
+func (gs *godocServer) parseDir(ctx context.Context, workingDir, importPath, dir string) (*loadedPackage, error) {
+	ctxt := &build.Default
+	if exp := goExperiment(ctx); exp != "" {
+		ctxt = experimentContext(exp)
+	}
+	if gs.parseCache == nil {
+		return parsePackageContext(ctxt, dir)
+	}
+	hash, err := sourceHash(dir)
+	if err != nil {
+		return parsePackageContext(ctxt, dir)
+	}
+
+	key := gs.docCacheKey(ctx, workingDir, []string{"ast", importPath, dir}) + "|src=" + hash
+	if !noCache(ctx) {
+		if lp, ok := gs.parseCache.get(key); ok {
+			log.Printf("Parse cache hit for %s", importPath)
+			return lp, nil
+		}
+	}
+	lp, err := parsePackageContext(ctxt, dir)
+	if err != nil {
+		return nil, err
+	}
+	lp.shared = &sharedDoc{}
+	gs.parseCache.put(key, lp)
+	cp := *lp
+	return &cp, nil
+}
+
+// sharedDoc is the go/doc view of a cached parse. go/doc filters the AST it
+// is given, so it is computed at most once, on a parse of its own.
+type sharedDoc struct {
+	once  sync.Once
+	files []*ast.File // the parse go/doc filtered
+	doc   *doc.Package
+	err   error
+}
+
+// get returns the documentation of lp, a copy of the cached parse sd belongs
+// to, along with the filtered files its declarations point into.
+func (sd *sharedDoc) get(lp *loadedPackage) ([]*ast.File, *doc.Package, error) {
+	sd.once.Do(func() {
+		files, err := lp.reparse(lp.fset)
+		if err != nil {
+			sd.err = err
+			return
+		}
+		dp, err := doc.NewFromFiles(lp.fset, files, lp.path, doc.PreserveAST)
+		if err != nil {
+			sd.err = fmt.Errorf("failed to compute documentation for %s: %w", lp.path, err)
+			return
+		}
+		sd.files, sd.doc = files, dp
+	})
+	return sd.files, sd.doc, sd.err
+}
+
+// reparse parses lp's files again into fset, yielding an AST that shares no
+// nodes with lp's.
+func (lp *loadedPackage) reparse(fset *token.FileSet) ([]*ast.File, error) {
+	files := make([]*ast.File, 0, len(lp.files))
+	for _, f := range lp.files {
+		name := lp.fset.Position(f.Package).Filename
+		pf, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(name), err)
+		}
+		files = append(files, pf)
+	}
+	return files, nil
+}
+
+// editable returns a parse of lp that the caller may modify: lp itself, or,
+// when lp shares its AST with the parse cache, a fresh parse of its files.
+func (lp *loadedPackage) editable() (*loadedPackage, error) {
+	if lp.shared == nil {
+		return lp, nil
+	}
+	fset := token.NewFileSet()
+	files, err := lp.reparse(fset)
+	if err != nil {
+		return nil, err
+	}
+	return &loadedPackage{
+		path:       lp.path,
+		dir:        lp.dir,
+		workingDir: lp.workingDir,
+		fset:       fset,
+		files:      files,
+		sFiles:     lp.sFiles,
+		generated:  lp.generated,
+	}, nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"go/ast"
+	"go/build"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// funcNames returns the names of the functions declared in lp's files.
+func funcNames(lp *loadedPackage) map[string]bool {
+	names := make(map[string]bool)
+	for _, f := range lp.files {
+		for _, decl := range f.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok {
+				names[fd.Name.Name] = true
+			}
+		}
+	}
+	return names
+}
+
+func TestParseCacheSharedAST(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/shared\n\ngo 1.21\n",
+		"shared.go": `// Package shared is parsed once.
+package shared
+
+// T has a hidden field.
+type T struct {
+	Name   string
+	hidden int
+}
+
+// New returns a T.
+func New() *T { return &T{} }
+
+func helper() {}
+`,
+	})
+	gs := newGodocServer()
+	ctx := context.Background()
+	first, err := gs.parseDir(ctx, dir, "example.com/shared", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := gs.parseDir(ctx, dir, "example.com/shared", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.files[0] != first.files[0] {
+		t.Fatal("cache hit should share the cached AST")
+	}
+
+	// go/doc filters a parse of its own; the shared AST stays whole.
+	dp, err := first.docPackage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if funcNames(first)["helper"] {
+		t.Error("expected the documented files to lack helper")
+	}
+	if !funcNames(second)["helper"] {
+		t.Error("helper was removed from the shared parse")
+	}
+	ts, _ := findTypeSpec(second.files, "T")
+	if st := ts.Type.(*ast.StructType); len(st.Fields.List) != 2 {
+		t.Errorf("shared struct has %d fields, want 2", len(st.Fields.List))
+	}
+	if again, err := second.docPackage(); err != nil || again != dp {
+		t.Error("documentation of a cached parse should be computed once")
+	}
+
+	// Callers that edit the AST get a parse of their own.
+	ed, err := second.editable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ed.fset == second.fset || !funcNames(ed)["helper"] {
+		t.Error("editable should return a fresh, unfiltered parse")
+	}
+	private, err := parsePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same, _ := private.editable(); same != private {
+		t.Error("a private parse is already editable")
+	}
+}
+
+func TestParseCache(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/cached\n\ngo 1.21\n",
+		"a.go":   "// Package cached is parsed once.\npackage cached\n\n// A is a.\nfunc A() {}\n\nfunc helper() {}\n",
+	})
+
+	gs := newGodocServer()
+	ctx := context.Background()
+
+	first, err := gs.loadPackage(ctx, dir, "example.com/cached")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.docPackage(); err != nil {
+		t.Fatal(err)
+	}
+	second, err := gs.loadPackage(ctx, dir, "example.com/cached")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := gs.parseCache.len(); n != 1 {
+		t.Errorf("parse cache holds %d packages, want 1", n)
+	}
+	if second.fset != first.fset {
+		t.Error("second load was parsed again instead of served from the cache")
+	}
+	if !funcNames(second)["helper"] {
+		t.Error("the first caller's go/doc filtering leaked into the cached parse")
+	}
+	if second.path != "example.com/cached" || second.workingDir != dir {
+		t.Errorf("cached load has path %q, working dir %q", second.path, second.workingDir)
+	}
+
+	// Editing the package invalidates the cached parse.
+	src := "// Package cached is parsed once.\npackage cached\n\n// A is a.\nfunc A() {}\n\n// B is new.\nfunc B() {}\n"
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	third, err := gs.loadPackage(ctx, dir, "example.com/cached")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !funcNames(third)["B"] {
+		t.Error("edited source was not reparsed")
+	}
+	if third.fset == first.fset {
+		t.Error("expected a fresh parse after the edit")
+	}
+
+	// no_cache requests parse afresh and refresh the entry.
+	fresh, err := gs.loadPackage(withNoCache(ctx), dir, "example.com/cached")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh.fset == third.fset {
+		t.Error("no_cache load was served from the cache")
+	}
+}
+
+func TestParseCacheSize(t *testing.T) {
+	if gs := newGodocServer(withParseCacheSize(0)); gs.parseCache != nil {
+		t.Error("withParseCacheSize(0) should disable the cache")
+	}
+	if gs := newGodocServer(withParseCacheSize(-1)); gs.parseCache == nil || gs.parseCache.capacity != defaultParseCacheSize {
+		t.Error("negative sizes should keep the default cache")
+	}
+
+	c := newParseCache(2)
+	lp := &loadedPackage{}
+	for _, key := range []string{"a", "b", "c"} {
+		c.put(key, lp)
+	}
+	if n := c.len(); n != 2 {
+		t.Fatalf("cache holds %d entries, want 2", n)
+	}
+	if _, ok := c.get("a"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	// Using b makes c the eviction candidate.
+	c.get("b")
+	c.put("d", lp)
+	if _, ok := c.get("b"); !ok {
+		t.Error("recently used entry b was evicted")
+	}
+	if _, ok := c.get("c"); ok {
+		t.Error("expected c to be evicted")
+	}
+}
+
+// BenchmarkParseCache compares documenting net/http from a cached parse with
+// parsing it afresh.
+func BenchmarkParseCache(b *testing.B) {
+	dir := filepath.Join(build.Default.GOROOT, "src", "net", "http")
+	ctx := context.Background()
+	b.Run("hit", func(b *testing.B) {
+		log.SetOutput(io.Discard)
+		defer log.SetOutput(os.Stderr)
+		gs := newGodocServer()
+		if _, err := gs.parseDir(ctx, dir, "net/http", dir); err != nil {
+			b.Fatal(err)
+		}
+		b.ReportAllocs()
+		for range b.N {
+			lp, err := gs.parseDir(ctx, dir, "net/http", dir)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := lp.docPackage(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			lp, err := parsePackage(dir)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := lp.docPackage(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
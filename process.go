@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// modModes are the -mod values accepted by the mod_mode argument.
+var modModes = map[string]bool{"readonly": true, "mod": true, "vendor": true}
+
+// modModeKey carries the -mod mode for a request's go commands in a context.
+type modModeKey struct{}
+
+// withModMode returns a context whose go commands run with -mod=mode.
+func withModMode(ctx context.Context, mode string) context.Context {
+	return context.WithValue(ctx, modModeKey{}, mode)
+}
+
+// modMode returns the -mod mode set by withModMode, or "".
+func modMode(ctx context.Context) string {
+	mode, _ := ctx.Value(modModeKey{}).(string)
+	return mode
+}
+
+// cmdTimeoutKey carries a per-request override of cmdTimeout in a context.
+type cmdTimeoutKey struct{}
+
+// withCmdTimeout returns a context whose go commands time out after d
+// instead of cmdTimeout.
+func withCmdTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, cmdTimeoutKey{}, d)
+}
+
+// commandContext bounds ctx by the timeout for a single go command: cmdTimeout
+// unless the request overrode it with withCmdTimeout.
+func commandContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, commandTimeout(ctx))
+}
+
+// commandTimeout returns the timeout commandContext applies under ctx.
+func commandTimeout(ctx context.Context) time.Duration {
+	if override, ok := ctx.Value(cmdTimeoutKey{}).(time.Duration); ok && override > 0 {
+		return override
+	}
+	return cmdTimeout
+}
+
+// processPool bounds how many go subprocesses run at once across all
+// requests, however many clients or batched lookups arrive together.
+type processPool struct {
+	slots     chan struct{}
+	saturated atomic.Int64 // acquisitions that had to wait for a slot
+}
+
+func newProcessPool(size int) *processPool {
+	return &processPool{slots: make(chan struct{}, max(size, 1))}
+}
+
+// acquire blocks until a slot is free or ctx is done. It logs each time the
+// pool is full so sustained saturation shows up in the server log.
+func (p *processPool) acquire(ctx context.Context) (release func(), err error) {
+	select {
+	case p.slots <- struct{}{}:
+		return p.release, nil
+	default:
+	}
+
+	n := p.saturated.Add(1)
+	log.Printf("Process pool saturated (%d/%d running); waiting for a slot (%d waits so far)", len(p.slots), cap(p.slots), n)
+	select {
+	case p.slots <- struct{}{}:
+		return p.release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *processPool) release() {
+	<-p.slots
+}
+
+// cleanEnvVars are the only variables go subprocesses see under -clean-env.
+var cleanEnvVars = []string{"PATH", "HOME", "GOPATH", "GOCACHE", "GOMODCACHE"}
+
+// buildCleanEnv returns a minimal environment for go subprocesses holding
+// only cleanEnvVars. GOPATH, GOCACHE, and GOMODCACHE are pinned to the values
+// the go command resolves for the server's own environment, so dropping
+// their defaults' inputs (such as XDG_CACHE_HOME) changes nothing.
+func buildCleanEnv() []string {
+	resolved := make(map[string]string)
+	if out, err := exec.Command("go", "env", "GOPATH", "GOCACHE", "GOMODCACHE").Output(); err == nil {
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		for i, key := range []string{"GOPATH", "GOCACHE", "GOMODCACHE"} {
+			if i < len(lines) {
+				resolved[key] = lines[i]
+			}
+		}
+	}
+
+	var env []string
+	for _, key := range cleanEnvVars {
+		value, ok := resolved[key]
+		if !ok || value == "" {
+			value = os.Getenv(key)
+		}
+		if value != "" {
+			env = append(env, key+"="+value)
+		}
+	}
+	return env
+}
+
+// environ returns the base environment for go subprocesses: the server's
+// own, or the minimal one set up by withCleanEnv.
+func (gs *godocServer) environ() []string {
+	if gs.cleanEnv != nil {
+		return slices.Clone(gs.cleanEnv)
+	}
+	return os.Environ()
+}
+
+// envValue returns the value of key in env, honoring the last occurrence as
+// os/exec does.
+func envValue(env []string, key string) string {
+	for i := len(env) - 1; i >= 0; i-- {
+		if v, ok := strings.CutPrefix(env[i], key+"="); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// goCommand returns a go command for args, run in dir when dir is non-empty,
+// honoring the request's -mod mode and the server's -gotoolchain and
+// -modcache settings. Directories of a legacy GOPATH workspace run in GOPATH
+// mode, where -mod does not apply; in a go.work workspace, -mod settings
+// inherited from GOFLAGS that workspace mode rejects are dropped.
+func (gs *godocServer) goCommand(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	if dir == "" {
+		// Commands tied to no module, such as standard library lookups, run
+		// in the temp directory so that a go.mod around the server's working
+		// directory, and any toolchain it requires, does not apply to them.
+		cmd.Dir = os.TempDir()
+	}
+	cmd.Env = gs.environ()
+	if gs.goToolchain != "" {
+		cmd.Env = append(cmd.Env, "GOTOOLCHAIN="+gs.goToolchain)
+	}
+	if gs.modCache != "" {
+		cmd.Env = append(cmd.Env, "GOMODCACHE="+gs.modCache)
+	}
+	if exp := goExperiment(ctx); exp != "" {
+		cmd.Env = append(cmd.Env, "GOEXPERIMENT="+exp)
+	}
+	if gopathMode(dir) {
+		cmd.Env = append(cmd.Env, "GO111MODULE=off")
+		return cmd
+	}
+	goflags := envValue(cmd.Env, "GOFLAGS")
+	if workspaceFile(cmd.Env, dir) != "" {
+		goflags = workspaceGoFlags(goflags)
+	}
+	// go doc takes no build flags, so the mode is passed through GOFLAGS.
+	if mode := modMode(ctx); mode != "" {
+		goflags = strings.TrimSpace(goflags + " -mod=" + mode)
+	}
+	if goflags != envValue(cmd.Env, "GOFLAGS") {
+		cmd.Env = append(cmd.Env, "GOFLAGS="+goflags)
+	}
+	return cmd
+}
+
+// output runs cmd in a process pool slot and returns its standard output.
+func (gs *godocServer) output(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	release, err := gs.pool.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return cmd.Output()
+}
+
+// combinedOutput runs cmd in a process pool slot and returns its combined
+// standard output and standard error.
+func (gs *godocServer) combinedOutput(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	release, err := gs.pool.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return cmd.CombinedOutput()
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestProcessPool(t *testing.T) {
+	p := newProcessPool(1)
+	ctx := context.Background()
+
+	release, err := p.acquire(ctx)
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+
+	// A second acquire must wait until the slot is released.
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := p.acquire(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected saturated acquire to time out, got %v", err)
+	}
+	if got := p.saturated.Load(); got != 1 {
+		t.Errorf("saturated = %d, want 1", got)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r, err := p.acquire(ctx)
+		if err == nil {
+			r()
+		}
+		close(done)
+	}()
+	release()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting acquire did not proceed after release")
+	}
+}
+
+func TestWithMaxConcurrent(t *testing.T) {
+	gs := newGodocServer(withMaxConcurrent(3))
+	if got := cap(gs.pool.slots); got != 3 {
+		t.Errorf("pool size = %d, want 3", got)
+	}
+
+	gs = newGodocServer(withMaxConcurrent(0))
+	if got := cap(gs.pool.slots); got < 1 {
+		t.Errorf("default pool size = %d, want at least 1", got)
+	}
+}
+
+func TestCommandContext(t *testing.T) {
+	ctx, cancel := commandContext(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > cmdTimeout {
+		t.Errorf("default deadline = %v, want within %v", time.Until(deadline), cmdTimeout)
+	}
+
+	ctx, cancel = commandContext(withCmdTimeout(context.Background(), 2*time.Minute))
+	defer cancel()
+	deadline, _ = ctx.Deadline()
+	if time.Until(deadline) <= cmdTimeout {
+		t.Errorf("override deadline = %v, want about 2m", time.Until(deadline))
+	}
+}
+
+func TestGoCommandNeutralDir(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	// A go.mod the server happens to run under must not affect stdlib lookups.
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/broken\n\ngo 1.21\n\nrequire (\n",
+	})
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	gs := newGodocServer()
+	ctx := context.Background()
+	cmd := gs.goCommand(ctx, "", "doc", "io.Reader")
+	if cmd.Dir != os.TempDir() {
+		t.Errorf("cmd.Dir = %q, want %q", cmd.Dir, os.TempDir())
+	}
+	out, err := gs.output(ctx, cmd)
+	if err != nil {
+		t.Fatalf("go doc io.Reader: %v", err)
+	}
+	if !strings.Contains(string(out), "type Reader interface") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestGoCommandModMode(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+	t.Setenv("GOFLAGS", "")
+
+	gs := newGodocServer()
+	ctx := withModMode(context.Background(), "mod")
+	out, err := gs.output(ctx, gs.goCommand(ctx, "", "env", "GOFLAGS"))
+	if err != nil {
+		t.Fatalf("go env: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "-mod=mod" {
+		t.Errorf("GOFLAGS = %q, want -mod=mod", got)
+	}
+
+	args := []string{"io"}
+	if gs.docCacheKey(ctx, "", args) == gs.docCacheKey(context.Background(), "", args) {
+		t.Error("mod mode should be part of the cache key")
+	}
+}
+
+func TestGoCommandCleanEnv(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+	t.Setenv("GOFLAGS", "-tags=stray")
+	t.Setenv("GOPRIVATE", "example.com/private")
+
+	gs := newGodocServer(withCleanEnv(true))
+	for _, kv := range gs.cleanEnv {
+		key, _, _ := strings.Cut(kv, "=")
+		if !slices.Contains(cleanEnvVars, key) {
+			t.Errorf("unexpected variable %s in clean environment", kv)
+		}
+	}
+	if envValue(gs.cleanEnv, "GOMODCACHE") == "" {
+		t.Errorf("GOMODCACHE should be pinned in the clean environment: %v", gs.cleanEnv)
+	}
+
+	ctx := context.Background()
+	out, err := gs.output(ctx, gs.goCommand(ctx, "", "env", "GOFLAGS", "GOPRIVATE"))
+	if err != nil {
+		t.Fatalf("go env: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "" {
+		t.Errorf("stray variables leaked into the clean environment: %q", got)
+	}
+
+	ctx = withModMode(ctx, "mod")
+	if got := envValue(gs.goCommand(ctx, "", "env").Env, "GOFLAGS"); got != "-mod=mod" {
+		t.Errorf("GOFLAGS = %q, want -mod=mod", got)
+	}
+}
+
+func TestGoCommandToolchain(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer(withGoToolchain("local"))
+	ctx := context.Background()
+	out, err := gs.output(ctx, gs.goCommand(ctx, "", "env", "GOTOOLCHAIN"))
+	if err != nil {
+		t.Fatalf("go env: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "local" {
+		t.Errorf("GOTOOLCHAIN = %q, want local", got)
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":    "module example.com/future\n\ngo 1.999\n",
+		"future.go": "// Package future needs a newer Go.\npackage future\n",
+	})
+	_, err = gs.runGoDoc(ctx, dir, ".")
+	if !errors.Is(err, errToolchain) {
+		t.Fatalf("expected errToolchain, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "requires go 1.999") || !strings.Contains(err.Error(), "-gotoolchain=auto") {
+		t.Errorf("error should name the required version and the fix: %v", err)
+	}
+}
+
+func TestNoToolchainDownload(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+	t.Setenv("GOTOOLCHAIN", "auto")
+
+	gs := newGodocServer(withGoToolchain(""), withNoToolchainDownload(true))
+	ctx := context.Background()
+	if got := envValue(gs.goCommand(ctx, "", "env").Env, "GOTOOLCHAIN"); got != "local" {
+		t.Errorf("GOTOOLCHAIN = %q, want local", got)
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":    "module example.com/future\n\ngo 1.999\n",
+		"future.go": "// Package future needs a newer Go.\npackage future\n",
+	})
+	_, err := gs.runGoDoc(ctx, dir, ".")
+	if !errors.Is(err, errToolchain) {
+		t.Fatalf("expected errToolchain, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "requires go 1.999") || !strings.Contains(err.Error(), "without -no-toolchain-download") {
+		t.Errorf("error should name the required version and the fix: %v", err)
+	}
+
+	gs = newGodocServer(withNoToolchainDownload(false))
+	if got := envValue(gs.goCommand(ctx, "", "env").Env, "GOTOOLCHAIN"); got != "auto" {
+		t.Errorf("GOTOOLCHAIN = %q with the option off, want the inherited auto", got)
+	}
+}
+
+func TestGoCommandModCache(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	if gs := newGodocServer(withModCache("rel/cache")); !filepath.IsAbs(gs.modCache) {
+		t.Errorf("modCache = %q, want an absolute path", gs.modCache)
+	}
+
+	// A file-based proxy serving one module keeps the download offline.
+	proxy := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/dep\n\ngo 1.21\n",
+		"dep.go": "// Package dep came from the proxy.\npackage dep\n",
+	}
+	versions := filepath.Join(proxy, "example.com", "dep", "@v")
+	if err := os.MkdirAll(versions, 0755); err != nil {
+		t.Fatal(err)
+	}
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	for name, content := range files {
+		w, err := zw.Create("example.com/dep@v1.0.0/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"list":        "v1.0.0\n",
+		"v1.0.0.info": `{"Version":"v1.0.0","Time":"2024-01-01T00:00:00Z"}`,
+		"v1.0.0.mod":  files["go.mod"],
+		"v1.0.0.zip":  zipped.String(),
+	} {
+		if err := os.WriteFile(filepath.Join(versions, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(proxy))
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GOFLAGS", "-modcacherw") // lets t.TempDir remove the cache
+
+	cache := t.TempDir()
+	for _, clean := range []bool{false, true} {
+		gs := newGodocServer(withCleanEnv(clean), withModCache(cache))
+		ctx := context.Background()
+		out, err := gs.output(ctx, gs.goCommand(ctx, "", "env", "GOMODCACHE"))
+		if err != nil {
+			t.Fatalf("go env: %v", err)
+		}
+		if got := strings.TrimSpace(string(out)); got != cache {
+			t.Errorf("clean env %v: GOMODCACHE = %q, want %q", clean, got, cache)
+		}
+	}
+
+	gs := newGodocServer(withModCache(cache))
+	defer gs.cleanup()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_doc"
+	req.Params.Arguments = map[string]any{"path": "example.com/dep"}
+	result, err := gs.handleGetDoc(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleGetDoc returned protocol error: %v", err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; result.IsError || !strings.Contains(text, "Package dep came from the proxy.") {
+		t.Fatalf("unexpected result:\n%s", text)
+	}
+	if _, err := os.Stat(filepath.Join(cache, "example.com", "dep@v1.0.0", "dep.go")); err != nil {
+		t.Errorf("module was not downloaded into the configured cache: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const proposalLinksDescription = `List the Go issues, proposals, and design documents that a package's doc
+comments refer to, as links with the sentence and declaration that mention
+them. Recognizes go.dev/issue, golang.org/issue, github.com/golang/go/issues,
+and go.dev/design URLs; in the standard library, bare "issue 12345" and
+"proposal 12345" references to the Go issue tracker are recognized too. Use
+this to point users at the design rationale behind a standard library API.`
+
+var (
+	issueURLPattern  = regexp.MustCompile(`(?:https?://)?(?:go\.dev|golang\.org)/issues?/(\d+)|(?:https?://)?github\.com/golang/go/issues/(\d+)`)
+	designURLPattern = regexp.MustCompile(`(?:https?://)?(?:go\.dev|golang\.org)/design/([\w.-]*\w)`)
+	issueRefPattern  = regexp.MustCompile(`(?i)\b(?:issue|proposal)\s+#?(\d{3,})\b`)
+)
+
+// docReference is one link found in a package's doc comments and the places
+// that mention it.
+type docReference struct {
+	link     string
+	mentions []string // "where: sentence"
+}
+
+func (gs *godocServer) handleProposalLinks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Bare issue numbers only point at the Go tracker in the Go tree itself.
+	goroot := filepath.Join(build.Default.GOROOT, "src") + string(filepath.Separator)
+	refs := docReferences(dp, strings.HasPrefix(lp.dir+string(filepath.Separator), goroot))
+	if len(refs) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No issue, proposal, or design document references in the doc comments of %s.", pkgPath)), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Issue, proposal, and design references in the doc comments of %s\n", pkgPath)
+	for _, r := range refs {
+		fmt.Fprintf(&b, "\n%s\n", r.link)
+		for _, m := range r.mentions {
+			fmt.Fprintf(&b, "    %s\n", m)
+		}
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// docReferences collects the issue and design links in the doc comments of
+// dp's package and exported declarations, in the order they first appear.
+// When goTree is set, bare "issue N" and "proposal N" references are taken
+// to mean the Go issue tracker.
+func docReferences(dp *doc.Package, goTree bool) []*docReference {
+	var refs []*docReference
+	byLink := make(map[string]*docReference)
+	scan := func(where, text string) {
+		for _, s := range sentences(text) {
+			var links []string
+			for _, m := range issueURLPattern.FindAllStringSubmatch(s, -1) {
+				links = append(links, "https://go.dev/issue/"+m[1]+m[2])
+			}
+			for _, m := range designURLPattern.FindAllStringSubmatch(s, -1) {
+				links = append(links, "https://go.dev/design/"+m[1])
+			}
+			if goTree {
+				for _, m := range issueRefPattern.FindAllStringSubmatch(s, -1) {
+					links = append(links, "https://go.dev/issue/"+m[1])
+				}
+			}
+			for _, link := range links {
+				r := byLink[link]
+				if r == nil {
+					r = &docReference{link: link}
+					byLink[link] = r
+					refs = append(refs, r)
+				}
+				mention := where + ": " + s
+				if len(r.mentions) == 0 || r.mentions[len(r.mentions)-1] != mention {
+					r.mentions = append(r.mentions, mention)
+				}
+			}
+		}
+	}
+	values := func(vs []*doc.Value, kind string) {
+		for _, v := range vs {
+			if len(v.Names) > 0 && ast.IsExported(v.Names[0]) {
+				scan(kind+" "+strings.Join(v.Names, ", "), v.Doc)
+			}
+		}
+	}
+	funcs := func(fs []*doc.Func, recv string) {
+		for _, f := range fs {
+			if ast.IsExported(f.Name) {
+				scan("func "+recv+f.Name, f.Doc)
+			}
+		}
+	}
+
+	scan("package "+dp.Name, dp.Doc)
+	values(dp.Consts, "const")
+	values(dp.Vars, "var")
+	funcs(dp.Funcs, "")
+	for _, t := range dp.Types {
+		if !ast.IsExported(t.Name) {
+			continue
+		}
+		scan("type "+t.Name, t.Doc)
+		values(t.Consts, "const")
+		values(t.Vars, "var")
+		funcs(t.Funcs, "")
+		funcs(t.Methods, t.Name+".")
+	}
+	return refs
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleProposalLinks(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/iterx\n\ngo 1.21\n",
+		"iterx.go": `// Package iterx adapts sequences, following the design at
+// https://go.dev/design/61405-range-over-func.
+package iterx
+
+// Seq is a sequence. See golang.org/issue/61897 for its history.
+type Seq func(yield func(int) bool)
+
+// Pull converts a push sequence into a pull one (go.dev/issue/61897).
+// Unlike issue 1234, this is not resolved outside the Go tree.
+func (s Seq) Pull() {}
+
+// helper cites https://github.com/golang/go/issues/1 but is unexported.
+func helper() {}
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "proposal_links"
+		req.Params.Arguments = args
+		result, err := gs.handleProposalLinks(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleProposalLinks returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleProposalLinks returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := call(map[string]any{"path": ".", "working_dir": dir})
+	want := `Issue, proposal, and design references in the doc comments of example.com/iterx
+
+https://go.dev/design/61405-range-over-func
+    package iterx: Package iterx adapts sequences, following the design at https://go.dev/design/61405-range-over-func.
+
+https://go.dev/issue/61897
+    type Seq: See golang.org/issue/61897 for its history.
+    func Seq.Pull: Pull converts a push sequence into a pull one (go.dev/issue/61897).
+`
+	if !strings.HasPrefix(text, want) {
+		t.Errorf("got:\n%s\nwant prefix:\n%s", text, want)
+	}
+	for _, absent := range []string{"issue/1234", "issue/1\n", "helper"} {
+		if strings.Contains(text, absent) {
+			t.Errorf("unexpected %q in:\n%s", absent, text)
+		}
+	}
+
+	if text := call(map[string]any{"path": "go/types"}); !strings.Contains(text, "https://go.dev/issue/8353\n    func Selection.Indirect: ") {
+		t.Errorf("expected the bare Go issue reference in go/types to be linked:\n%s", text)
+	}
+}
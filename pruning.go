@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// missingRequirementPattern matches the go doc failures for a package whose
+// module the main module's go.mod does not require.
+var missingRequirementPattern = regexp.MustCompile(`updates to go\.mod needed|missing go\.sum entry for module providing package|no required module provides package`)
+
+// maxPrunedRequirers caps how many requiring modules a pruned-module error names.
+const maxPrunedRequirers = 3
+
+// prunedModule finds the module that provides importPath in the module graph
+// of workingDir when go.mod does not require it, as happens under the module
+// graph pruning of Go 1.17 and later: the module is reachable only through
+// another module's requirements, so its packages cannot be loaded from the
+// main module. It returns the module and the graph nodes that require it.
+func (gs *godocServer) prunedModule(ctx context.Context, workingDir, importPath string) (modVersion, []modRequire, bool) {
+	mf, err := gs.readModFile(ctx, workingDir)
+	if err != nil {
+		return modVersion{}, nil, false
+	}
+	for p := importPath; strings.Contains(p, "."); p = path.Dir(p) {
+		if p == mf.Module.Path || slices.ContainsFunc(mf.Require, func(r modRequire) bool { return r.Path == p }) {
+			return modVersion{}, nil, false
+		}
+		version := gs.requiredVersion(ctx, workingDir, p)
+		if version == "" {
+			continue
+		}
+		requirers, err := gs.moduleRequirers(ctx, workingDir, p)
+		if err != nil {
+			return modVersion{}, nil, false
+		}
+		return modVersion{Path: p, Version: version}, requirers, true
+	}
+	return modVersion{}, nil, false
+}
+
+// prunedModuleDoc handles go doc's failure docErr to load dr.pkgPath when its
+// module is only in the pruned module graph. When the operator enabled it
+// with -enable-go-get and the request sets mod_mode=mod, which lets the go
+// command edit go.mod, the module is added with go get and the lookup
+// retried; otherwise the returned error explains the fix. docErr is returned
+// unchanged for any other failure.
+func (gs *godocServer) prunedModuleDoc(ctx context.Context, dr docRequest, args []string, docErr error) (string, error) {
+	mod, requirers, ok := gs.prunedModule(ctx, dr.workingDir, dr.pkgPath)
+	if !ok {
+		return "", docErr
+	}
+	query := mod.Path + "@" + mod.Version
+
+	if modMode(ctx) != "mod" || !gs.goGet {
+		hint := "or retry with mod_mode=mod to let the server run that command"
+		if !gs.goGet {
+			hint = "the server runs that command itself, under mod_mode=mod, only when started with -enable-go-get"
+		}
+		via := make([]string, 0, maxPrunedRequirers)
+		for _, r := range requirers[:min(len(requirers), maxPrunedRequirers)] {
+			via = append(via, r.Path)
+		}
+		if len(requirers) > maxPrunedRequirers {
+			via = append(via, fmt.Sprintf("%d more", len(requirers)-maxPrunedRequirers))
+		}
+		return "", fmt.Errorf("%s is provided by %s, which is in the module graph only as a requirement of %s. "+
+			"Since Go 1.17, module graph pruning keeps packages of such modules out of reach until go.mod requires the module itself; add it with:\n"+
+			"\tgo get %s\n"+
+			"%s.\nDetail: %w",
+			dr.pkgPath, mod, strings.Join(via, ", "), query, hint, docErr)
+	}
+
+	getCtx, cancel := commandContext(ctx)
+	defer cancel()
+	if out, err := gs.combinedOutput(getCtx, gs.goCommand(getCtx, dr.workingDir, "get", query)); err != nil {
+		return "", fmt.Errorf("go get %s failed: %w\noutput: %s", query, err, out)
+	}
+	doc, err := gs.runGoDocHashed(ctx, dr.workingDir, dr.srcHash, args...)
+	if err != nil {
+		return "", err
+	}
+	modDir, err := findModuleRoot(dr.workingDir)
+	if err != nil {
+		modDir = dr.workingDir
+	}
+	return doc + fmt.Sprintf("\nNOTE: %s was only in the pruned module graph; ran go get %s to require it in %s (mod_mode=mod).\n", mod.Path, query, filepath.Join(modDir, "go.mod")), nil
+}
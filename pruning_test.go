@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleGetDocPrunedModule(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(dir, "cmd")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	added := filepath.Join(t.TempDir(), "added")
+	// example.com/app requires example.com/a, which requires example.com/b;
+	// b is in the module graph but not in go.mod until go get adds it.
+	fakeGo(t, `case "$*" in
+"doc example.com/b/codec")
+	if [ -e "`+added+`" ]; then
+		echo 'package codec // import "example.com/b/codec"'
+		echo
+		echo 'Package codec encodes values.'
+	else
+		printf 'doc: go: updates to go.mod needed; to update it:\n\tgo mod tidy\n' >&2
+		exit 1
+	fi ;;
+"mod edit -json")
+	echo '{"Module": {"Path": "example.com/app"}, "Require": [{"Path": "example.com/a", "Version": "v1.0.0"}]}' ;;
+"list -m -f {{.Version}} example.com/b")
+	echo v1.2.0 ;;
+"list -m -f {{.Version}} "*)
+	exit 1 ;;
+"mod graph")
+	printf '%s\n' "example.com/app example.com/a@v1.0.0" "example.com/a@v1.0.0 example.com/b@v1.2.0" ;;
+"get example.com/b@v1.2.0")
+	touch "`+added+`" ;;
+esac
+`)
+	gs := newGodocServer(withGoGet(true))
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		return result
+	}
+
+	result := call(map[string]any{"path": "example.com/b/codec", "working_dir": dir})
+	text := result.Content[0].(mcp.TextContent).Text
+	if !result.IsError {
+		t.Fatalf("expected an error before the module is required:\n%s", text)
+	}
+	for _, want := range []string{
+		"example.com/b/codec is provided by example.com/b v1.2.0, which is in the module graph only as a requirement of example.com/a@v1.0.0",
+		"\tgo get example.com/b@v1.2.0\n",
+		"mod_mode=mod",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	// Without -enable-go-get, go.mod is never edited.
+	gs = newGodocServer()
+	result = call(map[string]any{"path": "example.com/b/codec", "working_dir": sub, "mod_mode": "mod"})
+	text = result.Content[0].(mcp.TextContent).Text
+	if !result.IsError || !strings.Contains(text, "only when started with -enable-go-get") {
+		t.Fatalf("expected go get to be refused without -enable-go-get:\n%s", text)
+	}
+	if _, err := os.Stat(added); err == nil {
+		t.Fatal("go get ran without -enable-go-get")
+	}
+
+	gs = newGodocServer(withGoGet(true))
+	result = call(map[string]any{"path": "example.com/b/codec", "working_dir": sub, "mod_mode": "mod"})
+	text = result.Content[0].(mcp.TextContent).Text
+	if result.IsError {
+		t.Fatalf("expected the module to be added under mod_mode=mod:\n%s", text)
+	}
+	for _, want := range []string{"Package codec encodes values.", "NOTE: example.com/b was only in the pruned module graph; ran go get example.com/b@v1.2.0 to require it in " + filepath.Join(dir, "go.mod")} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestGoGetReadOnlyHint(t *testing.T) {
+	readOnly := func(gs *godocServer) bool {
+		hint := gs.mcpServer.ListTools()["get_doc"].Tool.Annotations.ReadOnlyHint
+		return hint != nil && *hint
+	}
+	if !readOnly(newGodocServer()) {
+		t.Error("get_doc should be read-only by default")
+	}
+	if readOnly(newGodocServer(withGoGet(true))) {
+		t.Error("get_doc may edit go.mod with -enable-go-get and should not be read-only")
+	}
+}
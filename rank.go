@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const rankPackagesDescription = `List the importable packages under a Go module or package path, ranked by importance.
+Packages nearer the root, those imported by more of the other packages listed,
+and those exporting more symbols rank higher; internal packages and commands
+are left out. Each package is shown with its synopsis and the numbers behind
+its rank. Use this to find the main entry points of a large library quickly
+instead of reading list_packages' alphabetical list.`
+
+// rankedPackage is a package considered by rank_packages.
+type rankedPackage struct {
+	ImportPath string
+	Name       string
+	Doc        string
+	Dir        string
+	Imports    []string
+
+	depth     int // path elements below the listed root
+	importers int // other listed packages importing it
+	exported  int // exported symbols
+	score     float64
+}
+
+func (gs *godocServer) handleRankPackages(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	pkgs, err := gs.listRankedPackages(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(pkgs) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No importable packages found under %s", pkgPath)), nil
+	}
+
+	for _, p := range pkgs {
+		p.depth = strings.Count(strings.TrimPrefix(p.ImportPath, pkgPath), "/")
+		lp, err := gs.parseDir(ctx, workingDir, p.ImportPath, p.Dir)
+		if err != nil {
+			continue
+		}
+		lp.path = p.ImportPath
+		dp, err := lp.docPackage()
+		if err != nil {
+			continue
+		}
+		for _, sym := range packageSymbols(dp) {
+			if ast.IsExported(lastName(sym.name)) {
+				p.exported++
+			}
+		}
+	}
+	rankPackages(pkgs)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d packages under %s, most important first\n\n", len(pkgs), pkgPath)
+	for i, p := range pkgs {
+		fmt.Fprintf(&b, "%d. %s", i+1, p.ImportPath)
+		if synopsis := strings.TrimSpace(p.Doc); synopsis != "" {
+			b.WriteString(" - " + synopsis)
+		}
+		fmt.Fprintf(&b, "\n   depth %d, %d exported symbols, imported by %d of these packages\n", p.depth, p.exported, p.importers)
+	}
+	return mcp.NewToolResultText(strings.TrimRight(b.String(), "\n")), nil
+}
+
+// listRankedPackages lists the packages under importPath that other modules
+// can import: internal packages and commands are skipped.
+func (gs *godocServer) listRankedPackages(ctx context.Context, workingDir, importPath string) ([]*rankedPackage, error) {
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	cmd := gs.goCommand(listCtx, workingDir, "list", "-e", "-json=ImportPath,Name,Doc,Dir,Imports", importPath+"/...")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := gs.output(listCtx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("go list failed: %w\noutput: %s", err, stderr.String())
+	}
+
+	var pkgs []*rankedPackage
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		p := new(rankedPackage)
+		if err := dec.Decode(p); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse go list output: %w", err)
+		}
+		if p.Name == "" || p.Name == "main" || isInternalPath(strings.TrimPrefix(p.ImportPath, importPath)) {
+			continue
+		}
+		pkgs = append(pkgs, p)
+	}
+	return pkgs, nil
+}
+
+// rankPackages scores pkgs and sorts them, most important first. Each
+// heuristic contributes up to one point: closeness to the root (1 for the
+// root, halving with each level), and the number of importers and of
+// exported symbols relative to the package with the most. Ties are broken by
+// import path.
+func rankPackages(pkgs []*rankedPackage) {
+	index := make(map[string]*rankedPackage, len(pkgs))
+	for _, p := range pkgs {
+		index[p.ImportPath] = p
+		p.importers = 0
+	}
+	for _, p := range pkgs {
+		for _, imp := range p.Imports {
+			if q, ok := index[imp]; ok && q != p {
+				q.importers++
+			}
+		}
+	}
+
+	maxImporters, maxExported := 0, 0
+	for _, p := range pkgs {
+		maxImporters = max(maxImporters, p.importers)
+		maxExported = max(maxExported, p.exported)
+	}
+	for _, p := range pkgs {
+		p.score = 1 / float64(int(1)<<min(p.depth, 30))
+		if maxImporters > 0 {
+			p.score += float64(p.importers) / float64(maxImporters)
+		}
+		if maxExported > 0 {
+			p.score += float64(p.exported) / float64(maxExported)
+		}
+	}
+	slices.SortStableFunc(pkgs, func(a, b *rankedPackage) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		}
+		return strings.Compare(a.ImportPath, b.ImportPath)
+	})
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRankPackages(t *testing.T) {
+	pkgs := []*rankedPackage{
+		{ImportPath: "m/z", depth: 1, exported: 1},
+		{ImportPath: "m", depth: 0, exported: 2, Imports: []string{"m/core"}},
+		{ImportPath: "m/core", depth: 1, exported: 10},
+		{ImportPath: "m/a", depth: 1, exported: 1, Imports: []string{"m/core", "fmt"}},
+		{ImportPath: "m/a/deep", depth: 2, exported: 1, Imports: []string{"m/core"}},
+	}
+	rankPackages(pkgs)
+
+	var got []string
+	for _, p := range pkgs {
+		got = append(got, p.ImportPath)
+	}
+	want := []string{"m/core", "m", "m/a", "m/z", "m/a/deep"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+	if pkgs[0].importers != 3 {
+		t.Errorf("m/core importers = %d, want 3", pkgs[0].importers)
+	}
+}
+
+func TestHandleRankPackages(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":                "module example.com/lib\n\ngo 1.21\n",
+		"lib.go":                "// Package lib is the entry point.\npackage lib\n\nimport _ \"example.com/lib/codec\"\n\n// Open opens.\nfunc Open() {}\n",
+		"codec/codec.go":        "// Package codec encodes.\npackage codec\n\nfunc Encode() {}\nfunc Decode() {}\n\ntype Codec struct{}\n",
+		"extra/x/x.go":          "// Package x is rarely used.\npackage x\n\nfunc X() {}\n",
+		"internal/priv/priv.go": "package priv\n\nfunc P() {}\n",
+		"cmd/tool/main.go":      "package main\n\nfunc main() {}\n",
+	})
+
+	gs := newGodocServer()
+	defer gs.cleanup()
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"path": ".", "working_dir": dir}
+	result, err := gs.handleRankPackages(context.Background(), req)
+	if err != nil {
+		t.Fatalf("protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+
+	if !strings.HasPrefix(text, "3 packages under example.com/lib") {
+		t.Errorf("unexpected header:\n%s", text)
+	}
+	for _, want := range []string{
+		"1. example.com/lib/codec - Package codec encodes.\n   depth 1, 3 exported symbols, imported by 1 of these packages",
+		"2. example.com/lib - Package lib is the entry point.",
+		"3. example.com/lib/extra/x",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"internal/priv", "cmd/tool"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("output lists %s:\n%s", unwanted, text)
+		}
+	}
+}
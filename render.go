@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"go/doc/comment"
+	"strings"
+)
+
+// docFormats lists the values of doc_structure's format argument. "json" is
+// the structured result itself; the others are rendered by docFormatters.
+var docFormats = []string{"json", "text", "markdown", "rst", "adoc"}
+
+// docFormatters maps each markup format to its formatter. A new format needs
+// only a docFormatter implementation and an entry here and in docFormats.
+var docFormatters = map[string]docFormatter{
+	"text":     textFormatter{},
+	"markdown": markdownFormatter{},
+	"rst":      rstFormatter{},
+	"adoc":     adocFormatter{},
+}
+
+// docFormatter writes the elements of a parsed doc comment in one markup
+// language. renderDoc walks the comment and calls it for each element; block
+// methods return the block without surrounding blank lines.
+type docFormatter interface {
+	title(text string) string
+	heading(text string) string
+	declaration(decl string) string
+	code(text string) string
+	listItem(ordered bool, number, text string) string
+	plain(text string) string
+	italic(text string) string
+	link(text, url string) string
+}
+
+// docLinkBaseURL is where doc links to packages and symbols point.
+const docLinkBaseURL = "https://pkg.go.dev"
+
+// renderDoc renders the documentation of sd, whose comment parses to d, with
+// f: a title naming the package or symbol, the declaration, and the comment's
+// blocks in order.
+func renderDoc(f docFormatter, sd structuredDoc, d *comment.Doc) string {
+	title := sd.Package
+	if sd.Symbol != "" {
+		title = sd.Package + "." + sd.Symbol
+	}
+	blocks := []string{f.title(title)}
+	if sd.Declaration != "" {
+		blocks = append(blocks, f.declaration(sd.Declaration))
+	}
+	for _, blk := range d.Content {
+		switch blk := blk.(type) {
+		case *comment.Heading:
+			blocks = append(blocks, f.heading(renderInline(f, blk.Text, sd.Package)))
+		case *comment.Paragraph:
+			blocks = append(blocks, renderInline(f, blk.Text, sd.Package))
+		case *comment.List:
+			var items []string
+			for i, item := range blk.Items {
+				var paras []string
+				for _, c := range item.Content {
+					if p, ok := c.(*comment.Paragraph); ok {
+						paras = append(paras, renderInline(f, p.Text, sd.Package))
+					}
+				}
+				number := item.Number
+				if number == "" {
+					number = fmt.Sprint(i + 1)
+				}
+				items = append(items, f.listItem(item.Number != "", number, strings.Join(paras, " ")))
+			}
+			blocks = append(blocks, strings.Join(items, "\n"))
+		case *comment.Code:
+			blocks = append(blocks, f.code(strings.TrimSuffix(blk.Text, "\n")))
+		}
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// renderInline renders the text of a block with f, joining soft line breaks
+// with spaces. Doc links without an import path refer to importPath.
+func renderInline(f docFormatter, texts []comment.Text, importPath string) string {
+	var b strings.Builder
+	for _, t := range texts {
+		switch t := t.(type) {
+		case comment.Plain:
+			b.WriteString(f.plain(string(t)))
+		case comment.Italic:
+			b.WriteString(f.italic(string(t)))
+		case *comment.Link:
+			b.WriteString(f.link(inlineText(t.Text, importPath, nil), t.URL))
+		case *comment.DocLink:
+			link := *t
+			if link.ImportPath == "" {
+				link.ImportPath = importPath
+			}
+			b.WriteString(f.link(inlineText(t.Text, importPath, nil), link.DefaultURL(docLinkBaseURL)))
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// indent prefixes each non-empty line of text with prefix.
+func indent(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textFormatter renders plain text in go doc's layout, with links reduced to
+// their text.
+type textFormatter struct{}
+
+func (textFormatter) title(text string) string       { return text }
+func (textFormatter) heading(text string) string     { return "# " + text }
+func (textFormatter) declaration(decl string) string { return indent(decl, "    ") }
+func (textFormatter) code(text string) string        { return indent(text, "    ") }
+func (textFormatter) plain(text string) string       { return text }
+func (textFormatter) italic(text string) string      { return text }
+func (textFormatter) link(text, url string) string   { return text }
+func (textFormatter) listItem(ordered bool, n, text string) string {
+	if ordered {
+		return "  " + n + ". " + text
+	}
+	return "  - " + text
+}
+
+// markdownFormatter renders CommonMark.
+type markdownFormatter struct{}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`)
+
+func (markdownFormatter) title(text string) string       { return "# " + text }
+func (markdownFormatter) heading(text string) string     { return "## " + text }
+func (markdownFormatter) declaration(decl string) string { return "```go\n" + decl + "\n```" }
+func (markdownFormatter) code(text string) string        { return indent(text, "    ") }
+func (markdownFormatter) plain(text string) string       { return markdownEscaper.Replace(text) }
+func (m markdownFormatter) italic(text string) string    { return "*" + m.plain(text) + "*" }
+func (m markdownFormatter) link(text, url string) string {
+	return "[" + m.plain(text) + "](" + url + ")"
+}
+func (markdownFormatter) listItem(ordered bool, n, text string) string {
+	if ordered {
+		return n + ". " + text
+	}
+	return "- " + text
+}
+
+// rstFormatter renders reStructuredText.
+type rstFormatter struct{}
+
+var rstEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "`", "\\`", "|", `\|`, "_", `\_`)
+
+// underline returns text underlined with c, as reStructuredText section
+// titles are.
+func underline(text string, c string) string {
+	return text + "\n" + strings.Repeat(c, len([]rune(text)))
+}
+
+func (rstFormatter) title(text string) string   { return underline(text, "=") }
+func (rstFormatter) heading(text string) string { return underline(text, "-") }
+func (rstFormatter) declaration(decl string) string {
+	return ".. code-block:: go\n\n" + indent(decl, "   ")
+}
+func (rstFormatter) code(text string) string     { return "::\n\n" + indent(text, "   ") }
+func (rstFormatter) plain(text string) string    { return rstEscaper.Replace(text) }
+func (r rstFormatter) italic(text string) string { return "*" + r.plain(text) + "*" }
+
+// link writes an anonymous hyperlink, so links sharing their text do not
+// clash as named targets would.
+func (rstFormatter) link(text, url string) string {
+	return "`" + strings.NewReplacer("`", "\\`", "<", `\<`).Replace(text) + " <" + url + ">`__"
+}
+func (rstFormatter) listItem(ordered bool, n, text string) string {
+	if ordered {
+		return n + ". " + text
+	}
+	return "- " + text
+}
+
+// adocFormatter renders AsciiDoc.
+type adocFormatter struct{}
+
+var adocEscaper = strings.NewReplacer("*", `\*`, "_", `\_`, "`", "\\`", "#", `\#`, "[", `\[`, "]", `\]`)
+
+func (adocFormatter) title(text string) string       { return "= " + text }
+func (adocFormatter) heading(text string) string     { return "== " + text }
+func (adocFormatter) declaration(decl string) string { return "[source,go]\n----\n" + decl + "\n----" }
+func (adocFormatter) code(text string) string        { return "----\n" + text + "\n----" }
+func (adocFormatter) plain(text string) string       { return adocEscaper.Replace(text) }
+func (a adocFormatter) italic(text string) string    { return "_" + a.plain(text) + "_" }
+func (adocFormatter) link(text, url string) string {
+	return "link:" + url + "[" + strings.ReplaceAll(text, "]", `\]`) + "]"
+}
+func (adocFormatter) listItem(ordered bool, n, text string) string {
+	if ordered {
+		return ". " + text
+	}
+	return "* " + text
+}
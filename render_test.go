@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"go/doc/comment"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const renderTestComment = `Package rich has rich docs.
+
+# Usage
+
+Call [New], then read from it as an [io.Reader].
+See https://example.com/rich for more.
+
+  1. Open it.
+  2. Read it.
+
+For example:
+
+	r := rich.New()
+`
+
+func TestRenderDoc(t *testing.T) {
+	var p comment.Parser
+	p.LookupPackage = func(name string) (string, bool) { return name, name == "io" }
+	p.LookupSym = func(recv, name string) bool { return name == "New" }
+	d := p.Parse(renderTestComment)
+	sd := structuredDoc{Package: "example.com/rich"}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"text", []string{
+			"example.com/rich\n\nPackage rich has rich docs.\n\n# Usage\n\n",
+			"as an io.Reader.",
+			"  1. Open it.\n  2. Read it.",
+			"\n    r := rich.New()\n",
+		}},
+		{"markdown", []string{
+			"# example.com/rich\n\n",
+			"## Usage",
+			"Call [New](https://pkg.go.dev/example.com/rich#New)",
+			"[io.Reader](https://pkg.go.dev/io#Reader)",
+			"1. Open it.\n2. Read it.",
+			"    r := rich.New()",
+		}},
+		{"rst", []string{
+			"example.com/rich\n================\n\n",
+			"Usage\n-----",
+			"`io.Reader <https://pkg.go.dev/io#Reader>`__",
+			"`https://example.com/rich <https://example.com/rich>`__",
+			"1. Open it.\n2. Read it.",
+			"::\n\n   r := rich.New()",
+		}},
+		{"adoc", []string{
+			"= example.com/rich\n\n",
+			"== Usage",
+			"link:https://pkg.go.dev/io#Reader[io.Reader]",
+			". Open it.\n. Read it.",
+			"----\nr := rich.New()\n----",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got := renderDoc(docFormatters[tt.format], sd, d)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output missing %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderDocEscaping(t *testing.T) {
+	var p comment.Parser
+	d := p.Parse("Use a *T or some_name.\n")
+	sd := structuredDoc{Package: "p", Symbol: "F", Declaration: "func F(t *T)"}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"markdown", []string{"# p.F", "```go\nfunc F(t *T)\n```", `Use a \*T or some\_name.`}},
+		{"rst", []string{"p.F\n===", ".. code-block:: go\n\n   func F(t *T)", `Use a \*T or some\_name.`}},
+		{"adoc", []string{"= p.F", "[source,go]\n----\nfunc F(t *T)\n----", `Use a \*T or some\_name.`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got := renderDoc(docFormatters[tt.format], sd, d)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output missing %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleDocStructureFormat(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":  "module example.com/rich\n\ngo 1.21\n",
+		"rich.go": "/*\n" + renderTestComment + "*/\npackage rich\n\n// Reader reads.\ntype Reader struct{}\n\n// New returns a [Reader].\nfunc New() *Reader { return nil }\n",
+	})
+
+	gs := newGodocServer()
+	defer gs.cleanup()
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "doc_structure"
+		req.Params.Arguments = args
+		result, err := gs.handleDocStructure(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleDocStructure returned protocol error: %v", err)
+		}
+		return result
+	}
+
+	result := call(map[string]any{"path": ".", "working_dir": dir, "target": "New", "format": "rst"})
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"example.com/rich.New\n====",
+		".. code-block:: go\n\n   func New() *Reader",
+		"`Reader <https://pkg.go.dev/example.com/rich#Reader>`__",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("rst output missing %q:\n%s", want, text)
+		}
+	}
+	if sd, ok := result.StructuredContent.(structuredDoc); !ok || sd.Symbol != "New" {
+		t.Errorf("structured content = %#v, want the structure of New", result.StructuredContent)
+	}
+
+	result = call(map[string]any{"path": ".", "working_dir": dir, "format": "adoc"})
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "== Usage") {
+		t.Errorf("adoc output missing heading:\n%s", text)
+	}
+
+	result = call(map[string]any{"path": ".", "working_dir": dir, "format": "html"})
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "invalid format") {
+		t.Errorf("format html: want invalid format error, got %+v", result.Content)
+	}
+}
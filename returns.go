@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// returnedTypeDoc documents the primary result type of the function or
+// method target, for get_doc's include_returned_type: the named type of its
+// first result, behind any pointer, such as io.ReadCloser for io.NopCloser.
+// Only one level is followed, and only to types declared in the same module
+// or the standard library, and never into a package -deny-packages blocks.
+// It returns "" when target returns no such type.
+func (gs *godocServer) returnedTypeDoc(ctx context.Context, dr docRequest, target string) string {
+	lp, err := gs.loadPackage(ctx, dr.workingDir, dr.pkgPath)
+	if err != nil {
+		return ""
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return ""
+	}
+	sym, ok := findSymbol(dp, target)
+	if !ok || (sym.kind != "func" && sym.kind != "method") {
+		return ""
+	}
+	fd := sym.decl.(*ast.FuncDecl)
+	if fd.Type.Results == nil || len(fd.Type.Results.List) == 0 {
+		return ""
+	}
+
+	importPath, name := gs.resultTypeName(ctx, lp, dr, fd.Type.Results.List[0].Type)
+	if name == "" || (importPath == dr.pkgPath && strings.HasPrefix(target, name+".")) {
+		// A method returning its own receiver type adds nothing.
+		return ""
+	}
+	if gs.checkPackageAllowed(importPath) != nil {
+		return ""
+	}
+	doc, err := gs.runGoDoc(ctx, dr.workingDir, importPath, name)
+	if err != nil {
+		return ""
+	}
+	qualified := name
+	if importPath != dr.pkgPath {
+		qualified = importPath + "." + name
+	}
+	return "\nRETURNED TYPE " + qualified + "\n\n" + stripPackageHeader(doc)
+}
+
+// resultTypeName resolves the result type expr of a function declared in lp
+// to the import path and name of an exported named type, or returns "" for a
+// name when it is unnamed, predeclared, a type parameter, or declared outside
+// the documented package's module and the standard library.
+func (gs *godocServer) resultTypeName(ctx context.Context, lp *loadedPackage, dr docRequest, expr ast.Expr) (importPath, name string) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch x := expr.(type) {
+	case *ast.IndexExpr:
+		expr = x.X
+	case *ast.IndexListExpr:
+		expr = x.X
+	}
+
+	switch x := expr.(type) {
+	case *ast.Ident:
+		ts, _ := findTypeSpec(lp.files, x.Name)
+		if ts == nil || !token.IsExported(x.Name) {
+			return "", ""
+		}
+		switch ts.Type.(type) {
+		case *ast.StructType, *ast.InterfaceType:
+			return dr.pkgPath, x.Name
+		}
+		return "", ""
+
+	case *ast.SelectorExpr:
+		pkg, ok := x.X.(*ast.Ident)
+		if !ok || !x.Sel.IsExported() {
+			return "", ""
+		}
+		importPath = fileImport(lp, x.Pos(), pkg.Name)
+		if importPath == "" {
+			return "", ""
+		}
+		if !isStdLib(importPath) {
+			modPath, _ := gs.packageModule(ctx, dr.workingDir, dr.pkgPath)
+			if modPath == "" || (importPath != modPath && !strings.HasPrefix(importPath, modPath+"/")) {
+				return "", ""
+			}
+		}
+		return importPath, x.Sel.Name
+	}
+	return "", ""
+}
+
+// fileImport returns the import path that name refers to in the file of lp
+// containing pos, or "" if that file imports no package by that name.
+func fileImport(lp *loadedPackage, pos token.Pos, name string) string {
+	for _, f := range lp.files {
+		if pos < f.Pos() || pos > f.End() {
+			continue
+		}
+		for _, imp := range f.Imports {
+			importPath, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				continue
+			}
+			if (imp.Name != nil && imp.Name.Name == name) || (imp.Name == nil && importDefaultName(importPath) == name) {
+				return importPath
+			}
+		}
+	}
+	return ""
+}
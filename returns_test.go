@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleGetDocReturnedType(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":           "module example.com/svc\n\ngo 1.21\n\nrequire example.com/other v0.0.0\n\nreplace example.com/other => ./other\n",
+		"other/go.mod":     "module example.com/other\n\ngo 1.21\n",
+		"other/ext/ext.go": "package ext\n\n// Client is another module's client.\ntype Client struct{}\n",
+		"svc.go": `package svc
+
+import (
+	"bufio"
+
+	"example.com/other/ext"
+	"example.com/svc/store"
+)
+
+// Open returns a store.
+func Open() (*store.DB, error) { return nil, nil }
+
+// Reader wraps a buffered reader.
+func Reader() *bufio.Reader { return nil }
+
+// Remote returns a type from another module.
+func Remote() ext.Client { return ext.Client{} }
+
+// Config configures the service.
+type Config struct{ Name string }
+
+// Default returns the default config.
+func Default() Config { return Config{} }
+
+// Clone copies c.
+func (c Config) Clone() Config { return c }
+
+// Count is a plain number.
+func Count() int { return 0 }
+`,
+		"store/store.go": `package store
+
+// DB is a handle to the store.
+type DB struct{}
+
+// Close closes the store.
+func (db *DB) Close() error { return nil }
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	for target, want := range map[string]string{
+		"Open":    "RETURNED TYPE example.com/svc/store.DB\n\ntype DB struct{}\n    DB is a handle to the store.\n\nfunc (db *DB) Close() error",
+		"Reader":  "RETURNED TYPE bufio.Reader\n\ntype Reader struct {",
+		"Default": "RETURNED TYPE Config\n\ntype Config struct{ Name string }\n    Config configures the service.",
+	} {
+		text := call(map[string]any{"path": ".", "working_dir": dir, "target": target, "include_returned_type": true})
+		if !strings.Contains(text, want) {
+			t.Errorf("%s: missing %q in:\n%s", target, want, text)
+		}
+	}
+
+	for _, target := range []string{"Remote", "Count", "Config.Clone"} {
+		text := call(map[string]any{"path": ".", "working_dir": dir, "target": target, "include_returned_type": true})
+		if strings.Contains(text, "RETURNED TYPE") {
+			t.Errorf("%s: unexpected returned type section:\n%s", target, text)
+		}
+	}
+
+	if text := call(map[string]any{"path": "io", "target": "NopCloser", "include_returned_type": true}); !strings.Contains(text, "RETURNED TYPE ReadCloser\n\ntype ReadCloser interface {") {
+		t.Errorf("expected io.ReadCloser docs:\n%s", text)
+	}
+	// Types from denied packages are not documented.
+	gs = newGodocServer(withDenyPackages([]string{"example.com/svc/store"}))
+	if text := call(map[string]any{"path": ".", "working_dir": dir, "target": "Open", "include_returned_type": true}); strings.Contains(text, "RETURNED TYPE") {
+		t.Errorf("returned type from a denied package was documented:\n%s", text)
+	}
+}
@@ -0,0 +1,664 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/format"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const scaffoldUsageDescription = `Generate a minimal Go program that imports a package and uses one of its
+symbols: a function or method is called with placeholder arguments declared
+from its signature (zero values, or context.Background() for a
+context.Context), its results are assigned and the error checked; a type is
+declared, with a struct's exported fields listed; a constant or variable is
+printed. Imports are taken from the package's own source, so paths and call
+shapes are real rather than guessed. The placeholders compile but usually do
+nothing useful: replace them before running. Type arguments of generic
+symbols are guesses from their constraints and noted as such.`
+
+// scaffold accumulates the imports, identifiers, and notes of a generated
+// usage program for a symbol of lp.
+type scaffold struct {
+	lp         *loadedPackage
+	pkgName    string
+	imports    map[string]string   // import path to the name the program uses
+	used       map[string]bool     // identifiers taken in main
+	typeParams map[string]ast.Expr // type parameter to its constraint
+	typeArgs   map[string]string   // type parameter to its placeholder argument
+	notes      []string
+}
+
+func (gs *godocServer) handleScaffoldUsage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	target, err := request.RequireString("target")
+	if err != nil {
+		return mcp.NewToolResultError("target argument is required"), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if dp.Name == "main" {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is a command (package main) and cannot be imported", pkgPath)), nil
+	}
+	sym, ok := findSymbol(dp, target)
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("symbol %s not found in %s", target, pkgPath)), nil
+	}
+
+	src, err := scaffoldUsage(lp, dp, sym)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(src), nil
+}
+
+// scaffoldUsage returns the source of a gofmt-formatted main package using
+// sym, which is declared in lp with documentation dp.
+func scaffoldUsage(lp *loadedPackage, dp *doc.Package, sym docSymbol) (string, error) {
+	s := &scaffold{
+		lp:         lp,
+		pkgName:    dp.Name,
+		imports:    map[string]string{lp.path: dp.Name},
+		used:       map[string]bool{"main": true, "fmt": true, "log": true, "context": true, dp.Name: true},
+		typeParams: make(map[string]ast.Expr),
+		typeArgs:   make(map[string]string),
+	}
+
+	var body []string
+	switch sym.kind {
+	case "func", "method":
+		body = s.callLines(sym.decl.(*ast.FuncDecl), sym.name)
+	case "type":
+		body = s.typeLines(sym.name)
+	default:
+		s.use("fmt", "fmt")
+		body = []string{fmt.Sprintf("fmt.Println(%s.%s)", s.pkgName, sym.name)}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Scaffold for %s.%s: the placeholder values compile but must be\n// replaced before the program does anything useful.\n", lp.path, sym.name)
+	for _, note := range s.notes {
+		fmt.Fprintf(&b, "//\n// NOTE: %s\n", note)
+	}
+	b.WriteString("package main\n\nimport (\n")
+	paths := make([]string, 0, len(s.imports))
+	for path := range s.imports {
+		paths = append(paths, path)
+	}
+	// Standard library imports come first, in their own group.
+	slices.SortFunc(paths, func(a, b string) int {
+		if isStdLib(a) != isStdLib(b) {
+			if isStdLib(a) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	for i, path := range paths {
+		if i > 0 && isStdLib(paths[i-1]) && !isStdLib(path) {
+			b.WriteString("\n")
+		}
+		if name := s.imports[path]; name != importDefaultName(path) {
+			fmt.Fprintf(&b, "\t%s %q\n", name, path)
+		} else {
+			fmt.Fprintf(&b, "\t%q\n", path)
+		}
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+	for _, line := range body {
+		b.WriteString("\t" + line + "\n")
+	}
+	b.WriteString("}\n")
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", fmt.Errorf("failed to format scaffold for %s: %w", sym.name, err)
+	}
+	return string(src), nil
+}
+
+// callLines returns the statements calling fd, named name ("F" or
+// "Type.Method"): placeholder declarations for the receiver and arguments,
+// the call, and uses of its results.
+func (s *scaffold) callLines(fd *ast.FuncDecl, name string) []string {
+	var lines, vars []string
+	callee := s.pkgName + "." + name
+
+	if fd.Recv != nil && len(fd.Recv.List) == 1 {
+		field := fd.Recv.List[0]
+		s.bindReceiverTypeParams(field.Type)
+		recvType := s.typeString(field.Type)
+		recv := s.varName(fieldName(field), field.Type)
+		typeName, _, _ := strings.Cut(name, ".")
+		lines = append(lines, fmt.Sprintf("// TODO: obtain a %s.%s%s.", s.pkgName, typeName, s.constructorHint(typeName)))
+		vars = append(vars, recv+" "+recvType)
+		callee = recv + "." + fd.Name.Name
+	}
+	s.addTypeParams(fd.Type.TypeParams)
+
+	var args []string
+	if fd.Type.Params != nil {
+		for _, field := range fd.Type.Params.List {
+			typ := s.typeString(field.Type)
+			names := []string{""}
+			if len(field.Names) > 0 {
+				names = names[:0]
+				for _, id := range field.Names {
+					names = append(names, id.Name)
+				}
+			}
+			for _, n := range names {
+				v := s.varName(n, field.Type)
+				switch {
+				case s.isContext(field.Type):
+					lines = append(lines, v+" := "+strings.TrimSuffix(typ, "Context")+"Background()")
+					args = append(args, v)
+				case strings.HasPrefix(typ, "..."):
+					vars = append(vars, v+" []"+typ[3:])
+					args = append(args, v+"...")
+				default:
+					vars = append(vars, v+" "+typ)
+					args = append(args, v)
+				}
+			}
+		}
+	}
+	if len(vars) == 1 {
+		lines = append(lines, "var "+vars[0])
+	} else if len(vars) > 1 {
+		lines = append(lines, "var (")
+		for _, v := range vars {
+			lines = append(lines, "\t"+v)
+		}
+		lines = append(lines, ")")
+	}
+
+	if explicit := s.explicitTypeArgs(fd.Type); explicit != "" {
+		callee += explicit
+	}
+	call := callee + "(" + strings.Join(args, ", ") + ")"
+
+	var results []string
+	errResult := ""
+	if fd.Type.Results != nil {
+		for _, field := range fd.Type.Results.List {
+			count := max(len(field.Names), 1)
+			for i := range count {
+				n := ""
+				if len(field.Names) > 0 {
+					n = field.Names[i].Name
+				}
+				v := s.varName(n, field.Type)
+				results = append(results, v)
+				if id, ok := field.Type.(*ast.Ident); ok && id.Name == "error" {
+					errResult = v
+				}
+			}
+		}
+	}
+	if errResult != "" && errResult != results[len(results)-1] {
+		errResult = ""
+	}
+
+	switch {
+	case len(results) == 0:
+		lines = append(lines, call)
+	case len(results) == 1 && errResult != "":
+		s.use("log", "log")
+		lines = append(lines, fmt.Sprintf("if %s := %s; %s != nil {", errResult, call, errResult), "\tlog.Fatal("+errResult+")", "}")
+	default:
+		lines = append(lines, strings.Join(results, ", ")+" := "+call)
+		printed := results
+		if errResult != "" {
+			s.use("log", "log")
+			lines = append(lines, "if "+errResult+" != nil {", "\tlog.Fatal("+errResult+")", "}")
+			printed = results[:len(results)-1]
+		}
+		s.use("fmt", "fmt")
+		lines = append(lines, "fmt.Println("+strings.Join(printed, ", ")+")")
+	}
+	return lines
+}
+
+// isContext reports whether expr, declared in s.lp, is context.Context.
+func (s *scaffold) isContext(expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Context" {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && fileImport(s.lp, sel.Pos(), pkg.Name) == "context"
+}
+
+// typeLines returns the statements declaring a value of the type named name.
+// A struct is built as a composite literal with its exported fields listed
+// as comments to fill in.
+func (s *scaffold) typeLines(name string) []string {
+	ts, _ := findTypeSpec(s.lp.files, name)
+	if ts == nil {
+		return nil
+	}
+	typ := s.pkgName + "." + name
+	if ts.TypeParams != nil {
+		s.addTypeParams(ts.TypeParams)
+		var args []string
+		for _, field := range ts.TypeParams.List {
+			for _, id := range field.Names {
+				args = append(args, s.typeArg(id.Name))
+			}
+		}
+		typ += "[" + strings.Join(args, ", ") + "]"
+	}
+
+	v := s.varName("", ts.Name)
+	var lines []string
+	if hint := s.constructorHint(name); hint != "" {
+		lines = append(lines, fmt.Sprintf("// TODO: obtain a %s.%s%s.", s.pkgName, name, hint))
+	}
+	if st, ok := ts.Type.(*ast.StructType); ok {
+		lines = append(lines, v+" := "+typ+"{")
+		for _, field := range st.Fields.List {
+			for _, id := range field.Names {
+				if id.IsExported() {
+					lines = append(lines, "\t// "+id.Name+": "+s.commentType(field.Type)+",")
+				}
+			}
+		}
+		lines = append(lines, "}")
+	} else {
+		lines = append(lines, "var "+v+" "+typ)
+	}
+	s.use("fmt", "fmt")
+	return append(lines, "fmt.Println("+v+")")
+}
+
+// commentType renders a field type for a comment, qualifying package-level
+// names without importing anything.
+func (s *scaffold) commentType(expr ast.Expr) string {
+	imports, notes := s.imports, s.notes
+	s.imports = make(map[string]string)
+	defer func() { s.imports, s.notes = imports, notes }()
+	return s.typeString(expr)
+}
+
+// constructorHint returns ", e.g. from pkg.NewT" naming the functions go/doc
+// associates with typeName as constructors, or "".
+func (s *scaffold) constructorHint(typeName string) string {
+	dp, err := s.lp.docPackage()
+	if err != nil {
+		return ""
+	}
+	for _, t := range dp.Types {
+		if t.Name != typeName || len(t.Funcs) == 0 {
+			continue
+		}
+		var names []string
+		for _, f := range t.Funcs {
+			if token.IsExported(f.Name) {
+				names = append(names, s.pkgName+"."+f.Name)
+			}
+		}
+		if len(names) > 0 {
+			return ", e.g. from " + strings.Join(names, " or ")
+		}
+	}
+	return ""
+}
+
+// use records that the program imports path under name and returns name.
+func (s *scaffold) use(path, name string) string {
+	if existing, ok := s.imports[path]; ok {
+		return existing
+	}
+	s.imports[path] = name
+	s.used[name] = true
+	return name
+}
+
+// addTypeParams records the constraints of the type parameters in list.
+func (s *scaffold) addTypeParams(list *ast.FieldList) {
+	if list == nil {
+		return
+	}
+	for _, field := range list.List {
+		for _, id := range field.Names {
+			s.typeParams[id.Name] = field.Type
+		}
+	}
+}
+
+// bindReceiverTypeParams records the constraints of the type parameters a
+// method receiver such as *List[T] names, taken positionally from the type's
+// declaration.
+func (s *scaffold) bindReceiverTypeParams(recv ast.Expr) {
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	var base ast.Expr
+	var params []ast.Expr
+	switch x := recv.(type) {
+	case *ast.IndexExpr:
+		base, params = x.X, []ast.Expr{x.Index}
+	case *ast.IndexListExpr:
+		base, params = x.X, x.Indices
+	default:
+		return
+	}
+	id, ok := base.(*ast.Ident)
+	if !ok {
+		return
+	}
+	ts, _ := findTypeSpec(s.lp.files, id.Name)
+	if ts == nil || ts.TypeParams == nil {
+		return
+	}
+	var constraints []ast.Expr
+	for _, field := range ts.TypeParams.List {
+		for range field.Names {
+			constraints = append(constraints, field.Type)
+		}
+	}
+	for i, p := range params {
+		if pid, ok := p.(*ast.Ident); ok && i < len(constraints) {
+			s.typeParams[pid.Name] = constraints[i]
+		}
+	}
+}
+
+// explicitTypeArgs returns the instantiation "[A, B]" for a generic function
+// when some type parameter occurs neither in its parameters nor in the
+// constraint of one that does, and so cannot be inferred, or "".
+func (s *scaffold) explicitTypeArgs(ft *ast.FuncType) string {
+	if ft.TypeParams == nil {
+		return ""
+	}
+	inferred := make(map[string]bool)
+	var mark func(n ast.Node) bool
+	mark = func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || inferred[id.Name] {
+			return true
+		}
+		inferred[id.Name] = true
+		if c, ok := s.typeParams[id.Name]; ok && c != nil {
+			ast.Inspect(c, mark)
+		}
+		return true
+	}
+	if ft.Params != nil {
+		ast.Inspect(ft.Params, mark)
+	}
+	var args []string
+	all := true
+	for _, field := range ft.TypeParams.List {
+		for _, id := range field.Names {
+			args = append(args, s.typeArg(id.Name))
+			all = all && inferred[id.Name]
+		}
+	}
+	if all {
+		return ""
+	}
+	return "[" + strings.Join(args, ", ") + "]"
+}
+
+// typeArg returns the placeholder type argument for type parameter name,
+// derived from its constraint.
+func (s *scaffold) typeArg(name string) string {
+	if arg, ok := s.typeArgs[name]; ok {
+		return arg
+	}
+	s.typeArgs[name] = "int" // breaks cycles such as [S ~[]E, E ~[]S]
+	arg := s.constraintType(name, s.typeParams[name])
+	s.typeArgs[name] = arg
+	return arg
+}
+
+// knownConstraints maps constraint names of package cmp and
+// golang.org/x/exp/constraints to a type satisfying them.
+var knownConstraints = map[string]string{
+	"Ordered":  "int",
+	"Integer":  "int",
+	"Signed":   "int",
+	"Unsigned": "uint",
+	"Float":    "float64",
+	"Complex":  "complex128",
+}
+
+// constraintType returns a type satisfying constraint c of type parameter
+// name: the first term of a type set, or int for any and comparable. Other
+// constraints get int and a note.
+func (s *scaffold) constraintType(name string, c ast.Expr) string {
+	switch x := c.(type) {
+	case nil:
+		return "int"
+	case *ast.Ident:
+		if x.Name == "any" || x.Name == "comparable" {
+			return "int"
+		}
+		if ts, _ := findTypeSpec(s.lp.files, x.Name); ts != nil {
+			if it, ok := ts.Type.(*ast.InterfaceType); ok {
+				return s.constraintType(name, it)
+			}
+		}
+	case *ast.SelectorExpr:
+		if t, ok := knownConstraints[x.Sel.Name]; ok {
+			return t
+		}
+	case *ast.InterfaceType:
+		var methods []string
+		for _, field := range x.Methods.List {
+			if len(field.Names) == 0 {
+				return s.constraintType(name, field.Type)
+			}
+			methods = append(methods, field.Names[0].Name)
+		}
+		if len(methods) == 0 {
+			return "int"
+		}
+	case *ast.BinaryExpr:
+		return s.constraintType(name, x.X)
+	case *ast.UnaryExpr:
+		return s.typeString(x.X)
+	default:
+		return s.typeString(c)
+	}
+	s.notes = append(s.notes, fmt.Sprintf("int is a placeholder for type parameter %s, which is constrained by %s; choose a type that satisfies it.", name, s.commentType(c)))
+	return "int"
+}
+
+// typeString renders type expression expr, declared in s.lp, as it is
+// written in the generated program: package-level names qualified, other
+// packages imported under the names the declaring file uses, and type
+// parameters replaced by their placeholder arguments.
+func (s *scaffold) typeString(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		if _, ok := s.typeParams[x.Name]; ok {
+			return s.typeArg(x.Name)
+		}
+		if types.Universe.Lookup(x.Name) != nil {
+			return x.Name
+		}
+		if !x.IsExported() {
+			s.notes = append(s.notes, fmt.Sprintf("%s.%s is unexported, so code outside the package cannot name it; find an exported way to obtain one.", s.pkgName, x.Name))
+		}
+		return s.pkgName + "." + x.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := x.X.(*ast.Ident); ok {
+			if path := fileImport(s.lp, x.Pos(), pkg.Name); path != "" {
+				return s.use(path, pkg.Name) + "." + x.Sel.Name
+			}
+		}
+		return nodeString(s.lp.fset, x)
+	case *ast.StarExpr:
+		return "*" + s.typeString(x.X)
+	case *ast.ParenExpr:
+		return "(" + s.typeString(x.X) + ")"
+	case *ast.Ellipsis:
+		return "..." + s.typeString(x.Elt)
+	case *ast.ArrayType:
+		if x.Len == nil {
+			return "[]" + s.typeString(x.Elt)
+		}
+		return "[" + nodeString(s.lp.fset, x.Len) + "]" + s.typeString(x.Elt)
+	case *ast.MapType:
+		return "map[" + s.typeString(x.Key) + "]" + s.typeString(x.Value)
+	case *ast.ChanType:
+		switch x.Dir {
+		case ast.SEND:
+			return "chan<- " + s.typeString(x.Value)
+		case ast.RECV:
+			return "<-chan " + s.typeString(x.Value)
+		}
+		return "chan " + s.typeString(x.Value)
+	case *ast.FuncType:
+		sig := "func(" + s.fieldTypes(x.Params) + ")"
+		if x.Results != nil && len(x.Results.List) > 0 {
+			results := s.fieldTypes(x.Results)
+			if len(x.Results.List) > 1 || len(x.Results.List[0].Names) > 1 {
+				results = "(" + results + ")"
+			}
+			sig += " " + results
+		}
+		return sig
+	case *ast.IndexExpr:
+		return s.typeString(x.X) + "[" + s.typeString(x.Index) + "]"
+	case *ast.IndexListExpr:
+		args := make([]string, len(x.Indices))
+		for i, index := range x.Indices {
+			args[i] = s.typeString(index)
+		}
+		return s.typeString(x.X) + "[" + strings.Join(args, ", ") + "]"
+	}
+	return nodeString(s.lp.fset, expr)
+}
+
+// fieldTypes renders the types of a parameter or result list, one per
+// declared name.
+func (s *scaffold) fieldTypes(list *ast.FieldList) string {
+	if list == nil {
+		return ""
+	}
+	var parts []string
+	for _, field := range list.List {
+		typ := s.typeString(field.Type)
+		for range max(len(field.Names), 1) {
+			parts = append(parts, typ)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// fieldName returns the single name of a receiver field, or "".
+func fieldName(field *ast.Field) string {
+	if len(field.Names) == 1 {
+		return field.Names[0].Name
+	}
+	return ""
+}
+
+// varName returns an unused identifier for a value of type expr: preferred
+// if it is a usable name, or one derived from the type.
+func (s *scaffold) varName(preferred string, expr ast.Expr) string {
+	name := preferred
+	if name == "" || name == "_" {
+		name = typeVarName(expr)
+	}
+	if token.IsKeyword(name) {
+		name = name[:1]
+	}
+	v := name
+	for i := 2; s.used[v]; i++ {
+		v = name + strconv.Itoa(i)
+	}
+	s.used[v] = true
+	return v
+}
+
+// predeclaredVarNames are the conventional variable names for values of
+// predeclared types.
+var predeclaredVarNames = map[string]string{
+	"error": "err", "string": "s", "bool": "ok", "byte": "b", "rune": "r",
+	"any": "v", "float32": "f", "float64": "f", "complex64": "c", "complex128": "c",
+}
+
+// typeVarName derives a variable name from a type: err for error, n for
+// integers, and the type name in lower camel case for named types, such as
+// httpClient for *http.HTTPClient.
+func typeVarName(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		if name, ok := predeclaredVarNames[x.Name]; ok {
+			return name
+		}
+		if strings.HasPrefix(x.Name, "int") || strings.HasPrefix(x.Name, "uint") {
+			return "n"
+		}
+		return lowerCamel(x.Name)
+	case *ast.SelectorExpr:
+		return lowerCamel(x.Sel.Name)
+	case *ast.StarExpr:
+		return typeVarName(x.X)
+	case *ast.Ellipsis:
+		return typeVarName(x.Elt)
+	case *ast.ArrayType:
+		if id, ok := x.Elt.(*ast.Ident); ok && id.Name == "byte" {
+			return "data"
+		}
+		return typeVarName(x.Elt)
+	case *ast.IndexExpr:
+		return typeVarName(x.X)
+	case *ast.IndexListExpr:
+		return typeVarName(x.X)
+	case *ast.MapType:
+		return "m"
+	case *ast.ChanType:
+		return "ch"
+	case *ast.FuncType:
+		return "fn"
+	}
+	return "v"
+}
+
+// lowerCamel lowers the leading upper-case run of name, keeping the last
+// letter of an initialism that starts the next word: URL becomes url and
+// HTTPClient httpClient.
+func lowerCamel(name string) string {
+	r := []rune(name)
+	n := 0
+	for n < len(r) && unicode.IsUpper(r[n]) {
+		n++
+	}
+	if n > 1 && n < len(r) {
+		n--
+	}
+	for i := range n {
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleScaffoldUsage(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/store\n\ngo 1.21\n",
+		"store.go": `// Package kv stores values.
+package kv
+
+import (
+	"context"
+	stdio "io"
+	"time"
+)
+
+// Limit caps the number of keys.
+const Limit = 10
+
+// Options configures a Client.
+type Options struct {
+	Addr    string
+	Timeout time.Duration
+	retries int
+}
+
+// Client talks to a store.
+type Client struct{ opts Options }
+
+// Dial connects to addr.
+func Dial(ctx context.Context, opts Options, hooks ...func(string)) (*Client, error) {
+	return &Client{opts: opts}, nil
+}
+
+// Get reads key into w.
+func (c *Client) Get(ctx context.Context, key string, w stdio.Writer) (n int64, err error) {
+	return 0, nil
+}
+
+// Close disconnects.
+func (c *Client) Close() error { return nil }
+
+// Decode parses data as a T.
+func Decode[T any](data []byte) (T, error) {
+	var v T
+	return v, nil
+}
+
+// Max returns the largest element of s.
+func Max[S ~[]E, E interface{ ~int | ~float64 }](s S) E {
+	var m E
+	return m
+}
+`,
+	})
+
+	gs := newGodocServer()
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"Dial", []string{
+			"\"fmt\"\n\t\"log\"\n\n\tkv \"example.com/store\"",
+			"ctx := context.Background()",
+			"hooks []func(string)",
+			"client, err := kv.Dial(ctx, opts, hooks...)",
+			"log.Fatal(err)",
+			"fmt.Println(client)",
+		}},
+		{"Client.Get", []string{
+			`stdio "io"`,
+			"// TODO: obtain a kv.Client, e.g. from kv.Dial.",
+			"c   *kv.Client",
+			"w   stdio.Writer",
+			"n, err := c.Get(ctx, key, w)",
+		}},
+		{"Client.Close", []string{"if err := c.Close(); err != nil {"}},
+		{"Decode", []string{"t, err := kv.Decode[int](data)", "var data []byte"}},
+		{"Max", []string{"var s []int", "e := kv.Max(s)"}},
+		{"Options", []string{"options := kv.Options{", "// Addr: string,", "// Timeout: time.Duration,"}},
+		{"Limit", []string{"fmt.Println(kv.Limit)"}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			req := mcp.CallToolRequest{}
+			req.Params.Name = "scaffold_usage"
+			req.Params.Arguments = map[string]any{"path": ".", "target": tt.target, "working_dir": dir}
+			result, err := gs.handleScaffoldUsage(context.Background(), req)
+			if err != nil {
+				t.Fatalf("handleScaffoldUsage returned protocol error: %v", err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if result.IsError {
+				t.Fatalf("handleScaffoldUsage returned tool error: %s", text)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("scaffold missing %q:\n%s", want, text)
+				}
+			}
+			if strings.Contains(text, "retries") {
+				t.Errorf("scaffold lists an unexported field:\n%s", text)
+			}
+
+			// Every scaffold must compile against the real package.
+			cmd := filepath.Join(dir, "cmd", fmt.Sprint("s", i))
+			if err := os.MkdirAll(cmd, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(cmd, "main.go"), []byte(text), 0644); err != nil {
+				t.Fatal(err)
+			}
+			build := exec.Command("go", "build", "-o", os.DevNull, "./cmd/"+filepath.Base(cmd))
+			build.Dir = dir
+			if out, err := build.CombinedOutput(); err != nil {
+				t.Errorf("scaffold does not compile: %v\n%s\n%s", err, out, text)
+			}
+		})
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "scaffold_usage"
+	req.Params.Arguments = map[string]any{"path": ".", "target": "Missing", "working_dir": dir}
+	result, err := gs.handleScaffoldUsage(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleScaffoldUsage returned protocol error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected an error for a missing symbol")
+	}
+}
+
+func TestTypeVarName(t *testing.T) {
+	tests := []struct {
+		typ  string
+		want string
+	}{
+		{"error", "err"},
+		{"int64", "n"},
+		{"*http.Client", "client"},
+		{"*HTTPClient", "httpClient"},
+		{"URL", "url"},
+		{"[]byte", "data"},
+		{"map[string]int", "m"},
+		{"List[T]", "list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tt.typ)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := typeVarName(expr); got != tt.want {
+				t.Errorf("typeVarName(%s) = %q, want %q", tt.typ, got, tt.want)
+			}
+		})
+	}
+}
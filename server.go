@@ -1,15 +1,26 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"go/build"
+	"go/token"
+	goversion "go/version"
 	"log"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -21,8 +32,47 @@ const (
 	projectTTL   = 30 * time.Minute
 	maxCacheSize = 500
 	cmdTimeout   = 30 * time.Second
+
+	// maxCmdTimeout caps the per-request timeout_seconds override.
+	maxCmdTimeout = 5 * time.Minute
+
+	defaultTempModule = "godoc-temp"
+
+	// defaultMaxTempProjects caps the temporary project directories on disk
+	// unless configured otherwise.
+	defaultMaxTempProjects = 64
 )
 
+// errNoBuildableFiles reports that build constraints exclude every file of a
+// package for the current platform.
+var errNoBuildableFiles = errors.New("no Go files for current platform")
+
+// errSymbolNotFound reports that the package exists but has no symbol,
+// method, or field with the requested name.
+var errSymbolNotFound = errors.New("symbol not found")
+
+// errModuleBuild reports that go doc failed because the package or its module
+// is broken (syntax errors, an invalid go.mod, inconsistent dependencies),
+// not because the package does not exist.
+var errModuleBuild = errors.New("the module failed to build")
+
+// errGoDocTimeout reports that go doc did not finish within the command
+// timeout.
+var errGoDocTimeout = errors.New("go doc timed out")
+
+// errToolchain reports that the module needs a newer Go toolchain than the
+// one installed and the go command could not switch to it.
+var errToolchain = errors.New("a newer Go toolchain is required")
+
+// toolchainErrorPattern matches the go command's refusal to run a module
+// whose go or toolchain line is newer than the installed toolchain, either
+// because GOTOOLCHAIN forbids switching or because the download failed.
+var toolchainErrorPattern = regexp.MustCompile(`requires go >= (\S+) \(running go (\S+); GOTOOLCHAIN=(\S+)\)|download (go\S+) for \S+: toolchain not available`)
+
+// buildErrorPattern matches go command output lines that indicate a broken
+// package or module rather than a missing one.
+var buildErrorPattern = regexp.MustCompile(`\.go:\d+(?::\d+)?: |found packages \S+ \(.*\) and |errors parsing go\.mod|go\.mod:\d+: |missing go\.sum entry|updates to go\.mod needed|ambiguous import|requires go >= |checksum mismatch`)
+
 // allowedFlags is the set of go doc flags permitted via cmd_flags.
 var allowedFlags = map[string]bool{
 	"-all":   true,
@@ -48,7 +98,8 @@ Common Usage Patterns:
 - External packages: Use full import path (e.g., "github.com/user/repo")
 - Local packages: Use relative path (e.g., "./pkg") or absolute path
 
-The documentation is cached for 5 minutes to improve performance.`
+The documentation is cached for 5 minutes to improve performance; local packages stay
+cached until their source files change.`
 
 const listPackagesDescription = `List all sub-packages under a Go package path.
 Use this to discover the correct import paths for sub-packages when you see types
@@ -64,20 +115,74 @@ type cachedDoc struct {
 
 type cachedProject struct {
 	dir       string
-	timestamp time.Time
+	timestamp time.Time // created or last reused after expiry; governs projectTTL
+	lastUsed  time.Time // last handed to a request; orders eviction
+	refs      int       // requests still using dir; guarded by godocServer.mu
+	dropped   bool      // no longer cached; dir is removed once refs drops to 0
+}
+
+// projectLeasesKey is the context key under which a tool call collects the
+// projects it uses, so they are not removed until the call returns.
+type projectLeasesKey struct{}
+
+// projectLeases lists the projects a tool call holds; guarded by
+// godocServer.mu.
+type projectLeases struct {
+	projects []*cachedProject
 }
 
 type godocServer struct {
-	mcpServer *server.MCPServer
-	mu        sync.Mutex
-	cache     map[string]cachedDoc
-	projects  map[string]cachedProject
+	mcpServer  *server.MCPServer
+	mu         sync.Mutex // guards projects and parsed
+	cache      *docCache
+	parseCache *parseCache // nil when disabled
+	projects   map[string]*cachedProject
+	parsed     map[string]*parsedPackage
+	pool       *processPool
+
+	// projectSlots holds one token per temporary project directory on disk.
+	projectSlots chan struct{}
+	// projectIdle is signalled when a cached project is no longer in use, so
+	// requests waiting for a slot can retry eviction.
+	projectIdle chan struct{}
+
+	tempModule    string
+	tempGoVersion string
+	sessionCache  bool
+	denyPackages  []string
+	allowedRoots  []string // canonical directories working_dir must be inside; nil allows any
+
+	responsePreamble string
+	cleanEnv         []string        // environment for go subprocesses; nil inherits the server's
+	includeStderr    bool            // append go doc's stderr warnings to its documentation
+	disassembly      bool            // register the disassemble tool
+	gitTools         bool            // register api_diff, which runs git
+	testTools        bool            // register test_coverage, which runs go test
+	goGet            bool            // let get_doc run go get, editing go.mod, under mod_mode=mod
+	tools            map[string]bool // names of the tools to register; nil registers all
+	goToolchain      string          // GOTOOLCHAIN for go subprocesses; "" inherits the environment
+	maxArgLength     int             // longest string argument accepted, in bytes
+	maxArrayLength   int             // most items accepted in an array argument
+	modCache         string          // GOMODCACHE for go subprocesses; "" inherits the environment
+	warmupDirs       []string        // module directories whose build cache is warmed at startup
+	warmupCommand    string          // go subcommand run for warmup: build or vet
 }
 
-func newGodocServer() *godocServer {
+func newGodocServer(opts ...serverOption) *godocServer {
 	gs := &godocServer{
-		cache:    make(map[string]cachedDoc),
-		projects: make(map[string]cachedProject),
+		cache:          newDocCache(defaultCacheShards),
+		parseCache:     newParseCache(defaultParseCacheSize),
+		projects:       make(map[string]*cachedProject),
+		parsed:         make(map[string]*parsedPackage),
+		pool:           newProcessPool(runtime.NumCPU()),
+		projectSlots:   make(chan struct{}, defaultMaxTempProjects),
+		projectIdle:    make(chan struct{}, 1),
+		tempModule:     defaultTempModule,
+		maxArgLength:   defaultMaxArgLength,
+		maxArrayLength: defaultMaxArrayLength,
+	}
+	for _, opt := range opts {
+		opt(gs)
 	}
 
 	s := server.NewMCPServer(
@@ -91,13 +196,15 @@ func newGodocServer() *godocServer {
 
 	tool := mcp.NewTool("get_doc",
 		mcp.WithDescription(toolDescription),
-		mcp.WithReadOnlyHintAnnotation(true),
+		// With -enable-go-get, get_doc may edit the working_dir module's
+		// go.mod and go.sum.
+		mcp.WithReadOnlyHintAnnotation(!gs.goGet),
 		mcp.WithString("path",
 			mcp.Required(),
-			mcp.Description("Path to the Go package or file. Import path (e.g., 'io', 'github.com/user/repo') or local file path."),
+			mcp.Description("Path to the Go package or file. Import path (e.g., 'io', 'github.com/user/repo') or local file path. Append '@v1.2.3' to pick a version, or '@head' for the latest commit on the default branch."),
 		),
-		mcp.WithString("target",
-			mcp.Description("Specific symbol to document (function, type, interface). Leave empty for full package docs."),
+		mcp.WithAny("target",
+			mcp.Description("Specific symbol to document (function, type, interface), or an array of symbols in the same package to document together. Leave empty for full package docs."),
 		),
 		mcp.WithArray("cmd_flags",
 			mcp.Description("Additional go doc flags: -all (all docs), -src (source code), -u (unexported symbols), -short, -c."),
@@ -117,8 +224,103 @@ func newGodocServer() *godocServer {
 			mcp.Max(5000),
 			mcp.DefaultNumber(1000),
 		),
+		mcp.WithBoolean("show_metadata",
+			mcp.Description("Include the 'Page N of M' metadata line. When false, it is omitted for single-page results."),
+			mcp.DefaultBool(true),
+		),
+		mcp.WithBoolean("resolve_aliases",
+			mcp.Description("When target is a type alias or re-exported variable, note where it is really declared and include that documentation."),
+		),
+		mcp.WithString("unexported",
+			mcp.Description("Unexported symbols to include: 'none', 'all' (same as -u), or 'types' (unexported types only, to understand internal data structures)."),
+			mcp.Enum("none", "all", "types"),
+			mcp.DefaultString("none"),
+		),
+		mcp.WithBoolean("force_parse",
+			mcp.Description("If build constraints exclude every file, parse the package source directly (ignoring build tags) and list the exported symbols found."),
+		),
+		mcp.WithNumber("timeout_seconds",
+			mcp.Description("Override the 30 second timeout for each go command in this request, e.g. when a large module times out while downloading. Max 300."),
+			mcp.Min(1),
+			mcp.Max(300),
+		),
+		mcp.WithString("mod_mode",
+			mcp.Description("Module download mode for the working_dir module, passed to the go command as -mod (e.g., 'mod' to resolve a dependency missing from go.mod, 'vendor' to use the vendor directory). Under 'mod', if the server was started with -enable-go-get, a package whose module is only in the pruned module graph is added to go.mod with go get."),
+			mcp.Enum("readonly", "mod", "vendor"),
+		),
+		mcp.WithString("goexperiment",
+			mcp.Description("GOEXPERIMENT setting for the go command, e.g. 'arenas' or 'rangefunc,noloopvar', to document APIs that only exist behind an experiment."),
+		),
+		mcp.WithBoolean("no_cache",
+			mcp.Description("Run go doc even if the result is cached, e.g. right after editing local code. The fresh result replaces the cached one."),
+		),
+		mcp.WithBoolean("strip_header",
+			mcp.Description("Drop the leading 'package X // import \"...\"' line. Defaults to true for symbol queries (target set) and false for package queries."),
+		),
+		mcp.WithBoolean("signature_only",
+			mcp.Description("For symbol queries, return only the declaration (parameter and return types, or a type's definition and method signatures) with no doc comments. The most token-efficient way to learn how to call something."),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Symbols to leave out of a package listing (e.g. ['Client', 'Client.Do', 'ErrNotFound']), such as ones already known or generated noise. A type is omitted with its constructors and methods. Works with -all, -short, -src, -u, and synopsis; not with a target."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString("fields",
+			mcp.Description("For a struct target, show only the fields whose names contain this substring (case-insensitive), e.g. 'timeout' to drill into the timeout settings of a large config struct. Not with signature_only or synopsis."),
+		),
+		mcp.WithBoolean("documented_fields",
+			mcp.Description("For a struct target, show only the fields that have a doc or line comment. Combines with fields."),
+		),
+		mcp.WithBoolean("synopsis",
+			mcp.Description("Return each symbol's one-line signature with only the first sentence of its doc comment: for a package, every exported symbol; for a target, the symbol and, for a type, its constructors and methods. A scannable middle ground between -short and the full documentation."),
+		),
+		mcp.WithBoolean("normalize",
+			mcp.Description("Normalize indentation: declarations and code blocks indented four spaces, prose flush left."),
+		),
+		mcp.WithBoolean("include_returned_type",
+			mcp.Description("For a function or method target, also document the struct or interface type it returns (e.g. io.ReadCloser for io.NopCloser). Follows one level, within the same module or the standard library."),
+		),
+		mcp.WithBoolean("wrap_signatures",
+			mcp.Description("Write function signatures with 5 or more parameters, or longer than 100 characters, one parameter per line."),
+		),
+	)
+	gs.addTool(tool, gs.handleGetDoc)
+
+	getStartedTool := mcp.NewTool("get_started",
+		mcp.WithDescription(getStartedDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of roadmap steps to show (default: 20)."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
 	)
-	s.AddTool(tool, gs.handleGetDoc)
+	gs.addTool(getStartedTool, gs.handleGetStarted)
+
+	docStructureTool := mcp.NewTool("doc_structure",
+		mcp.WithDescription(docStructureDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("target",
+			mcp.Description("Symbol whose doc comment to parse (e.g., 'Reader' or 'Client.Do'). Omit for the package comment."),
+		),
+		mcp.WithString("format",
+			mcp.Description("Text rendering of the result: 'json' (default) for the structure itself, or 'text', 'markdown', 'rst', or 'adoc' to render the documentation in that markup. The structured content is returned either way."),
+			mcp.Enum(docFormats...),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+		mcp.WithOutputSchema[structuredDoc](),
+	)
+	gs.addTool(docStructureTool, gs.handleDocStructure)
 
 	listTool := mcp.NewTool("list_packages",
 		mcp.WithDescription(listPackagesDescription),
@@ -131,28 +333,558 @@ func newGodocServer() *godocServer {
 			mcp.Description("Working directory for module context. Required for relative paths."),
 		),
 	)
-	s.AddTool(listTool, gs.handleListPackages)
+	gs.addTool(listTool, gs.handleListPackages)
+
+	rankTool := mcp.NewTool("rank_packages",
+		mcp.WithDescription(rankPackagesDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Root package or module import path (e.g., 'net', 'github.com/user/repo', 'golang.org/x/tools') or local path."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(rankTool, gs.handleRankPackages)
+
+	readLinesTool := mcp.NewTool("read_lines",
+		mcp.WithDescription(readLinesDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("file",
+			mcp.Required(),
+			mcp.Description("Path to the file. Absolute, or relative to working_dir."),
+		),
+		mcp.WithNumber("start_line",
+			mcp.Description("First line to return (1-based)."),
+			mcp.Min(1),
+			mcp.DefaultNumber(1),
+		),
+		mcp.WithNumber("end_line",
+			mcp.Description("Last line to return (inclusive). Defaults to start_line plus 1999."),
+			mcp.Min(1),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Module directory whose files may be read. Required for relative file paths."),
+		),
+	)
+	gs.addTool(readLinesTool, gs.handleReadLines)
+
+	listFilesTool := mcp.NewTool("list_files",
+		mcp.WithDescription(listFilesDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(listFilesTool, gs.handleListFiles)
+
+	filePackageTool := mcp.NewTool("file_package",
+		mcp.WithDescription(filePackageDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("file",
+			mcp.Required(),
+			mcp.Description("Absolute path of a file in the package, e.g. the file open in the editor."),
+		),
+	)
+	gs.addTool(filePackageTool, gs.handleFilePackage)
+
+	moduleInfoTool := mcp.NewTool("module_info",
+		mcp.WithDescription(moduleInfoDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("working_dir",
+			mcp.Required(),
+			mcp.Description("Directory containing the go.mod to describe."),
+		),
+	)
+	gs.addTool(moduleInfoTool, gs.handleModuleInfo)
+
+	moduleLinksTool := mcp.NewTool("module_links",
+		mcp.WithDescription(moduleLinksDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("module",
+			mcp.Required(),
+			mcp.Description("Module path (e.g., 'github.com/user/repo'), optionally with @version."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Module whose required version of the module to describe. Defaults to the latest version."),
+		),
+	)
+	gs.addTool(moduleLinksTool, gs.handleModuleLinks)
+
+	latestVersionTool := mcp.NewTool("latest_version",
+		mcp.WithDescription(latestVersionDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("module",
+			mcp.Required(),
+			mcp.Description("Module path (e.g., 'github.com/user/repo')."),
+		),
+		mcp.WithBoolean("include_prerelease",
+			mcp.Description("Also list pre-release versions and consider them for latest (default: false)."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Module whose currently required version to compare against the latest."),
+		),
+	)
+	gs.addTool(latestVersionTool, gs.handleLatestVersion)
+
+	selectedVersionTool := mcp.NewTool("selected_version",
+		mcp.WithDescription(selectedVersionDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("module",
+			mcp.Required(),
+			mcp.Description("Module path of the dependency (e.g., 'golang.org/x/net')."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Required(),
+			mcp.Description("Module whose build list to inspect."),
+		),
+	)
+	gs.addTool(selectedVersionTool, gs.handleSelectedVersion)
+
+	dependsOnTool := mcp.NewTool("depends_on",
+		mcp.WithDescription(dependsOnDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("from",
+			mcp.Required(),
+			mcp.Description("Package whose dependencies are searched: import path (e.g., 'net/http', 'github.com/user/repo/api') or local path."),
+		),
+		mcp.WithString("to",
+			mcp.Required(),
+			mcp.Description("Package to look for among the dependencies of 'from' (e.g., 'database/sql', './internal/store')."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(dependsOnTool, gs.handleDependsOn)
+
+	canonicalImportTool := mcp.NewTool("canonical_import",
+		mcp.WithDescription(canonicalImportDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(canonicalImportTool, gs.handleCanonicalImport)
+
+	whatisTool := mcp.NewTool("whatis",
+		mcp.WithDescription(whatisDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("target",
+			mcp.Required(),
+			mcp.Description("Symbol to describe, e.g. 'ReadAll' or 'Reader.Read'."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(whatisTool, gs.handleWhatis)
+
+	symbolExistsTool := mcp.NewTool("symbol_exists",
+		mcp.WithDescription(symbolExistsDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("target",
+			mcp.Required(),
+			mcp.Description("Symbol to look for, e.g. 'ReadAll' or 'Reader.Read'."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+		mcp.WithOutputSchema[symbolExistence](),
+	)
+	gs.addTool(symbolExistsTool, gs.handleSymbolExists)
+
+	listSymbolsTool := mcp.NewTool("list_symbols",
+		mcp.WithDescription(listSymbolsDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithBoolean("exclude_generated",
+			mcp.Description("Leave out symbols declared in generated files (default: false)."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(listSymbolsTool, gs.handleListSymbols)
+
+	listPanicsTool := mcp.NewTool("list_panics",
+		mcp.WithDescription(listPanicsDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(listPanicsTool, gs.handleListPanics)
+
+	apiSizeTool := mcp.NewTool("api_size",
+		mcp.WithDescription(apiSizeDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(apiSizeTool, gs.handleAPISize)
+
+	compareBuildTagsTool := mcp.NewTool("compare_build_tags",
+		mcp.WithDescription(compareBuildTagsDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("tags",
+			mcp.Required(),
+			mcp.Description("Comma-separated candidates to compare against the default build, each a build tag or GOOS/GOARCH pair (e.g., 'purego,cgo,windows/amd64')."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(compareBuildTagsTool, gs.handleCompareBuildTags)
+
+	buildConstraintsTool := mcp.NewTool("build_constraints",
+		mcp.WithDescription(buildConstraintsDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(buildConstraintsTool, gs.handleBuildConstraints)
+
+	concurrencyNotesTool := mcp.NewTool("concurrency_notes",
+		mcp.WithDescription(concurrencyNotesDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("target",
+			mcp.Description("Type to examine (e.g., 'Map'). Omit to summarize every exported type."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(concurrencyNotesTool, gs.handleConcurrencyNotes)
+
+	describeInterfaceTool := mcp.NewTool("describe_interface",
+		mcp.WithDescription(describeInterfaceDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("target",
+			mcp.Required(),
+			mcp.Description("Interface name (e.g., 'Reader')."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(describeInterfaceTool, gs.handleDescribeInterface)
+
+	describeStructTool := mcp.NewTool("describe_struct",
+		mcp.WithDescription(describeStructDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'net/http', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("target",
+			mcp.Required(),
+			mcp.Description("Struct type name (e.g., 'Server')."),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' (fields, tags, and comments) or 'json_schema' (a JSON Schema of the type's JSON encoding)."),
+			mcp.Enum("text", "json_schema"),
+			mcp.DefaultString("text"),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(describeStructTool, gs.handleDescribeStruct)
+
+	methodSetTool := mcp.NewTool("method_set",
+		mcp.WithDescription(methodSetDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'bufio', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("target",
+			mcp.Required(),
+			mcp.Description("Type name (e.g., 'ReadWriter')."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(methodSetTool, gs.handleMethodSet)
+
+	languageHelpTool := mcp.NewTool("language_help",
+		mcp.WithDescription(languageHelpDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("keyword",
+			mcp.Required(),
+			mcp.Description("Keyword, built-in, or topic (e.g., 'select', 'defer', 'append', 'method sets', 'iota')."),
+		),
+	)
+	gs.addTool(languageHelpTool, gs.handleLanguageHelp)
+
+	getExamplesTool := mcp.NewTool("get_examples",
+		mcp.WithDescription(getExamplesDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("target",
+			mcp.Description("Only return examples for this symbol (e.g., 'Reader' or 'Client.Do'). Leave empty for all examples."),
+		),
+		mcp.WithBoolean("include_output",
+			mcp.Description("Include each example's expected output section. Set to false to return only the example code."),
+			mcp.DefaultBool(true),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(getExamplesTool, gs.handleGetExamples)
+
+	usageHintsTool := mcp.NewTool("usage_hints",
+		mcp.WithDescription(usageHintsDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of referenced symbols to rank (default: 15)."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(usageHintsTool, gs.handleUsageHints)
+
+	scaffoldUsageTool := mcp.NewTool("scaffold_usage",
+		mcp.WithDescription(scaffoldUsageDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("target",
+			mcp.Required(),
+			mcp.Description("Function, method, type, constant, or variable to use (e.g., 'Copy' or 'Client.Do')."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(scaffoldUsageTool, gs.handleScaffoldUsage)
+
+	explainErrorTool := mcp.NewTool("explain_error",
+		mcp.WithDescription(explainErrorDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("error",
+			mcp.Required(),
+			mcp.Description("The compiler or runtime error message, or a panic stack trace."),
+		),
+		mcp.WithString("path",
+			mcp.Description("Import path or local path of the package the error was reported in, used to resolve its own types and import names."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(explainErrorTool, gs.handleExplainError)
+
+	proposalLinksTool := mcp.NewTool("proposal_links",
+		mcp.WithDescription(proposalLinksDescription),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Package import path (e.g., 'io', 'github.com/user/repo') or local path."),
+		),
+		mcp.WithString("working_dir",
+			mcp.Description("Working directory for module context. Required for relative paths."),
+		),
+	)
+	gs.addTool(proposalLinksTool, gs.handleProposalLinks)
+
+	// Compiling to assembly is expensive, so the tool is opt-in.
+	if gs.disassembly {
+		disassembleTool := mcp.NewTool("disassemble",
+			mcp.WithDescription(disassembleDescription),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("path",
+				mcp.Required(),
+				mcp.Description("Local package: a relative path such as '.' or './internal/codec', or an import path within the working_dir module."),
+			),
+			mcp.WithString("target",
+				mcp.Required(),
+				mcp.Description("Function or method to disassemble, e.g. 'Encode' or 'Decoder.Next'."),
+			),
+			mcp.WithString("working_dir",
+				mcp.Required(),
+				mcp.Description("Module directory the package is built in."),
+			),
+			mcp.WithNumber("page",
+				mcp.Description("Page number (1-based) for long listings of 1000 lines per page."),
+				mcp.Min(1),
+				mcp.DefaultNumber(1),
+			),
+		)
+		gs.addTool(disassembleTool, gs.handleDisassemble)
+	}
+
+	// Reading other revisions runs git in the client's directories, so the
+	// tool is opt-in.
+	if gs.gitTools {
+		apiDiffTool := mcp.NewTool("api_diff",
+			mcp.WithDescription(apiDiffDescription),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("path",
+				mcp.Required(),
+				mcp.Description("Local package: a relative path such as '.' or './internal/codec', or an import path within the working_dir module."),
+			),
+			mcp.WithString("working_dir",
+				mcp.Required(),
+				mcp.Description("Directory inside the git repository, usually the module root."),
+			),
+			mcp.WithString("base",
+				mcp.Required(),
+				mcp.Description("Git revision to compare from, e.g. 'main', 'v1.2.0', or a commit hash."),
+			),
+			mcp.WithString("head",
+				mcp.Description("Git revision to compare to (default: HEAD)."),
+			),
+		)
+		gs.addTool(apiDiffTool, gs.handleAPIDiff)
+	}
+
+	// Running a package's tests executes its code and can take minutes, so
+	// the tool is opt-in.
+	if gs.testTools {
+		testCoverageTool := mcp.NewTool("test_coverage",
+			mcp.WithDescription(testCoverageDescription),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithString("path",
+				mcp.Required(),
+				mcp.Description("Local package: a relative path such as '.' or './internal/codec', or an import path within the working_dir module."),
+			),
+			mcp.WithString("working_dir",
+				mcp.Required(),
+				mcp.Description("Module directory the tests run in."),
+			),
+			mcp.WithBoolean("include_doc",
+				mcp.Description("Append the package documentation to the coverage report."),
+				mcp.DefaultBool(true),
+			),
+			mcp.WithNumber("timeout_seconds",
+				mcp.Description("Time limit for go test (default: 120). Max 300."),
+			),
+		)
+		gs.addTool(testCoverageTool, gs.handleTestCoverage)
+	}
+
+	var unknown []string
+	for name := range gs.tools {
+		if s.GetTool(name) == nil {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		log.Printf("Ignoring -tools entries that name no available tool: %s", strings.Join(unknown, ", "))
+	}
 
 	return gs
 }
 
+// addTool registers tool with the MCP server unless the server was
+// configured with a tool list that leaves it out.
+func (gs *godocServer) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if gs.tools != nil && !gs.tools[tool.Name] {
+		return
+	}
+	gs.mcpServer.AddTool(tool, gs.limitArgs(gs.holdProjects(handler)))
+}
+
 func (gs *godocServer) handleGetDoc(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pkgPath, err := request.RequireString("path")
 	if err != nil {
 		return mcp.NewToolResultError("path argument is required"), nil
 	}
 
-	target := request.GetString("target", "")
+	targets, err := docTargets(request.GetArguments()["target"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	workingDir := request.GetString("working_dir", "")
 	page := request.GetInt("page", 1)
 	pageSize := request.GetInt("page_size", 1000)
+	showMetadata := request.GetBool("show_metadata", true)
+	resolveAliases := request.GetBool("resolve_aliases", false)
+	normalize := request.GetBool("normalize", false)
+	wrap := request.GetBool("wrap_signatures", false)
+	returnedType := request.GetBool("include_returned_type", false)
+	signatureOnly := request.GetBool("signature_only", false)
+	synopsis := request.GetBool("synopsis", false)
+	exclude := request.GetStringSlice("exclude", nil)
+	fieldFilter := request.GetString("fields", "")
+	documentedFields := request.GetBool("documented_fields", false)
+	forceParse := request.GetBool("force_parse", false)
+	unexported := request.GetString("unexported", "none")
+	timeoutSeconds := request.GetInt("timeout_seconds", 0)
+	mode := request.GetString("mod_mode", "")
+	experiment := request.GetString("goexperiment", "")
+	if request.GetBool("no_cache", false) {
+		ctx = withNoCache(ctx)
+	}
 
-	// Validate working_dir exists and is a directory.
-	if workingDir != "" {
-		info, err := os.Stat(workingDir)
-		if err != nil || !info.IsDir() {
-			return mcp.NewToolResultError(fmt.Sprintf("invalid working directory: %s", workingDir)), nil
-		}
+	// Validate working_dir exists and is a directory, and resolve symlinks.
+	pkgPath, workingDir, err = canonicalPaths(pkgPath, workingDir)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := gs.checkPathsAllowed(pkgPath, workingDir); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	// Validate cmd_flags against allowlist.
@@ -166,47 +898,402 @@ func (gs *godocServer) handleGetDoc(ctx context.Context, request mcp.CallToolReq
 			return mcp.NewToolResultError(fmt.Sprintf("unsupported flag %q (allowed: %s)", f, strings.Join(allowed, ", "))), nil
 		}
 	}
+	if err := validateExclude(exclude); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if timeoutSeconds < 0 || time.Duration(timeoutSeconds)*time.Second > maxCmdTimeout {
+		return mcp.NewToolResultError(fmt.Sprintf("timeout_seconds must be between 1 and %d", int(maxCmdTimeout.Seconds()))), nil
+	}
+	if timeoutSeconds > 0 {
+		ctx = withCmdTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	}
+
+	if mode != "" {
+		if !modModes[mode] {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid mod_mode %q (use readonly, mod, or vendor)", mode)), nil
+		}
+		if workingDir == "" {
+			return mcp.NewToolResultError("mod_mode requires working_dir"), nil
+		}
+		if work := workspaceFile(gs.environ(), workingDir); work != "" && mode == "mod" {
+			return mcp.NewToolResultError(fmt.Sprintf("mod_mode 'mod' is not supported in workspace mode (%s); use readonly or vendor, or run go get in the workspace module", work)), nil
+		}
+		ctx = withModMode(ctx, mode)
+	}
+
+	if experiment != "" {
+		if err := validateGoExperiment(experiment); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		ctx = withGoExperiment(ctx, experiment)
+	}
+
+	switch unexported {
+	case "none", "types":
+	case "all":
+		if !slices.Contains(cmdFlags, "-u") {
+			cmdFlags = append(cmdFlags, "-u")
+		}
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid unexported value %q (use none, all, or types)", unexported)), nil
+	}
+
+	// Accept a fully qualified symbol such as "net/http.Client.Do" in path,
+	// or a pkg.go.dev link to one.
+	if targets[0] == "" {
+		if sym := pkgGoDevSymbol(pkgPath); sym != "" {
+			targets[0] = sym
+		} else {
+			pkgPath, targets[0] = splitSymbolPath(pkgPath)
+		}
+	}
+	stripHeader := request.GetBool("strip_header", targets[0] != "")
+
+	if err := validateDocArgs(docArgs{
+		targets:        targets,
+		cmdFlags:       cmdFlags,
+		signatureOnly:  signatureOnly,
+		synopsis:       synopsis,
+		exclude:        len(exclude) > 0,
+		fieldFilter:    fieldFilter != "" || documentedFields,
+		resolveAliases: resolveAliases,
+		returnedType:   returnedType,
+	}); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if isCgoPseudoPackage(pkgPath) {
+		return mcp.NewToolResultError(`"C" is the cgo pseudo-package; it refers to C declarations and has no Go documentation`), nil
+	}
+
+	// Local packages are cached by the state of their sources, not a TTL.
+	var srcHash string
+	localDir := localPackageDir(pkgPath, workingDir)
+	if localDir != "" {
+		srcHash, _ = sourceHash(localDir)
+	}
 
 	// Resolve the path to an import path.
 	resolvedPath, subDirs, err := validatePath(pkgPath, workingDir)
 	if err != nil {
 		if subDirs != nil {
 			msg := fmt.Sprintf("No Go files found in %s, but found Go packages in:\n%s", pkgPath, strings.Join(subDirs, "\n"))
-			return mcp.NewToolResultText(msg), nil
+			return gs.docResult(msg), nil
 		}
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	pkgPath = resolvedPath
+	pkgPath, version := splitVersion(resolvedPath)
+	if strings.EqualFold(version, headQuery) {
+		version = headQuery
+	}
 
-	// Get or create a cached project directory if no working directory was provided.
-	if workingDir == "" {
-		projDir, err := gs.getOrCreateProject(ctx, pkgPath)
+	if err := gs.checkPackageAllowed(pkgPath); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := gs.checkStdVersion(ctx, pkgPath, version); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// A module or local package named like a standard library package is
+	// ambiguous to the go command; prefer the local one the caller meant.
+	var collision, collisionDir string
+	if workingDir != "" && stdPackageExists(pkgPath) {
+		collision, collisionDir, pkgPath = resolveStdCollision(workingDir, localDir, pkgPath)
+	}
+
+	// Get or create a cached project directory if no working directory was
+	// provided. Standard library packages resolve from GOROOT and need none.
+	var head bool
+	if workingDir == "" && !isStdLib(pkgPath) {
+		projDir, err := gs.getOrCreateProject(ctx, projectKey(pkgPath, version))
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to create temporary project: %v", err)), nil
 		}
 		workingDir = projDir
+		head = version == headQuery
 	}
 
+	dr := docRequest{
+		pkgPath:          pkgPath,
+		workingDir:       workingDir,
+		srcHash:          srcHash,
+		cmdFlags:         cmdFlags,
+		unexported:       unexported,
+		collision:        collision,
+		collisionDir:     collisionDir,
+		signatureOnly:    signatureOnly,
+		synopsis:         synopsis,
+		exclude:          exclude,
+		fieldFilter:      fieldFilter,
+		documentedFields: documentedFields,
+		forceParse:       forceParse,
+		resolveAliases:   resolveAliases,
+		stripHeader:      stripHeader,
+		head:             head,
+		returnedType:     returnedType,
+	}
+
+	// Several targets share the resolved package and project; each gets its
+	// own section, and a failed lookup does not hide the others.
+	var doc string
+	if len(targets) == 1 {
+		doc, err = gs.targetDoc(ctx, dr, targets[0])
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	} else {
+		var b strings.Builder
+		for i, target := range targets {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "=== %s ===\n\n", target)
+			text, err := gs.targetDoc(ctx, dr, target)
+			if err != nil {
+				text = "Error: " + err.Error() + "\n"
+			}
+			b.WriteString(strings.TrimRight(text, "\n") + "\n")
+		}
+		doc = b.String()
+	}
+
+	if wrap {
+		doc = wrapSignatures(doc)
+	}
+	if normalize {
+		doc = normalizeDoc(doc)
+	}
+
+	// Single-page results can skip the metadata line if the caller asked.
+	if !showMetadata && page <= 1 && pageCount(doc, pageSize) == 1 {
+		return gs.docResult(doc), nil
+	}
+
+	// Paginate the output.
+	result, err := paginate(doc, page, pageSize)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return gs.docResult(result), nil
+}
+
+// docRequest is a get_doc request resolved to a package, shared by each of
+// the targets documented in it.
+type docRequest struct {
+	pkgPath          string
+	workingDir       string
+	srcHash          string
+	cmdFlags         []string
+	unexported       string
+	collision        string
+	collisionDir     string
+	signatureOnly    bool
+	synopsis         bool
+	exclude          []string // symbols left out of a package listing
+	fieldFilter      string   // substring a struct target's fields must contain
+	documentedFields bool     // show only a struct target's commented fields
+	forceParse       bool
+	resolveAliases   bool
+	stripHeader      bool
+	head             bool // the package was fetched at "@HEAD"
+	returnedType     bool // append the docs of a function's primary result type
+}
+
+// targetDoc documents target, or the whole package if target is empty, for
+// the resolved request dr, with the notes get_doc adds to go doc's output.
+func (gs *godocServer) targetDoc(ctx context.Context, dr docRequest, target string) (string, error) {
+	var err error
+
 	// Build go doc arguments.
 	var args []string
-	args = append(args, cmdFlags...)
-	args = append(args, pkgPath)
+	args = append(args, dr.cmdFlags...)
+	args = append(args, dr.pkgPath)
 	if target != "" {
 		args = append(args, target)
 	}
 
-	doc, err := gs.runGoDoc(ctx, workingDir, args...)
+	// Symbols of a package parsed earlier are rendered from its source, which
+	// was parsed without any experiment's build tags.
+	var doc string
+	cached := false
+	if dr.collisionDir == "" && !dr.signatureOnly && !dr.synopsis && !dr.filtersFields() && target != "" && len(dr.cmdFlags) == 0 && dr.unexported == "none" && goExperiment(ctx) == "" && !noCache(ctx) {
+		doc, cached = gs.cachedSymbolDoc(ctx, dr.workingDir, dr.pkgPath, target)
+	}
+	switch {
+	case cached:
+	case len(dr.exclude) > 0:
+		// go doc cannot leave symbols out, so filter them from the AST.
+		doc, err = gs.excludedDoc(ctx, dr)
+	case dr.filtersFields() && target != "":
+		// go doc cannot filter fields either, so remove them from the AST.
+		doc, err = gs.fieldFilteredDoc(ctx, dr, target)
+	case dr.collisionDir != "":
+		// go doc reports an ambiguous import, so read the local source.
+		var lp *loadedPackage
+		if lp, err = parsePackage(dr.collisionDir); err == nil {
+			lp.path = dr.pkgPath
+			if dr.signatureOnly {
+				doc, err = signatureDoc(lp, target)
+			} else {
+				doc, err = parsedDoc(lp, target)
+			}
+		}
+	case dr.signatureOnly:
+		// Signatures come from the AST, so the doc prose never reaches the output.
+		var lp *loadedPackage
+		if lp, err = gs.loadPackage(ctx, dr.workingDir, dr.pkgPath); err == nil {
+			doc, err = signatureDoc(lp, target)
+		}
+	case dr.synopsis:
+		// First sentences come from go/doc, which go doc has no flag for.
+		var lp *loadedPackage
+		if lp, err = gs.loadPackage(ctx, dr.workingDir, dr.pkgPath); err == nil {
+			doc, err = synopsisDoc(lp, target)
+		}
+	case dr.unexported == "types" && target != "" && !token.IsExported(target):
+		// go doc cannot show an unexported type without -u's full output.
+		doc, err = gs.unexportedTypesDoc(ctx, dr.workingDir, dr.pkgPath, target)
+	default:
+		doc, err = gs.runGoDocHashed(ctx, dr.workingDir, dr.srcHash, args...)
+		if err != nil && dr.workingDir != "" && !isStdLib(dr.pkgPath) && missingRequirementPattern.MatchString(err.Error()) {
+			doc, err = gs.prunedModuleDoc(ctx, dr, args, err)
+		}
+		// -all on a huge package can outlast the timeout; the -short index
+		// is much faster and still lists every symbol.
+		if errors.Is(err, errGoDocTimeout) && target == "" && slices.Contains(dr.cmdFlags, "-all") {
+			doc, err = gs.shortFallbackDoc(ctx, dr, err)
+		}
+	}
+	// Symbol lookups in fully excluded packages report "no such package", so
+	// confirm exclusion with go list when the error is not explicit.
+	if err != nil && dr.forceParse && (errors.Is(err, errNoBuildableFiles) || gs.allFilesExcluded(ctx, dr.workingDir, dr.pkgPath)) {
+		doc, err = gs.forceParseDoc(ctx, dr.workingDir, dr.pkgPath, target)
+	}
+	// A guessed name is often the prefix of real ones (Read for ReadAll).
+	if errors.Is(err, errSymbolNotFound) && target != "" {
+		if suggestions := gs.prefixSuggestions(ctx, dr.workingDir, dr.pkgPath, target); suggestions != "" {
+			return suggestions, nil
+		}
+	}
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return "", err
 	}
 
-	// Paginate the output.
-	result, err := paginate(doc, page, pageSize)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+	if dr.stripHeader {
+		doc = stripPackageHeader(doc)
+	}
+	if dr.collision != "" {
+		doc = dr.collision + "\n\n" + doc
 	}
 
-	return mcp.NewToolResultText(result), nil
+	// Under -src, a bodyless function looks like a stub; explain where its
+	// implementation really lives.
+	if target != "" && slices.Contains(dr.cmdFlags, "-src") {
+		doc += gs.bodylessFuncNote(ctx, dr.workingDir, dr.pkgPath, target)
+	}
+
+	// -all output can repeat method entries for heavily embedded types, and
+	// never shows the methods promoted from embedded fields.
+	if slices.Contains(dr.cmdFlags, "-all") {
+		doc = dedupeMethodDocs(doc)
+		if target != "" && !strings.Contains(target, ".") {
+			doc += gs.promotedMethodsNote(ctx, dr.workingDir, dr.pkgPath, target)
+		}
+	}
+
+	// Importing a package runs its init functions; flag those side effects,
+	// along with any cgo build requirements and a non-canonical import path.
+	if target == "" {
+		if note := gs.cachedNote(ctx, dr, "doc-only", "", gs.docOnlyNote); note != "" {
+			// -short lists only declarations, leaving nothing for such a
+			// package, so show its package comment instead.
+			if strings.TrimSpace(doc) == "" {
+				if full, err := gs.runGoDocHashed(ctx, dr.workingDir, dr.srcHash, dr.pkgPath); err == nil {
+					doc = full
+					if dr.stripHeader {
+						doc = stripPackageHeader(doc)
+					}
+				}
+			}
+			doc += note
+		}
+		doc += gs.cachedNote(ctx, dr, "init", "", gs.initFuncNote)
+		doc += gs.cachedNote(ctx, dr, "cgo", "", gs.cgoNote)
+		doc += gs.cachedNote(ctx, dr, "canonical", "", gs.canonicalImportNote)
+	}
+
+	if dr.unexported == "types" && target == "" {
+		if extra, err := gs.unexportedTypesDoc(ctx, dr.workingDir, dr.pkgPath, ""); err == nil && extra != "" {
+			doc += "\nUNEXPORTED TYPES\n\n" + extra
+		}
+	}
+
+	if dr.resolveAliases && target != "" {
+		doc += gs.describeAlias(ctx, dr.workingDir, dr.pkgPath, target)
+	}
+
+	if target != "" && !dr.signatureOnly {
+		doc += gs.cachedSymbolNote(ctx, dr, "min-go", target, gs.minGoVersionNote)
+		doc += gs.cachedSymbolNote(ctx, dr, "panic", target, gs.panicNote)
+	}
+
+	// A replace directive can swap in another version of the module; say
+	// which one the documentation describes.
+	if dr.collisionDir == "" && !dr.signatureOnly {
+		doc += gs.cachedNote(ctx, dr, "replace", "", gs.replacedModuleNote)
+	}
+
+	if dr.returnedType && target != "" {
+		doc += gs.returnedTypeDoc(ctx, dr, target)
+	}
+
+	if dr.head {
+		doc += gs.headNote(ctx, dr.workingDir, dr.pkgPath)
+	}
+
+	return doc, nil
+}
+
+// docTargets returns the symbols named by get_doc's target argument, which is
+// a single symbol or an array of them. A missing target is the package itself.
+func docTargets(v any) ([]string, error) {
+	switch t := v.(type) {
+	case nil:
+		return []string{""}, nil
+	case string:
+		return []string{t}, nil
+	case []any:
+		if len(t) == 0 {
+			return nil, errors.New("target array must not be empty")
+		}
+		targets := make([]string, 0, len(t))
+		for _, e := range t {
+			name, ok := e.(string)
+			if !ok || name == "" {
+				return nil, fmt.Errorf("target array must contain only symbol names, got %v", e)
+			}
+			targets = append(targets, name)
+		}
+		return targets, nil
+	case []string:
+		items := make([]any, len(t))
+		for i, name := range t {
+			items[i] = name
+		}
+		return docTargets(items)
+	}
+	return nil, fmt.Errorf("target must be a string or an array of strings, got %T", v)
+}
+
+// docResult wraps a get_doc text result, prefixed with the configured
+// response preamble line, if any.
+func (gs *godocServer) docResult(text string) *mcp.CallToolResult {
+	if gs.responsePreamble != "" {
+		text = gs.responsePreamble + "\n" + text
+	}
+	return mcp.NewToolResultText(text)
 }
 
 func (gs *godocServer) handleListPackages(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -215,53 +1302,147 @@ func (gs *godocServer) handleListPackages(ctx context.Context, request mcp.CallT
 		return mcp.NewToolResultError("path argument is required"), nil
 	}
 
-	workingDir := request.GetString("working_dir", "")
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	if workingDir != "" {
-		info, err := os.Stat(workingDir)
-		if err != nil || !info.IsDir() {
-			return mcp.NewToolResultError(fmt.Sprintf("invalid working directory: %s", workingDir)), nil
-		}
+	packages, err := gs.listPackages(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if len(packages) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No sub-packages found under %s", pkgPath)), nil
+	}
+
+	return mcp.NewToolResultText(strings.Join(packages, "\n")), nil
+}
+
+// resolvePackage validates workingDir, resolves pkgPath to an import path,
+// and supplies a cached temporary project when no working directory was given
+// for a non-stdlib package. It returns the import path and the directory to
+// run go commands in.
+func (gs *godocServer) resolvePackage(ctx context.Context, pkgPath, workingDir string) (string, string, error) {
+	pkgPath, workingDir, err := canonicalPaths(pkgPath, workingDir)
+	if err != nil {
+		return "", "", err
+	}
+	if err := gs.checkPathsAllowed(pkgPath, workingDir); err != nil {
+		return "", "", err
 	}
 
-	// Resolve the path.
 	resolvedPath, _, err := validatePath(pkgPath, workingDir)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return "", "", err
+	}
+	resolvedPath, version := splitVersion(resolvedPath)
+	if err := gs.checkPackageAllowed(resolvedPath); err != nil {
+		return "", "", err
+	}
+	if err := gs.checkStdVersion(ctx, resolvedPath, version); err != nil {
+		return "", "", err
 	}
-	pkgPath = resolvedPath
 
-	// Get or create project dir if needed.
-	if workingDir == "" {
-		projDir, err := gs.getOrCreateProject(ctx, pkgPath)
+	// Stdlib resolves from GOROOT and needs no project.
+	if workingDir == "" && !isStdLib(resolvedPath) {
+		projDir, err := gs.getOrCreateProject(ctx, projectKey(resolvedPath, version))
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to create temporary project: %v", err)), nil
+			return "", "", fmt.Errorf("failed to create temporary project: %w", err)
 		}
 		workingDir = projDir
 	}
 
-	packages, err := gs.listPackages(ctx, workingDir, pkgPath)
+	return resolvedPath, workingDir, nil
+}
+
+// canonicalPaths validates workingDir and resolves symlinks in it and in an
+// absolute pkgPath, so that go.mod lookup and import path computation work on
+// the same real paths the go command reports. An empty workingDir stays empty.
+func canonicalPaths(pkgPath, workingDir string) (string, string, error) {
+	if filepath.IsAbs(pkgPath) {
+		if real, err := filepath.EvalSymlinks(pkgPath); err == nil {
+			pkgPath = real
+		}
+	}
+	if workingDir == "" {
+		return pkgPath, "", nil
+	}
+
+	info, err := os.Stat(workingDir)
+	if err != nil || !info.IsDir() {
+		return "", "", fmt.Errorf("invalid working directory: %s", workingDir)
+	}
+	real, err := filepath.EvalSymlinks(workingDir)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return "", "", fmt.Errorf("invalid working directory: %s: %w", workingDir, err)
+	}
+	return pkgPath, real, nil
+}
+
+// checkPackageAllowed returns an error if importPath matches one of the
+// operator-configured deny patterns.
+func (gs *godocServer) checkPackageAllowed(importPath string) error {
+	for _, pattern := range gs.denyPackages {
+		if matchPackagePattern(pattern, importPath) {
+			return fmt.Errorf("documentation for package %s is not permitted", importPath)
+		}
+	}
+	return nil
+}
+
+// checkPathsAllowed returns an error if workingDir, or the directory of a
+// relative or absolute pkgPath, is outside the operator-configured allowed
+// roots. Paths are cleaned and their symlinks resolved first, so neither
+// ".." nor a symlink can escape a root.
+func (gs *godocServer) checkPathsAllowed(pkgPath, workingDir string) error {
+	if len(gs.allowedRoots) == 0 {
+		return nil
+	}
+	for _, dir := range []string{workingDir, localPackageDir(pkgPath, workingDir)} {
+		if dir == "" {
+			continue
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("invalid directory %s: %w", dir, err)
+		}
+		if real, err := filepath.EvalSymlinks(abs); err == nil {
+			abs = real
+		}
+		if !slices.ContainsFunc(gs.allowedRoots, func(root string) bool { return withinDir(root, abs) }) {
+			return fmt.Errorf("directory %s is outside the allowed roots", dir)
+		}
 	}
+	return nil
+}
 
-	if len(packages) == 0 {
-		return mcp.NewToolResultText(fmt.Sprintf("No sub-packages found under %s", pkgPath)), nil
+// matchPackagePattern reports whether importPath matches pattern. Patterns
+// are path.Match globs, and a trailing "/..." also matches every package
+// below the prefix, as with go list.
+func matchPackagePattern(pattern, importPath string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		// The prefix may itself be a glob, so test each ancestor of importPath.
+		for dir := importPath; dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if matched, _ := path.Match(prefix, dir); matched {
+				return true
+			}
+		}
+		return false
 	}
-
-	return mcp.NewToolResultText(strings.Join(packages, "\n")), nil
+	matched, _ := path.Match(pattern, importPath)
+	return matched
 }
 
 // listPackages runs `go list <path>/...` and returns each package with its doc synopsis.
 func (gs *godocServer) listPackages(ctx context.Context, workingDir, importPath string) ([]string, error) {
-	listCtx, cancel := context.WithTimeout(ctx, cmdTimeout)
+	listCtx, cancel := commandContext(ctx)
 	defer cancel()
 
 	// Use go list -f to get import path and doc synopsis in one call.
-	cmd := exec.CommandContext(listCtx, "go", "list", "-f", "{{.ImportPath}}\t{{.Doc}}", importPath+"/...")
-	cmd.Dir = workingDir
+	cmd := gs.goCommand(listCtx, workingDir, "list", "-f", "{{.ImportPath}}\t{{.Doc}}", importPath+"/...")
 
-	out, err := cmd.CombinedOutput()
+	out, err := gs.combinedOutput(listCtx, cmd)
 	if err != nil {
 		return nil, fmt.Errorf("go list failed: %w\noutput: %s", err, string(out))
 	}
@@ -291,10 +1472,7 @@ func paginate(content string, page, pageSize int) (string, error) {
 
 	lines := strings.Split(content, "\n")
 	totalLines := len(lines)
-	totalPages := (totalLines + pageSize - 1) / pageSize
-	if totalPages < 1 {
-		totalPages = 1
-	}
+	totalPages := pageCount(content, pageSize)
 
 	if page > totalPages {
 		return "", fmt.Errorf("page %d exceeds total pages %d", page, totalPages)
@@ -307,49 +1485,344 @@ func paginate(content string, page, pageSize int) (string, error) {
 	}
 
 	pageContent := strings.Join(lines[start:end], "\n")
-	metadata := fmt.Sprintf("Page %d of %d (showing lines %d-%d of %d)",
-		page, totalPages, start+1, end, totalLines)
+	// is_last_page gives clients an explicit stop signal instead of relying
+	// on them to compare page numbers.
+	metadata := fmt.Sprintf("Page %d of %d (showing lines %d-%d of %d; is_last_page: %t)",
+		page, totalPages, start+1, end, totalLines, page == totalPages)
 
 	return metadata + "\n\n" + pageContent, nil
 }
 
-// validatePath resolves a user-provided path to a Go import path.
+// pageCount returns the number of pages content spans at the given page size.
+func pageCount(content string, pageSize int) int {
+	totalLines := strings.Count(content, "\n") + 1
+	totalPages := (totalLines + pageSize - 1) / pageSize
+	if totalPages < 1 {
+		totalPages = 1
+	}
+	return totalPages
+}
+
+// stripPackageHeader removes the leading "package X // import ..." line that
+// go doc prints before symbol documentation, along with the blank line after it.
+func stripPackageHeader(doc string) string {
+	first, rest, ok := strings.Cut(doc, "\n")
+	if !ok || !strings.HasPrefix(first, "package ") || !strings.Contains(first, "// import ") {
+		return doc
+	}
+	return strings.TrimPrefix(rest, "\n")
+}
+
+// splitSymbolPath splits a fully qualified symbol like "net/http.Client.Do"
+// into its package path and symbol ("net/http", "Client.Do"). The symbol
+// starts at the first dot-separated element of the last path segment that
+// begins with an upper-case letter, so versioned paths such as
+// "gopkg.in/yaml.v3" are left intact. Paths without a symbol are returned
+// unchanged with an empty target.
+func splitSymbolPath(p string) (string, string) {
+	if p == "" || filepath.IsAbs(p) {
+		return p, ""
+	}
+	dir, last := "", p
+	if i := strings.LastIndex(p, "/"); i >= 0 {
+		dir, last = p[:i+1], p[i+1:]
+	}
+
+	parts := strings.Split(last, ".")
+	for i := 1; i < len(parts); i++ {
+		if !token.IsExported(parts[i]) {
+			continue
+		}
+		symbol := parts[i:]
+		if len(symbol) > 2 {
+			return p, ""
+		}
+		for _, part := range symbol {
+			if !token.IsIdentifier(part) {
+				return p, ""
+			}
+		}
+		return dir + strings.Join(parts[:i], "."), strings.Join(symbol, ".")
+	}
+	return p, ""
+}
+
+// stdPackageExists reports whether importPath names a package in GOROOT.
+func stdPackageExists(importPath string) bool {
+	if !isStdLib(importPath) {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(build.Default.GOROOT, "src", filepath.FromSlash(importPath)))
+	return err == nil && info.IsDir()
+}
+
+// resolveStdCollision handles an import path that names both a standard
+// library package and something local to workingDir. localDir is the
+// directory of a relative or absolute path argument, if any. It returns a
+// note describing the collision, the directory to document from source when
+// the go command cannot disambiguate (the main module itself is named like
+// the standard package), and the import path to document. Without a
+// collision, the note is empty and importPath is returned unchanged.
+func resolveStdCollision(workingDir, localDir, importPath string) (note, dir, resolved string) {
+	root, err := findModuleRoot(workingDir)
+	if err != nil {
+		return "", "", importPath
+	}
+	moduleName, err := readModuleName(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", "", importPath
+	}
+
+	if localDir == "" && moduleName == importPath {
+		localDir = root
+	}
+	if localDir != "" {
+		return fmt.Sprintf("Note: %q is both a standard library package and a package in the module at %s; "+
+			"the go command cannot tell them apart, so this documentation was read from the local source in %s.",
+			importPath, root, localDir), localDir, importPath
+	}
+
+	// An import path typed as-is resolves to the standard library, but a
+	// same-named package inside the module was most likely meant.
+	candidate := filepath.Join(root, filepath.FromSlash(importPath))
+	if _, err := build.ImportDir(candidate, 0); err != nil {
+		return "", "", importPath
+	}
+	local := path.Join(moduleName, importPath)
+	return fmt.Sprintf("Note: %q is a standard library package, but %s also contains a package of that name; "+
+		"showing the local package %s. Omit working_dir to document the standard library package.",
+		importPath, root, local), "", local
+}
+
+// validatePath resolves a user-provided path to a Go import path. Package
+// URLs copied from pkg.go.dev are accepted; a version in such a URL is kept
+// as an "@version" suffix on the returned import path.
 func validatePath(pkgPath, workingDir string) (string, []string, error) {
+	if p, ok := trimPkgGoDevURL(pkgPath); ok {
+		return p, nil, nil
+	}
+	if p, ok := trimRepoURL(pkgPath); ok {
+		return p, nil, nil
+	}
+
 	// Relative paths require a working directory to resolve module context.
 	if strings.HasPrefix(pkgPath, ".") {
 		if workingDir == "" {
 			return "", nil, fmt.Errorf("working_dir is required for relative paths (including '.')")
 		}
 
-		moduleName, err := readModuleName(filepath.Join(workingDir, "go.mod"))
+		importPath, err := dirImportPath(filepath.Join(workingDir, pkgPath))
 		if err != nil {
-			return "", nil, fmt.Errorf("failed to read go.mod in working directory: %w", err)
-		}
-
-		if pkgPath == "." {
-			return moduleName, nil, nil
+			return "", nil, fmt.Errorf("failed to resolve %s in working directory: %w", pkgPath, err)
 		}
-		relPath := strings.TrimPrefix(pkgPath, "./")
-		return path.Join(moduleName, relPath), nil, nil
+		return importPath, nil, nil
 	}
 
-	// Absolute paths: read go.mod from the given path.
+	// Absolute paths: resolve against the module containing the path.
 	if strings.HasPrefix(pkgPath, "/") || filepath.IsAbs(pkgPath) {
 		if workingDir != "" && pkgPath != workingDir {
 			return "", nil, fmt.Errorf("absolute path must match working directory when provided")
 		}
 
-		moduleName, err := readModuleName(filepath.Join(pkgPath, "go.mod"))
+		importPath, err := dirImportPath(pkgPath)
 		if err != nil {
-			return "", nil, fmt.Errorf("failed to read go.mod: %w", err)
+			return "", nil, fmt.Errorf("failed to resolve %s: %w", pkgPath, err)
 		}
-		return moduleName, nil, nil
+		return importPath, nil, nil
 	}
 
 	// Treat everything else as an import path.
 	return pkgPath, nil, nil
 }
 
+// trimPkgGoDevURL converts a pkg.go.dev URL such as
+// "https://pkg.go.dev/github.com/user/repo@v1.2.3/sub/pkg" into an import path
+// with an optional version suffix ("github.com/user/repo/sub/pkg@v1.2.3").
+// Query strings and #symbol anchors are dropped; see pkgGoDevSymbol.
+func trimPkgGoDevURL(p string) (string, bool) {
+	rest := strings.TrimPrefix(strings.TrimPrefix(p, "https://"), "http://")
+	rest, ok := strings.CutPrefix(rest, "pkg.go.dev/")
+	if !ok {
+		return "", false
+	}
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+	rest = strings.TrimSuffix(rest, "/")
+
+	modPath, after, hasVersion := strings.Cut(rest, "@")
+	if !hasVersion {
+		return rest, true
+	}
+	ver, sub, _ := strings.Cut(after, "/")
+	return path.Join(modPath, sub) + "@" + ver, true
+}
+
+// pkgGoDevSymbol returns the symbol a pkg.go.dev URL links to, such as
+// "Client.Do" for "https://pkg.go.dev/net/http#Client.Do", or "" when p is not
+// such a URL or its anchor names a section or example instead.
+func pkgGoDevSymbol(p string) string {
+	rest := strings.TrimPrefix(strings.TrimPrefix(p, "https://"), "http://")
+	if !strings.HasPrefix(rest, "pkg.go.dev/") {
+		return ""
+	}
+	_, anchor, ok := strings.Cut(rest, "#")
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(anchor, ".")
+	if len(parts) > 2 || !token.IsExported(parts[0]) {
+		return ""
+	}
+	for _, part := range parts {
+		if !token.IsIdentifier(part) {
+			return ""
+		}
+	}
+	return anchor
+}
+
+// repoURLMarkers are the path elements that separate a repository from the
+// ref and file path in source browser URLs on common hosts:
+// github.com/user/repo/blob/REF/path, gitlab.com/group/project/-/tree/REF/path,
+// and bitbucket.org/user/repo/src/REF/path.
+var repoURLMarkers = map[string][]string{
+	"github.com":    {"/blob/", "/tree/"},
+	"gitlab.com":    {"/-/blob/", "/-/tree/"},
+	"bitbucket.org": {"/src/"},
+}
+
+// semverMajor matches a semantic version tag, capturing its major version.
+var semverMajor = regexp.MustCompile(`^v([0-9]+)\.[0-9]+\.[0-9]+`)
+
+// trimRepoURL converts a link to a file or directory in a GitHub, GitLab, or
+// Bitbucket repository, such as
+// "https://github.com/user/repo/blob/v1.2.3/pkg/foo/bar.go", into the import
+// path of the containing package with the ref as its version
+// ("github.com/user/repo/pkg/foo@v1.2.3"). A v2+ tag adds the major version
+// suffix that module paths carry. Links to a repository root give its path
+// with no version. Line anchors and query strings are dropped.
+func trimRepoURL(p string) (string, bool) {
+	rest, ok := strings.CutPrefix(p, "https://")
+	if !ok {
+		if rest, ok = strings.CutPrefix(p, "http://"); !ok {
+			return "", false
+		}
+	}
+	rest = strings.TrimPrefix(rest, "www.")
+	host, _, _ := strings.Cut(rest, "/")
+	markers, ok := repoURLMarkers[host]
+	if !ok {
+		return "", false
+	}
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+	rest = strings.TrimSuffix(rest, "/")
+
+	repo, ref, file := rest, "", ""
+	for _, marker := range markers {
+		if before, after, found := strings.Cut(rest, marker); found {
+			repo = before
+			ref, file, _ = strings.Cut(after, "/")
+			break
+		}
+	}
+	repo = strings.TrimSuffix(repo, ".git")
+	if strings.Count(repo, "/") < 2 {
+		return "", false
+	}
+
+	if strings.HasSuffix(file, ".go") || path.Ext(file) != "" {
+		file = path.Dir(file)
+	}
+	if m := semverMajor.FindStringSubmatch(ref); m != nil && m[1] != "0" && m[1] != "1" {
+		if major := "v" + m[1]; file != major && !strings.HasPrefix(file, major+"/") {
+			repo += "/" + major
+		}
+	}
+
+	importPath := path.Join(repo, file)
+	if ref == "" {
+		return importPath, true
+	}
+	return importPath + "@" + ref, true
+}
+
+// splitVersion splits an "@version" suffix off an import path.
+func splitVersion(p string) (importPath, version string) {
+	importPath, version, _ = strings.Cut(p, "@")
+	return importPath, version
+}
+
+// checkStdVersion rejects a version on a standard library path, such as the
+// "@go1.21.0" of a pkg.go.dev link, unless it names the Go release the go
+// command runs: the standard library is always documented from its GOROOT.
+func (gs *godocServer) checkStdVersion(ctx context.Context, pkgPath, version string) error {
+	if version == "" || !isStdLib(pkgPath) {
+		return nil
+	}
+	envCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	out, err := gs.output(envCtx, gs.goCommand(envCtx, "", "env", "GOVERSION"))
+	if err != nil {
+		return fmt.Errorf("go env failed: %w", err)
+	}
+	goVersion := strings.TrimSpace(string(out))
+	if version == goVersion {
+		return nil
+	}
+	return fmt.Errorf("%s@%s names a standard library release, but documentation comes from the running toolchain (%s); omit the version to document it", pkgPath, version, goVersion)
+}
+
+// projectKey identifies the temporary project for importPath at version,
+// in the "path@version" form go get accepts. An empty version means latest.
+func projectKey(importPath, version string) string {
+	if version == "" {
+		return importPath
+	}
+	return importPath + "@" + version
+}
+
+// findModuleRoot returns the nearest directory at or above dir that contains
+// a go.mod file, the way the go command locates the main module.
+func findModuleRoot(dir string) (string, error) {
+	dir = filepath.Clean(dir)
+	for d := dir; ; {
+		if info, err := os.Stat(filepath.Join(d, "go.mod")); err == nil && !info.IsDir() {
+			return d, nil
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", fmt.Errorf("no go.mod found in %s or any parent directory", dir)
+		}
+		d = parent
+	}
+}
+
+// dirImportPath returns the import path of the package in dir, computed from
+// the module path in the nearest go.mod at or above it, or for a directory
+// outside any module, from its location in GOPATH.
+func dirImportPath(dir string) (string, error) {
+	root, err := findModuleRoot(dir)
+	if err != nil {
+		if importPath, gerr := gopathImportPath(dir); gerr == nil {
+			return importPath, nil
+		}
+		return "", err
+	}
+	moduleName, err := readModuleName(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, filepath.Clean(dir))
+	if err != nil {
+		return "", err
+	}
+	return path.Join(moduleName, filepath.ToSlash(rel)), nil
+}
+
 // readModuleName extracts the module name from a go.mod file.
 func readModuleName(goModPath string) (string, error) {
 	content, err := os.ReadFile(goModPath)
@@ -373,140 +1846,492 @@ func readModuleName(goModPath string) (string, error) {
 
 // getOrCreateProject returns a cached project directory for the import path,
 // creating one if needed. Directories are reused for 30 minutes to avoid
-// repeated go get calls for the same package.
+// repeated go get calls for the same package. An expired directory is kept
+// when its module is still in the module cache, skipping go get entirely.
+// importPath may carry an "@version" suffix to pin the fetched module.
+// The directory is held for the tool call in ctx, if any; see holdProjects.
 func (gs *godocServer) getOrCreateProject(ctx context.Context, importPath string) (string, error) {
+	var expired *cachedProject
 	gs.mu.Lock()
 	if proj, ok := gs.projects[importPath]; ok {
 		if time.Since(proj.timestamp) < projectTTL {
+			gs.useProject(ctx, proj)
 			gs.mu.Unlock()
 			log.Printf("Project cache hit for %s", importPath)
 			return proj.dir, nil
 		}
-		// Expired — clean up and recreate.
-		os.RemoveAll(proj.dir)
+		expired = proj
 		delete(gs.projects, importPath)
+		expired.refs++ // Keep the directory while deciding whether to reuse it.
 	}
 	gs.mu.Unlock()
 
-	dir, err := createTempProject(ctx, importPath)
-	if err != nil {
-		return "", err
+	var proj *cachedProject
+	// HEAD moves, so an expired project for it is always fetched again.
+	pkgPath, version := splitVersion(importPath)
+	if expired != nil && version != headQuery && gs.moduleDownloaded(ctx, expired.dir, pkgPath) {
+		proj = expired
+		log.Printf("Project for %s expired but module is cached; reusing %s", importPath, proj.dir)
+	} else {
+		if expired != nil {
+			gs.mu.Lock()
+			expired.refs--
+			gs.dropProject(expired)
+			gs.mu.Unlock()
+		}
+		if err := gs.acquireProjectSlot(ctx); err != nil {
+			return "", err
+		}
+		dir, err := gs.createTempProject(ctx, importPath)
+		if err != nil {
+			gs.releaseProjectSlot()
+			return "", err
+		}
+		proj = &cachedProject{dir: dir}
 	}
 
 	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if proj == expired {
+		proj.refs--
+	}
 	// Double-check: another goroutine may have populated the cache while we
 	// were creating our temp project.
-	if proj, ok := gs.projects[importPath]; ok {
-		if time.Since(proj.timestamp) < projectTTL {
-			gs.mu.Unlock()
-			os.RemoveAll(dir) // Discard ours; use the one already cached.
+	if cur, ok := gs.projects[importPath]; ok {
+		if time.Since(cur.timestamp) < projectTTL {
+			gs.dropProject(proj) // Discard ours; use the one already cached.
+			gs.useProject(ctx, cur)
 			log.Printf("Project cache hit for %s (race resolved)", importPath)
-			return proj.dir, nil
+			return cur.dir, nil
 		}
-		os.RemoveAll(proj.dir)
+		gs.dropProject(cur)
 	}
-	gs.projects[importPath] = cachedProject{dir: dir, timestamp: time.Now()}
-	gs.mu.Unlock()
+	proj.timestamp = time.Now()
+	gs.projects[importPath] = proj
+	gs.useProject(ctx, proj)
+
+	log.Printf("Project cache miss for %s -> %s", importPath, proj.dir)
+	return proj.dir, nil
+}
+
+// holdProjects wraps handler so that the projects it obtains from
+// getOrCreateProject stay on disk until it returns, even if they are evicted
+// or expire meanwhile.
+func (gs *godocServer) holdProjects(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		leases := &projectLeases{}
+		defer gs.releaseProjects(leases)
+		return handler(context.WithValue(ctx, projectLeasesKey{}, leases), request)
+	}
+}
+
+// useProject marks proj as just used and, within a tool call, holds it until
+// the call returns. gs.mu must be held.
+func (gs *godocServer) useProject(ctx context.Context, proj *cachedProject) {
+	proj.lastUsed = time.Now()
+	if leases, ok := ctx.Value(projectLeasesKey{}).(*projectLeases); ok {
+		proj.refs++
+		leases.projects = append(leases.projects, proj)
+	}
+}
+
+// releaseProjects lets go of the projects held by a tool call, removing
+// those that were dropped while in use.
+func (gs *godocServer) releaseProjects(leases *projectLeases) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	for _, proj := range leases.projects {
+		proj.refs--
+		if proj.refs > 0 {
+			continue
+		}
+		if proj.dropped {
+			gs.removeProject(proj.dir)
+		}
+		select {
+		case gs.projectIdle <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// dropProject forgets a project that is no longer cached, removing its
+// directory now or, while a request still uses it, once that request
+// returns. gs.mu must be held.
+func (gs *godocServer) dropProject(proj *cachedProject) {
+	proj.dropped = true
+	if proj.refs == 0 {
+		gs.removeProject(proj.dir)
+	}
+}
+
+// moduleDownloaded reports whether importPath resolves in the project at dir
+// using only the local module cache, without any network access.
+func (gs *godocServer) moduleDownloaded(ctx context.Context, dir, importPath string) bool {
+	listCtx, cancel := commandContext(ctx)
+	defer cancel()
 
-	log.Printf("Project cache miss for %s -> %s", importPath, dir)
-	return dir, nil
+	cmd := gs.goCommand(listCtx, dir, "list", "-mod=readonly", "-f", "{{with .Module}}{{.Dir}}{{end}}", importPath)
+	cmd.Env = append(cmd.Env, "GOPROXY=off", "GOFLAGS=")
+	out, err := gs.output(listCtx, cmd)
+	if err != nil {
+		return false
+	}
+	modDir := strings.TrimSpace(string(out))
+	if modDir == "" {
+		return false
+	}
+	_, err = os.Stat(modDir)
+	return err == nil
 }
 
-// cleanup removes all cached project directories.
+// cleanup removes all cached project directories. Directories still in use
+// are removed when their requests return.
 func (gs *godocServer) cleanup() {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
 	for key, proj := range gs.projects {
-		os.RemoveAll(proj.dir)
+		gs.dropProject(proj)
 		delete(gs.projects, key)
 	}
 }
 
+// acquireProjectSlot reserves room for one more temporary project directory.
+// At the limit, the least recently used idle project is removed to make room;
+// if every slot belongs to a project being created or in use, it waits for
+// one to be freed or to become idle until ctx is done.
+func (gs *godocServer) acquireProjectSlot(ctx context.Context) error {
+	waiting := false
+	for {
+		select {
+		case gs.projectSlots <- struct{}{}:
+			return nil
+		default:
+		}
+		if gs.evictOldestProject() {
+			continue
+		}
+
+		if !waiting {
+			log.Printf("Temporary project limit (%d) reached; waiting for a project to be removed", cap(gs.projectSlots))
+			waiting = true
+		}
+		select {
+		case gs.projectSlots <- struct{}{}:
+			return nil
+		case <-gs.projectIdle:
+		case <-ctx.Done():
+			return fmt.Errorf("too many temporary projects: %w", ctx.Err())
+		}
+	}
+}
+
+// releaseProjectSlot frees the slot of a removed project directory. Projects
+// added to the cache by other means hold no slot, so it never blocks.
+func (gs *godocServer) releaseProjectSlot() {
+	select {
+	case <-gs.projectSlots:
+	default:
+	}
+}
+
+// removeProject deletes a temporary project directory and frees its slot.
+func (gs *godocServer) removeProject(dir string) {
+	os.RemoveAll(dir)
+	gs.releaseProjectSlot()
+}
+
+// evictOldestProject removes the least recently used cached project that no
+// request is using. It reports false when there is none.
+func (gs *godocServer) evictOldestProject() bool {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	var oldestKey string
+	var oldest *cachedProject
+	for key, proj := range gs.projects {
+		if proj.refs > 0 {
+			continue
+		}
+		if oldest == nil || proj.lastUsed.Before(oldest.lastUsed) {
+			oldestKey, oldest = key, proj
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	delete(gs.projects, oldestKey)
+
+	log.Printf("Temporary project limit reached; removing the project for %s", oldestKey)
+	gs.dropProject(oldest)
+	return true
+}
+
+// projectOwnerFile names the file in each temporary project directory that
+// records the process ID of the server that created it.
+const projectOwnerFile = ".godoc-mcp-owner"
+
+// sweepStaleProjects removes temporary project directories that earlier
+// runs left behind in the system temp directory after an ungraceful exit,
+// returning how many it removed. Directories whose owning server is still
+// running are kept, as are unowned ones modified within projectTTL, which
+// may be mid-creation or come from a release that wrote no owner file.
+func sweepStaleProjects() int {
+	dirs, _ := filepath.Glob(filepath.Join(os.TempDir(), "godoc-mcp-*"))
+	removed := 0
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if data, err := os.ReadFile(filepath.Join(dir, projectOwnerFile)); err == nil {
+			pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+			if err == nil && processRunning(pid) {
+				continue
+			}
+		} else if time.Since(info.ModTime()) < projectTTL {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			log.Printf("Failed to remove stale project %s: %v", dir, err)
+			continue
+		}
+		removed++
+	}
+	return removed
+}
+
+// processRunning reports whether a process with the given ID exists. Where
+// that cannot be probed without side effects, it assumes it does.
+func processRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// FindProcess opens a handle, so it fails for exited processes.
+		p.Release()
+		return true
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
+
 // createTempProject creates a temporary Go module for fetching documentation.
-func createTempProject(ctx context.Context, importPath string) (string, error) {
+func (gs *godocServer) createTempProject(ctx context.Context, importPath string) (string, error) {
 	tempDir, err := os.MkdirTemp("", "godoc-mcp-*")
 	if err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %w", err)
 	}
+	owner := []byte(strconv.Itoa(os.Getpid()) + "\n")
+	if err := os.WriteFile(filepath.Join(tempDir, projectOwnerFile), owner, 0644); err != nil {
+		os.RemoveAll(tempDir)
+		return "", fmt.Errorf("failed to mark temp directory: %w", err)
+	}
 
-	initCtx, cancel := context.WithTimeout(ctx, cmdTimeout)
+	initCtx, cancel := commandContext(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(initCtx, "go", "mod", "init", "godoc-temp")
-	cmd.Dir = tempDir
-	if out, err := cmd.CombinedOutput(); err != nil {
+	cmd := gs.goCommand(initCtx, tempDir, "mod", "init", gs.tempModule)
+	if out, err := gs.combinedOutput(initCtx, cmd); err != nil {
 		os.RemoveAll(tempDir)
 		return "", fmt.Errorf("failed to initialize go.mod: %w\noutput: %s", err, out)
 	}
 
+	if gs.tempGoVersion != "" {
+		if err := gs.setGoDirective(initCtx, tempDir, gs.tempGoVersion); err != nil {
+			os.RemoveAll(tempDir)
+			return "", err
+		}
+	}
+
 	// For non-stdlib packages, download the dependency.
 	if !isStdLib(importPath) {
-		getCtx, cancel := context.WithTimeout(ctx, cmdTimeout)
+		getCtx, cancel := commandContext(ctx)
 		defer cancel()
 
-		cmd = exec.CommandContext(getCtx, "go", "get", importPath)
-		cmd.Dir = tempDir
-		if out, err := cmd.CombinedOutput(); err != nil {
+		cmd = gs.goCommand(getCtx, tempDir, "get", importPath)
+		if out, err := gs.combinedOutput(getCtx, cmd); err != nil {
 			os.RemoveAll(tempDir)
+			if getCtx.Err() == context.DeadlineExceeded {
+				return "", fmt.Errorf("timed out downloading %s; retry with a larger timeout_seconds: %w", importPath, err)
+			}
 			return "", fmt.Errorf("failed to get package %s: %w\noutput: %s", importPath, err, out)
 		}
+
+		// Without a pinned version, raise the go directive to whatever the
+		// dependency's module declares so newer language features resolve.
+		if gs.tempGoVersion == "" {
+			pkgPath, _ := splitVersion(importPath)
+			gs.matchDependencyGoVersion(getCtx, tempDir, pkgPath)
+		}
 	}
 
 	return tempDir, nil
 }
 
+// setGoDirective rewrites the go directive of the module in dir.
+func (gs *godocServer) setGoDirective(ctx context.Context, dir, goVersion string) error {
+	cmd := gs.goCommand(ctx, dir, "mod", "edit", "-go="+goVersion)
+	if out, err := gs.combinedOutput(ctx, cmd); err != nil {
+		return fmt.Errorf("failed to set go directive to %s: %w\noutput: %s", goVersion, err, out)
+	}
+	return nil
+}
+
+// matchDependencyGoVersion raises the go directive of the module in dir to
+// the one required by the module providing importPath, if that is newer.
+// Failures are logged and otherwise ignored.
+func (gs *godocServer) matchDependencyGoVersion(ctx context.Context, dir, importPath string) {
+	cmd := gs.goCommand(ctx, dir, "list", "-f", "{{with .Module}}{{.GoVersion}}{{end}}", importPath)
+	out, err := gs.output(ctx, cmd)
+	if err != nil {
+		log.Printf("Could not read the go version required by %s: %v", importPath, err)
+		return
+	}
+	depVersion := strings.TrimSpace(string(out))
+	if depVersion == "" {
+		return
+	}
+
+	mf, err := gs.readModFile(ctx, dir)
+	if err != nil {
+		log.Printf("Could not raise go directive for %s: %v", importPath, err)
+		return
+	}
+	if goversion.Compare("go"+depVersion, "go"+mf.Go) > 0 {
+		if err := gs.setGoDirective(ctx, dir, depVersion); err != nil {
+			log.Printf("Could not raise go directive for %s: %v", importPath, err)
+		}
+	}
+}
+
 // runGoDoc executes go doc with caching.
 func (gs *godocServer) runGoDoc(ctx context.Context, workingDir string, args ...string) (string, error) {
-	cacheKey := workingDir + "|" + strings.Join(args, "|")
+	return gs.runGoDocHashed(ctx, workingDir, "", args...)
+}
 
-	gs.mu.Lock()
-	if doc, ok := gs.cache[cacheKey]; ok {
-		if time.Since(doc.timestamp) < cacheTTL {
-			gs.mu.Unlock()
-			log.Printf("Cache hit for %s", cacheKey)
-			return doc.content, nil
-		}
-		delete(gs.cache, cacheKey)
+// runGoDocHashed is runGoDoc for a local package whose sources hash to
+// srcHash. The hash is part of the cache key, so edits invalidate the entry
+// immediately and an unchanged package stays cached without expiring. An
+// empty srcHash behaves like runGoDoc.
+func (gs *godocServer) runGoDocHashed(ctx context.Context, workingDir, srcHash string, args ...string) (string, error) {
+	cacheKey := gs.docCacheKey(ctx, workingDir, args)
+	if srcHash != "" {
+		cacheKey += "|src=" + srcHash
+	}
+
+	if noCache(ctx) {
+		log.Printf("Cache bypassed for %s", cacheKey)
+	} else if doc, ok := gs.cache.get(cacheKey, srcHash == ""); ok {
+		log.Printf("Cache hit for %s", cacheKey)
+		return doc, nil
 	}
-	gs.mu.Unlock()
 
-	execCtx, cancel := context.WithTimeout(ctx, cmdTimeout)
+	execCtx, cancel := commandContext(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(execCtx, "go", append([]string{"doc"}, args...)...)
-	if workingDir != "" {
-		cmd.Dir = workingDir
-	}
+	cmd := gs.goCommand(execCtx, workingDir, append([]string{"doc"}, args...)...)
 
-	out, err := cmd.CombinedOutput()
+	// Only stdout is documentation; stderr carries errors and warnings.
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := gs.output(execCtx, cmd)
 	if err != nil {
-		return "", formatGoDocError(string(out), err)
+		if execCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			return "", fmt.Errorf("%w after %s; retry with a larger timeout_seconds: %w", errGoDocTimeout, commandTimeout(ctx), err)
+		}
+		return "", formatGoDocError(stderr.String()+string(out), err)
 	}
 
 	content := string(out)
-
-	gs.mu.Lock()
-	// Evict oldest entry if cache is full.
-	if len(gs.cache) >= maxCacheSize {
-		var oldestKey string
-		var oldestTime time.Time
-		for k, v := range gs.cache {
-			if oldestKey == "" || v.timestamp.Before(oldestTime) {
-				oldestKey = k
-				oldestTime = v.timestamp
-			}
+	if stderr.Len() > 0 {
+		if gs.includeStderr {
+			content += stderr.String()
+		} else {
+			log.Printf("go doc %s wrote to stderr: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
 		}
-		delete(gs.cache, oldestKey)
 	}
-	gs.cache[cacheKey] = cachedDoc{content: content, timestamp: time.Now()}
-	gs.mu.Unlock()
+
+	gs.cache.put(cacheKey, content)
 
 	log.Printf("Cache miss for %s (%d bytes)", cacheKey, len(content))
 	return content, nil
 }
 
+// sourceHash fingerprints the non-test Go files in dir by name, size, and
+// modification time.
+func sourceHash(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\t%d\t%d\n", name, info.Size(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// localPackageDir returns the source directory for a relative or absolute
+// path argument, or "" when the path is an import path.
+func localPackageDir(pkgPath, workingDir string) string {
+	switch {
+	case strings.HasPrefix(pkgPath, "."):
+		return filepath.Join(workingDir, pkgPath)
+	case filepath.IsAbs(pkgPath):
+		return pkgPath
+	}
+	return ""
+}
+
+// docCacheKey builds the cache key for a go doc invocation. With session
+// isolation enabled, keys are scoped to the calling client's session so one
+// client's results are never served to another. The request's -mod mode is
+// part of the key.
+func (gs *godocServer) docCacheKey(ctx context.Context, workingDir string, args []string) string {
+	key := workingDir + "|" + strings.Join(args, "|")
+	if mode := modMode(ctx); mode != "" {
+		key += "|-mod=" + mode
+	}
+	if exp := goExperiment(ctx); exp != "" {
+		key += "|GOEXPERIMENT=" + exp
+	}
+	if gs.sessionCache {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			key = session.SessionID() + "|" + key
+		}
+	}
+	return key
+}
+
 // formatGoDocError returns an enhanced error message with suggestions.
 func formatGoDocError(output string, err error) error {
+	if m := toolchainErrorPattern.FindStringSubmatch(output); m != nil {
+		if m[4] != "" {
+			return fmt.Errorf("%w: %s could not be downloaded; check network access and GOPROXY, or install it and run the server with -gotoolchain=%s\nDetail: %w",
+				errToolchain, m[4], m[4], err)
+		}
+		fix := "-gotoolchain=auto"
+		if m[3] == "local" {
+			fix += " (and without -no-toolchain-download)"
+		}
+		return fmt.Errorf("%w: the module requires go %s, but the installed toolchain is go %s and GOTOOLCHAIN=%s forbids switching; "+
+			"run the server with %s to download go%s automatically, or install a newer Go\nDetail: %w",
+			errToolchain, m[1], m[2], m[3], fix, m[1], err)
+	}
+	if lines := buildErrorLines(output); len(lines) > 0 {
+		return fmt.Errorf("%w (the package exists, but it or its dependencies could not be loaded):\n%s\nDetail: %w",
+			errModuleBuild, strings.Join(lines, "\n"), err)
+	}
+
 	switch {
 	case strings.Contains(output, "no such package") || strings.Contains(output, "is not in std"):
 		return fmt.Errorf("package not found:\n"+
@@ -516,20 +2341,36 @@ func formatGoDocError(output string, err error) error {
 			"4. Check for typos in the package name\n"+
 			"Detail: %s", output)
 
-	case strings.Contains(output, "no such symbol"):
-		return fmt.Errorf("symbol not found:\n"+
+	case strings.Contains(output, "no such symbol") || strings.Contains(output, "doc: no symbol ") ||
+		strings.Contains(output, "doc: no method or field "):
+		return fmt.Errorf("%w:\n"+
 			"1. Check if the symbol name is correct (case-sensitive)\n"+
 			"2. Use -u flag to see unexported symbols\n"+
 			"3. Use -all flag to see all package documentation\n"+
-			"Detail: %w", err)
+			"Detail: %w", errSymbolNotFound, err)
 
 	case strings.Contains(output, "build constraints exclude all Go files"):
-		return fmt.Errorf("no Go files for current platform; try -all flag or set GOOS/GOARCH: %w", err)
+		return fmt.Errorf("%w; try -all flag or set GOOS/GOARCH: %w", errNoBuildableFiles, err)
 	}
 
 	return fmt.Errorf("go doc error: %w\noutput: %s", err, output)
 }
 
+// buildErrorLines returns the lines of go command output that report a
+// build or module failure, with go doc's "doc: " prefix removed.
+func buildErrorLines(output string) []string {
+	var lines []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "doc: ")
+		if buildErrorPattern.MatchString(line) && !seen[line] {
+			seen[line] = true
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
 // isStdLib returns true if the package path looks like a standard library package.
 // Standard library packages do not contain a dot in the first path element.
 func isStdLib(pkg string) bool {
@@ -2,9 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -135,6 +139,54 @@ func TestValidatePath(t *testing.T) {
 		}
 	})
 
+	t.Run("pkg.go.dev URL", func(t *testing.T) {
+		tests := map[string]string{
+			"https://pkg.go.dev/github.com/user/repo@v1.2.3/sub/pkg": "github.com/user/repo/sub/pkg@v1.2.3",
+			"https://pkg.go.dev/github.com/user/repo@v1.2.3":         "github.com/user/repo@v1.2.3",
+			"https://pkg.go.dev/net/http#Client":                     "net/http",
+			"pkg.go.dev/gopkg.in/yaml.v3?tab=doc":                    "gopkg.in/yaml.v3",
+		}
+		for in, want := range tests {
+			resolved, _, err := validatePath(in, "")
+			if err != nil {
+				t.Fatalf("validatePath(%q): unexpected error: %v", in, err)
+			}
+			if resolved != want {
+				t.Errorf("validatePath(%q) = %q, want %q", in, resolved, want)
+			}
+		}
+	})
+
+	t.Run("repository URL", func(t *testing.T) {
+		tests := map[string]string{
+			"https://github.com/user/repo/blob/v1.2.3/pkg/foo/bar.go":         "github.com/user/repo/pkg/foo@v1.2.3",
+			"https://github.com/user/repo/blob/main/bar.go#L10-L20":           "github.com/user/repo@main",
+			"https://github.com/user/repo/tree/v1.2.3/pkg/foo":                "github.com/user/repo/pkg/foo@v1.2.3",
+			"https://github.com/user/repo/blob/v2.1.0/pkg/foo/bar.go":         "github.com/user/repo/v2/pkg/foo@v2.1.0",
+			"https://github.com/user/repo/blob/v2.1.0/v2/pkg/bar.go":          "github.com/user/repo/v2/pkg@v2.1.0",
+			"https://github.com/user/repo":                                    "github.com/user/repo",
+			"https://www.github.com/user/repo.git":                            "github.com/user/repo",
+			"https://gitlab.com/group/sub/project/-/blob/v1.0.0/api/x.go":     "gitlab.com/group/sub/project/api@v1.0.0",
+			"https://gitlab.com/group/project/-/tree/main/api?ref_type=heads": "gitlab.com/group/project/api@main",
+			"https://bitbucket.org/user/repo/src/abc1234/internal/db/db.go":   "bitbucket.org/user/repo/internal/db@abc1234",
+		}
+		for in, want := range tests {
+			resolved, _, err := validatePath(in, "")
+			if err != nil {
+				t.Fatalf("validatePath(%q): unexpected error: %v", in, err)
+			}
+			if resolved != want {
+				t.Errorf("validatePath(%q) = %q, want %q", in, resolved, want)
+			}
+		}
+
+		for _, in := range []string{"https://github.com/user", "https://example.com/user/repo/blob/v1.0.0/x.go"} {
+			if got, ok := trimRepoURL(in); ok {
+				t.Errorf("trimRepoURL(%q) = %q, want no match", in, got)
+			}
+		}
+	})
+
 	t.Run("relative path without working_dir", func(t *testing.T) {
 		_, _, err := validatePath("./pkg", "")
 		if err == nil {
@@ -167,6 +219,239 @@ func TestValidatePath(t *testing.T) {
 			t.Errorf("got %q, want %q", resolved, "github.com/test/proj/sub/pkg")
 		}
 	})
+
+	t.Run("nested working_dir", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/test/proj\n"), 0644)
+		nested := filepath.Join(dir, "internal", "store")
+		os.MkdirAll(nested, 0755)
+
+		tests := map[string]string{
+			".":        "github.com/test/proj/internal/store",
+			"./sql":    "github.com/test/proj/internal/store/sql",
+			"../cache": "github.com/test/proj/internal/cache",
+		}
+		for in, want := range tests {
+			resolved, _, err := validatePath(in, nested)
+			if err != nil {
+				t.Fatalf("validatePath(%q): unexpected error: %v", in, err)
+			}
+			if resolved != want {
+				t.Errorf("validatePath(%q) = %q, want %q", in, resolved, want)
+			}
+		}
+
+		resolved, _, err := validatePath(nested, nested)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resolved != "github.com/test/proj/internal/store" {
+			t.Errorf("got %q, want %q", resolved, "github.com/test/proj/internal/store")
+		}
+	})
+
+	t.Run("no go.mod above working_dir", func(t *testing.T) {
+		if _, err := findModuleRoot(t.TempDir()); err == nil {
+			t.Skip("a go.mod exists above the temporary directory")
+		}
+		if _, _, err := validatePath(".", t.TempDir()); err == nil {
+			t.Fatal("expected error when no go.mod is found")
+		}
+	})
+}
+
+func TestSplitSymbolPath(t *testing.T) {
+	tests := []struct {
+		in         string
+		wantPkg    string
+		wantTarget string
+	}{
+		{"net/http.Client.Do", "net/http", "Client.Do"},
+		{"net/http.Client", "net/http", "Client"},
+		{"io.Reader", "io", "Reader"},
+		{"io", "io", ""},
+		{"net/http", "net/http", ""},
+		{"github.com/user/repo.Type", "github.com/user/repo", "Type"},
+		{"github.com/user/repo", "github.com/user/repo", ""},
+		{"gopkg.in/yaml.v3", "gopkg.in/yaml.v3", ""},
+		{"gopkg.in/yaml.v3.Node", "gopkg.in/yaml.v3", "Node"},
+		{"./pkg.Type", "./pkg", "Type"},
+		{".", ".", ""},
+		{"fmt.Stringer.String.Extra", "fmt.Stringer.String.Extra", ""},
+		{"/abs/path.Type", "/abs/path.Type", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			pkg, target := splitSymbolPath(tt.in)
+			if pkg != tt.wantPkg || target != tt.wantTarget {
+				t.Errorf("splitSymbolPath(%q) = (%q, %q), want (%q, %q)", tt.in, pkg, target, tt.wantPkg, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestPkgGoDevSymbol(t *testing.T) {
+	tests := map[string]string{
+		"https://pkg.go.dev/net/http#Client":                   "Client",
+		"https://pkg.go.dev/net/http#Client.Do":                "Client.Do",
+		"pkg.go.dev/github.com/user/repo@v1.2.3/sub#Type":      "Type",
+		"https://pkg.go.dev/net/http#section-documentation":    "",
+		"https://pkg.go.dev/net/http#example-Client":           "",
+		"https://pkg.go.dev/net/http#pkg-constants":            "",
+		"https://pkg.go.dev/net/http":                          "",
+		"https://github.com/user/repo/blob/main/foo.go#L10":    "",
+		"https://pkg.go.dev/net/http#Client.Transport.RoundTr": "",
+	}
+	for in, want := range tests {
+		if got := pkgGoDevSymbol(in); got != want {
+			t.Errorf("pkgGoDevSymbol(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHandleGetDocPkgGoDevURL(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+	out, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		t.Fatalf("go env GOVERSION: %v", err)
+	}
+	goVersion := strings.TrimSpace(string(out))
+
+	gs := newGodocServer()
+	defer gs.cleanup()
+	ctx := context.Background()
+
+	call := func(path string) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = map[string]any{"path": path}
+		result, err := gs.handleGetDoc(ctx, req)
+		if err != nil {
+			t.Fatalf("handleGetDoc(%q): %v", path, err)
+		}
+		return result
+	}
+
+	// The anchor selects the symbol, and the running release is accepted.
+	result := call("https://pkg.go.dev/io@" + goVersion + "#Reader.Read")
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "Read(p []byte)") {
+		t.Errorf("expected Reader.Read documentation, got:\n%s", text)
+	}
+
+	result = call("https://pkg.go.dev/io@go1.0.0#Reader")
+	text = result.Content[0].(mcp.TextContent).Text
+	if !result.IsError || !strings.Contains(text, goVersion) {
+		t.Errorf("expected a toolchain mismatch error naming %s, got:\n%s", goVersion, text)
+	}
+}
+
+func TestMatchPackagePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"github.com/acme/secret", "github.com/acme/secret", true},
+		{"github.com/acme/secret", "github.com/acme/secret/sub", false},
+		{"github.com/acme/*", "github.com/acme/secret", true},
+		{"github.com/acme/*", "github.com/acme/secret/sub", false},
+		{"github.com/acme/...", "github.com/acme", true},
+		{"github.com/acme/...", "github.com/acme/secret/sub", true},
+		{"github.com/acme/...", "github.com/acmeco/pkg", false},
+		{"*.corp.example.com/...", "git.corp.example.com/team/pkg", true},
+		{"*.corp.example.com/...", "github.com/team/pkg", false},
+		{"net/http", "net/http/httptest", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			if got := matchPackagePattern(tt.pattern, tt.path); got != tt.want {
+				t.Errorf("matchPackagePattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleGetDocDeniedPackage(t *testing.T) {
+	gs := newGodocServer(withDenyPackages([]string{"github.com/acme/..."}))
+	defer gs.cleanup()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_doc"
+	req.Params.Arguments = map[string]any{
+		"path": "github.com/acme/secret",
+	}
+
+	result, err := gs.handleGetDoc(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleGetDoc returned protocol error: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected tool error for denied package")
+	}
+	if len(gs.projects) != 0 {
+		t.Error("expected no temp project for denied package")
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "not permitted") {
+		t.Errorf("unexpected error: %s", text)
+	}
+}
+
+func TestAllowedRoots(t *testing.T) {
+	root := writeModule(t, map[string]string{
+		"allowed/go.mod": "module example.com/allowed\n\ngo 1.21\n",
+		"allowed/a.go":   "// Package allowed is inside the root.\npackage allowed\n",
+		"outside/go.mod": "module example.com/outside\n\ngo 1.21\n",
+		"outside/o.go":   "package outside\n",
+	})
+	allowed := filepath.Join(root, "allowed")
+	outside := filepath.Join(root, "outside")
+	if err := os.Symlink(outside, filepath.Join(allowed, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	gs := newGodocServer(withAllowedRoots([]string{allowed}))
+
+	for _, tt := range []struct {
+		name, pkgPath, workingDir string
+		ok                        bool
+	}{
+		{"inside", ".", allowed, true},
+		{"no working dir", "io", "", true},
+		{"outside", ".", outside, false},
+		{"dot-dot escape", "../outside", allowed, false},
+		{"dot-dot in working dir", ".", filepath.Join(allowed, "..", "outside"), false},
+		{"symlink escape", ".", filepath.Join(allowed, "escape"), false},
+		{"absolute path", outside, "", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := gs.checkPathsAllowed(tt.pkgPath, tt.workingDir)
+			if (err == nil) != tt.ok {
+				t.Errorf("checkPathsAllowed(%q, %q) = %v, want ok=%v", tt.pkgPath, tt.workingDir, err, tt.ok)
+			}
+		})
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_doc"
+	req.Params.Arguments = map[string]any{"path": ".", "working_dir": outside}
+	result, err := gs.handleGetDoc(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleGetDoc returned protocol error: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "outside the allowed roots") {
+		t.Errorf("expected allowed roots error, got %+v", result.Content)
+	}
+
+	if err := newGodocServer().checkPathsAllowed(".", outside); err != nil {
+		t.Errorf("without -allowed-roots every directory is allowed: %v", err)
+	}
 }
 
 func TestPaginate(t *testing.T) {
@@ -180,6 +465,9 @@ func TestPaginate(t *testing.T) {
 		if !strings.HasPrefix(result, "Page 1 of 3") {
 			t.Errorf("unexpected metadata: %s", firstLine(result))
 		}
+		if !strings.Contains(firstLine(result), "is_last_page: false") {
+			t.Errorf("expected is_last_page: false: %s", firstLine(result))
+		}
 	})
 
 	t.Run("last page", func(t *testing.T) {
@@ -190,6 +478,9 @@ func TestPaginate(t *testing.T) {
 		if !strings.HasPrefix(result, "Page 3 of 3") {
 			t.Errorf("unexpected metadata: %s", firstLine(result))
 		}
+		if !strings.Contains(firstLine(result), "is_last_page: true") {
+			t.Errorf("expected last page signal: %s", firstLine(result))
+		}
 	})
 
 	t.Run("page exceeds total", func(t *testing.T) {
@@ -230,86 +521,45 @@ func TestPaginate(t *testing.T) {
 	})
 }
 
-func TestCacheEviction(t *testing.T) {
-	gs := &godocServer{
-		cache: make(map[string]cachedDoc),
-	}
-
-	// Fill cache to maxCacheSize.
-	for i := 0; i < maxCacheSize; i++ {
-		key := strings.Repeat("x", i+1)
-		gs.cache[key] = cachedDoc{
-			content:   "content",
-			timestamp: time.Now().Add(-time.Duration(maxCacheSize-i) * time.Second),
-		}
-	}
-
-	if len(gs.cache) != maxCacheSize {
-		t.Fatalf("cache size = %d, want %d", len(gs.cache), maxCacheSize)
+func TestPageCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		pageSize int
+		want     int
+	}{
+		{"empty", "", 100, 1},
+		{"single line", "one", 100, 1},
+		{"exact fit", strings.Join(makeLines(100), "\n"), 100, 1},
+		{"one over", strings.Join(makeLines(101), "\n"), 100, 2},
+		{"many pages", strings.Join(makeLines(250), "\n"), 100, 3},
 	}
 
-	// Inserting one more entry should trigger eviction of the oldest.
-	gs.mu.Lock()
-	if len(gs.cache) >= maxCacheSize {
-		var oldestKey string
-		var oldestTime time.Time
-		for k, v := range gs.cache {
-			if oldestKey == "" || v.timestamp.Before(oldestTime) {
-				oldestKey = k
-				oldestTime = v.timestamp
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageCount(tt.content, tt.pageSize); got != tt.want {
+				t.Errorf("pageCount() = %d, want %d", got, tt.want)
 			}
-		}
-		delete(gs.cache, oldestKey)
-	}
-	gs.cache["new-entry"] = cachedDoc{content: "new", timestamp: time.Now()}
-	gs.mu.Unlock()
-
-	if len(gs.cache) != maxCacheSize {
-		t.Errorf("cache size after eviction = %d, want %d", len(gs.cache), maxCacheSize)
-	}
-
-	if _, ok := gs.cache["new-entry"]; !ok {
-		t.Error("new entry not found in cache")
+		})
 	}
 }
 
-func TestCacheExpiry(t *testing.T) {
-	gs := &godocServer{
-		cache: make(map[string]cachedDoc),
-	}
-
-	gs.cache["expired"] = cachedDoc{
-		content:   "old",
-		timestamp: time.Now().Add(-cacheTTL - time.Second),
-	}
-	gs.cache["fresh"] = cachedDoc{
-		content:   "new",
-		timestamp: time.Now(),
-	}
+func TestDocCacheKeySessionIsolation(t *testing.T) {
+	args := []string{"io", "Reader"}
 
-	// Fresh entry should be returned.
-	gs.mu.Lock()
-	if doc, ok := gs.cache["fresh"]; ok && time.Since(doc.timestamp) < cacheTTL {
-		gs.mu.Unlock()
-		if doc.content != "new" {
-			t.Errorf("fresh cache content = %q, want %q", doc.content, "new")
-		}
-	} else {
-		gs.mu.Unlock()
-		t.Error("fresh cache entry not found or expired unexpectedly")
+	shared := newGodocServer()
+	ctxA := shared.mcpServer.WithContext(context.Background(), testSession("a"))
+	ctxB := shared.mcpServer.WithContext(context.Background(), testSession("b"))
+	if shared.docCacheKey(ctxA, "", args) != shared.docCacheKey(ctxB, "", args) {
+		t.Error("expected shared cache keys by default")
 	}
 
-	// Expired entry should be evicted on access.
-	gs.mu.Lock()
-	if doc, ok := gs.cache["expired"]; ok {
-		if time.Since(doc.timestamp) >= cacheTTL {
-			delete(gs.cache, "expired")
-		}
+	isolated := newGodocServer(withSessionCache(true))
+	if isolated.docCacheKey(ctxA, "", args) == isolated.docCacheKey(ctxB, "", args) {
+		t.Error("expected distinct cache keys per session")
 	}
-	gs.mu.Unlock()
-
-	if _, ok := gs.cache["expired"]; ok {
-		t.Error("expired entry should have been evicted")
+	if isolated.docCacheKey(context.Background(), "", args) != shared.docCacheKey(context.Background(), "", args) {
+		t.Error("expected unscoped key without a session")
 	}
 }
 
@@ -362,14 +612,14 @@ func TestProjectCache(t *testing.T) {
 
 func TestProjectCacheExpiry(t *testing.T) {
 	gs := &godocServer{
-		cache:    make(map[string]cachedDoc),
-		projects: make(map[string]cachedProject),
+		cache:    newDocCache(1),
+		projects: make(map[string]*cachedProject),
 	}
 	defer gs.cleanup()
 
 	// Insert an expired project entry.
 	expiredDir := t.TempDir()
-	gs.projects["expired-pkg"] = cachedProject{
+	gs.projects["expired-pkg"] = &cachedProject{
 		dir:       expiredDir,
 		timestamp: time.Now().Add(-projectTTL - time.Second),
 	}
@@ -385,6 +635,197 @@ func TestProjectCacheExpiry(t *testing.T) {
 	}
 }
 
+func TestProjectCacheExpiredReuse(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	// Build a project depending on a module this repository already has in
+	// the local module cache, so no network access is needed.
+	mod, err := os.ReadFile("go.mod")
+	if err != nil {
+		t.Fatalf("reading go.mod: %v", err)
+	}
+	sum, err := os.ReadFile("go.sum")
+	if err != nil {
+		t.Fatalf("reading go.sum: %v", err)
+	}
+	dir := writeModule(t, map[string]string{
+		"go.mod": strings.Replace(string(mod), "module github.com/mrjoshuak/godoc-mcp", "module godoc-temp", 1),
+		"go.sum": string(sum),
+	})
+
+	gs := newGodocServer()
+	ctx := context.Background()
+	const pkg = "github.com/mark3labs/mcp-go/mcp"
+	if !gs.moduleDownloaded(ctx, dir, pkg) {
+		t.Skip("mcp-go not resolvable from the local module cache")
+	}
+	if gs.moduleDownloaded(ctx, dir, "example.com/not/required") {
+		t.Error("expected unrequired module to be reported missing")
+	}
+
+	gs.projects[pkg] = &cachedProject{dir: dir, timestamp: time.Now().Add(-projectTTL - time.Second)}
+
+	got, err := gs.getOrCreateProject(ctx, pkg)
+	if err != nil {
+		t.Fatalf("getOrCreateProject: %v", err)
+	}
+	if got != dir {
+		t.Errorf("expected expired project %q to be reused, got %q", dir, got)
+	}
+	if time.Since(gs.projects[pkg].timestamp) >= projectTTL {
+		t.Error("expected reused project timestamp to be refreshed")
+	}
+}
+
+func TestProjectSlots(t *testing.T) {
+	gs := newGodocServer(withMaxTempProjects(1))
+	ctx := context.Background()
+
+	if err := gs.acquireProjectSlot(ctx); err != nil {
+		t.Fatalf("first slot: %v", err)
+	}
+	dir := t.TempDir()
+	gs.projects["example.com/old"] = &cachedProject{dir: dir, timestamp: time.Now()}
+
+	// The limit is reached, so the cached project makes way for a new one.
+	if err := gs.acquireProjectSlot(ctx); err != nil {
+		t.Fatalf("second slot: %v", err)
+	}
+	if _, ok := gs.projects["example.com/old"]; ok {
+		t.Error("expected the oldest project to be evicted")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected evicted project directory to be removed: %v", err)
+	}
+
+	// With nothing left to evict, a request waits until its context ends.
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := gs.acquireProjectSlot(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline error at the limit, got %v", err)
+	}
+
+	gs.releaseProjectSlot()
+	if err := gs.acquireProjectSlot(ctx); err != nil {
+		t.Errorf("slot not freed by release: %v", err)
+	}
+}
+
+func TestProjectEvictionSkipsInUse(t *testing.T) {
+	gs := newGodocServer(withMaxTempProjects(2))
+	defer gs.cleanup()
+	ctx := context.Background()
+
+	leases := &projectLeases{}
+	leaseCtx := context.WithValue(ctx, projectLeasesKey{}, leases)
+	busyDir, idleDir := t.TempDir(), t.TempDir()
+	busy := &cachedProject{dir: busyDir, timestamp: time.Now()}
+	idle := &cachedProject{dir: idleDir, timestamp: time.Now()}
+	for _, proj := range []*cachedProject{busy, idle} {
+		if err := gs.acquireProjectSlot(ctx); err != nil {
+			t.Fatal(err)
+		}
+		gs.mu.Lock()
+		gs.useProject(leaseCtx, proj)
+		gs.mu.Unlock()
+	}
+	gs.projects["example.com/busy"] = busy
+	gs.projects["example.com/idle"] = idle
+	gs.releaseProjects(&projectLeases{projects: []*cachedProject{idle}})
+
+	// busy was used less recently, but a request still holds it.
+	if err := gs.acquireProjectSlot(ctx); err != nil {
+		t.Fatalf("acquireProjectSlot: %v", err)
+	}
+	if _, err := os.Stat(busyDir); err != nil {
+		t.Errorf("in-use project was removed: %v", err)
+	}
+	if _, err := os.Stat(idleDir); !os.IsNotExist(err) {
+		t.Error("expected the idle project to be evicted")
+	}
+
+	// Dropping busy defers its removal until the request returns.
+	gs.cleanup()
+	if _, err := os.Stat(busyDir); err != nil {
+		t.Errorf("in-use project was removed by cleanup: %v", err)
+	}
+	gs.releaseProjects(leases)
+	if _, err := os.Stat(busyDir); !os.IsNotExist(err) {
+		t.Error("expected the dropped project to be removed on release")
+	}
+}
+
+func TestProjectEvictionOrder(t *testing.T) {
+	gs := newGodocServer(withMaxTempProjects(2))
+	defer gs.cleanup()
+
+	older, newer := t.TempDir(), t.TempDir()
+	now := time.Now()
+	gs.projects["example.com/older"] = &cachedProject{dir: older, timestamp: now.Add(-time.Minute), lastUsed: now}
+	gs.projects["example.com/newer"] = &cachedProject{dir: newer, timestamp: now, lastUsed: now.Add(-time.Second)}
+
+	if !gs.evictOldestProject() {
+		t.Fatal("expected a project to be evicted")
+	}
+	if _, ok := gs.projects["example.com/newer"]; ok {
+		t.Error("expected the least recently used project to be evicted")
+	}
+	if _, ok := gs.projects["example.com/older"]; !ok {
+		t.Error("recently used project was evicted")
+	}
+}
+
+func TestSweepStaleProjects(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	exited := exec.Command(os.Args[0], "-test.run=^$")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := filepath.Join(tmp, "godoc-mcp-111")
+	unowned := filepath.Join(tmp, "godoc-mcp-222")
+	recent := filepath.Join(tmp, "godoc-mcp-333")
+	live := filepath.Join(tmp, "godoc-mcp-444")
+	other := filepath.Join(tmp, "other-555")
+	owners := map[string]int{stale: exited.Process.Pid, live: os.Getpid()}
+	for _, dir := range []string{stale, unowned, recent, live, other} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if pid, ok := owners[dir]; ok {
+			owner := []byte(strconv.Itoa(pid) + "\n")
+			if err := os.WriteFile(filepath.Join(dir, projectOwnerFile), owner, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// Age does not matter for owned directories, only whether the owner runs.
+	old := time.Now().Add(-projectTTL - time.Minute)
+	for _, dir := range []string{stale, unowned, live, other} {
+		if err := os.Chtimes(dir, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := sweepStaleProjects(); n != 2 {
+		t.Errorf("sweepStaleProjects removed %d directories, want 2", n)
+	}
+	for _, dir := range []string{stale, unowned} {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", dir)
+		}
+	}
+	for _, dir := range []string{recent, live, other} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("%s should be kept: %v", dir, err)
+		}
+	}
+}
+
 func TestReadOnlyHint(t *testing.T) {
 	gs := newGodocServer()
 
@@ -397,6 +838,51 @@ func TestReadOnlyHint(t *testing.T) {
 	}
 }
 
+func TestToolsOption(t *testing.T) {
+	all := newGodocServer().mcpServer.ListTools()
+	if all["get_doc"] == nil || all["read_lines"] == nil {
+		t.Fatalf("default server is missing tools: %d registered", len(all))
+	}
+
+	gs := newGodocServer(withTools([]string{"get_doc", "list_symbols", "no_such_tool"}))
+	tools := gs.mcpServer.ListTools()
+	if len(tools) != 2 || tools["get_doc"] == nil || tools["list_symbols"] == nil {
+		names := make([]string, 0, len(tools))
+		for name := range tools {
+			names = append(names, name)
+		}
+		t.Errorf("registered tools = %v, want get_doc and list_symbols", names)
+	}
+
+	if tools := newGodocServer(withTools(nil)).mcpServer.ListTools(); len(tools) != len(all) {
+		t.Errorf("empty tool list registered %d tools, want all %d", len(tools), len(all))
+	}
+}
+
+func TestCreateTempProjectOptions(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer(withTempModule("example.com/scratch", "1.21"))
+	dir, err := gs.createTempProject(context.Background(), "io")
+	if err != nil {
+		t.Fatalf("createTempProject: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatalf("reading go.mod: %v", err)
+	}
+	if !strings.Contains(string(content), "module example.com/scratch") {
+		t.Errorf("expected custom module name, got:\n%s", content)
+	}
+	if !strings.Contains(string(content), "go 1.21") {
+		t.Errorf("expected pinned go directive, got:\n%s", content)
+	}
+}
+
 // Integration tests that require the Go toolchain.
 
 func TestRunGoDocStdlib(t *testing.T) {
@@ -404,14 +890,12 @@ func TestRunGoDocStdlib(t *testing.T) {
 		t.Skip("go not found in PATH")
 	}
 
-	gs := &godocServer{
-		cache: make(map[string]cachedDoc),
-	}
+	gs := newGodocServer()
 
 	ctx := context.Background()
 
 	// Create a temp project for the stdlib lookup.
-	tempDir, err := createTempProject(ctx, "io")
+	tempDir, err := gs.createTempProject(ctx, "io")
 	if err != nil {
 		t.Fatalf("createTempProject: %v", err)
 	}
@@ -432,13 +916,11 @@ func TestRunGoDocSymbol(t *testing.T) {
 		t.Skip("go not found in PATH")
 	}
 
-	gs := &godocServer{
-		cache: make(map[string]cachedDoc),
-	}
+	gs := newGodocServer()
 
 	ctx := context.Background()
 
-	tempDir, err := createTempProject(ctx, "io")
+	tempDir, err := gs.createTempProject(ctx, "io")
 	if err != nil {
 		t.Fatalf("createTempProject: %v", err)
 	}
@@ -460,6 +942,7 @@ func TestHandleGetDocStdlib(t *testing.T) {
 	}
 
 	gs := newGodocServer()
+	defer gs.cleanup()
 
 	req := mcp.CallToolRequest{}
 	req.Params.Name = "get_doc"
@@ -476,6 +959,11 @@ func TestHandleGetDocStdlib(t *testing.T) {
 		t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
 	}
 
+	// Stdlib lookups are served from GOROOT without a temp project.
+	if len(gs.projects) != 0 {
+		t.Errorf("expected no temp projects for stdlib lookup, got %d", len(gs.projects))
+	}
+
 	// Check that the result contains fmt package documentation.
 	for _, c := range result.Content {
 		if tc, ok := c.(mcp.TextContent); ok {
@@ -487,9 +975,604 @@ func TestHandleGetDocStdlib(t *testing.T) {
 	t.Error("expected 'Package fmt' in tool result content")
 }
 
-func TestHandleGetDocBadFlag(t *testing.T) {
-	gs := newGodocServer()
-
+func TestHandleGetDocHideMetadata(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer()
+	defer gs.cleanup()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_doc"
+	req.Params.Arguments = map[string]any{
+		"path":          "io",
+		"target":        "Reader",
+		"show_metadata": false,
+	}
+
+	result, err := gs.handleGetDoc(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleGetDoc returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+	}
+
+	tc, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("unexpected content type %T", result.Content[0])
+	}
+	if strings.HasPrefix(tc.Text, "Page ") {
+		t.Errorf("expected metadata to be omitted, got: %s", firstLine(tc.Text))
+	}
+}
+
+func TestSourceHash(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"a.go":      "package a\n",
+		"a_test.go": "package a\n",
+	})
+
+	h1, err := sourceHash(dir)
+	if err != nil {
+		t.Fatalf("sourceHash: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a_test.go"), []byte("package a\n\n// changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if h2, _ := sourceHash(dir); h2 != h1 {
+		t.Error("test file edits should not change the hash")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\nfunc F() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if h3, _ := sourceHash(dir); h3 == h1 {
+		t.Error("source edits should change the hash")
+	}
+}
+
+func TestHandleGetDocLocalCacheInvalidation(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":   "module example.com/local\n\ngo 1.21\n",
+		"local.go": "package local\n\n// Version reports the first version.\nfunc Version() string { return \"1\" }\n",
+	})
+
+	gs := newGodocServer()
+	call := func() string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = map[string]any{"path": ".", "target": "Version", "working_dir": dir}
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetDoc failed: %v %+v", err, result)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	if text := call(); !strings.Contains(text, "first version") {
+		t.Fatalf("unexpected doc:\n%s", text)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "local.go"), []byte("package local\n\n// Version reports the edited version.\nfunc Version() string { return \"2\" }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if text := call(); !strings.Contains(text, "edited version") {
+		t.Errorf("expected edit to invalidate the cache, got:\n%s", text)
+	}
+}
+
+func TestHandleGetDocStdCollision(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	t.Run("module named like std", func(t *testing.T) {
+		dir := writeModule(t, map[string]string{
+			"go.mod": "module io\n\ngo 1.21\n",
+			"io.go":  "package io\n\n// Local is only in the local module.\nfunc Local() {}\n",
+		})
+		text := call(map[string]any{"path": ".", "working_dir": dir})
+		for _, want := range []string{`Note: "io" is both a standard library package`, "func Local()"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %q in output:\n%s", want, text)
+			}
+		}
+		if strings.Contains(text, "type Reader interface") {
+			t.Errorf("expected local docs, got standard library io:\n%s", text)
+		}
+	})
+
+	t.Run("local package named like std", func(t *testing.T) {
+		dir := writeModule(t, map[string]string{
+			"go.mod":       "module example.com/app\n\ngo 1.21\n",
+			"sort/sort.go": "package sort\n\n// Mine is a local sort helper.\nfunc Mine() {}\n",
+		})
+		text := call(map[string]any{"path": "sort", "working_dir": dir})
+		for _, want := range []string{"showing the local package example.com/app/sort", "func Mine()"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %q in output:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("no collision", func(t *testing.T) {
+		dir := writeModule(t, map[string]string{
+			"go.mod": "module example.com/app\n\ngo 1.21\n",
+		})
+		text := call(map[string]any{"path": "sort", "working_dir": dir, "target": "Ints"})
+		if strings.Contains(text, "standard library package") || !strings.Contains(text, "func Ints(") {
+			t.Errorf("expected plain standard library docs:\n%s", text)
+		}
+	})
+}
+
+func TestStripPackageHeader(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"package io // import \"io\"\n\ntype Reader interface{}\n", "type Reader interface{}\n"},
+		{"type Reader interface{}\n", "type Reader interface{}\n"},
+		{"package main\n\nfunc main()\n", "package main\n\nfunc main()\n"},
+	}
+	for _, tt := range tests {
+		if got := stripPackageHeader(tt.in); got != tt.want {
+			t.Errorf("stripPackageHeader(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHandleGetDocStripHeader(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	const header = "package io // import \"io\""
+	if text := call(map[string]any{"path": "io", "target": "Reader", "show_metadata": false}); strings.Contains(text, header) {
+		t.Errorf("symbol query should omit the package header by default:\n%s", text)
+	}
+	if text := call(map[string]any{"path": "io", "target": "Reader", "show_metadata": false, "strip_header": false}); !strings.HasPrefix(text, header) {
+		t.Errorf("strip_header=false should keep the package header:\n%s", text)
+	}
+	if text := call(map[string]any{"path": "io", "show_metadata": false}); !strings.HasPrefix(text, header) {
+		t.Errorf("package query should keep the package header by default:\n%s", firstLine(text))
+	}
+}
+
+func TestDocTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      any
+		want    []string
+		wantErr bool
+	}{
+		{"missing", nil, []string{""}, false},
+		{"string", "Reader", []string{"Reader"}, false},
+		{"array", []any{"Reader", "Writer"}, []string{"Reader", "Writer"}, false},
+		{"string slice", []string{"Copy"}, []string{"Copy"}, false},
+		{"empty array", []any{}, nil, true},
+		{"empty name", []any{"Reader", ""}, nil, true},
+		{"non-string item", []any{"Reader", 3.0}, nil, true},
+		{"number", 3.0, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := docTargets(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("docTargets(%v) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("docTargets(%v) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleGetDocMultipleTargets(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_doc"
+	req.Params.Arguments = map[string]any{"path": "io", "target": []any{"Reader", "NoSuchThing", "Copy"}, "show_metadata": false}
+	result, err := gs.handleGetDoc(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleGetDoc returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+
+	reader := strings.Index(text, "=== Reader ===")
+	missing := strings.Index(text, "=== NoSuchThing ===")
+	copyDoc := strings.Index(text, "=== Copy ===")
+	if reader != 0 || missing < reader || copyDoc < missing {
+		t.Fatalf("expected sections in request order:\n%s", text)
+	}
+	if !strings.Contains(text[reader:missing], "type Reader interface") {
+		t.Errorf("Reader section missing its declaration:\n%s", text[reader:missing])
+	}
+	if !strings.Contains(text[missing:copyDoc], "Error: ") {
+		t.Errorf("expected an error for the unknown symbol:\n%s", text[missing:copyDoc])
+	}
+	if !strings.Contains(text[copyDoc:], "func Copy(dst Writer, src Reader)") {
+		t.Errorf("Copy section missing its signature:\n%s", text[copyDoc:])
+	}
+	if strings.Contains(text, "package io // import") {
+		t.Errorf("symbol sections should omit the package header:\n%s", text)
+	}
+}
+
+func TestHandleGetDocSignatureOnly(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer()
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		return result
+	}
+
+	result := call(map[string]any{"path": "strings", "target": "Cut", "signature_only": true, "show_metadata": false})
+	if result.IsError {
+		t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "func Cut(s, sep string) (before, after string, found bool)\n" {
+		t.Errorf("unexpected signature: %q", text)
+	}
+
+	result = call(map[string]any{"path": "strings", "target": "Builder", "signature_only": true, "show_metadata": false})
+	if result.IsError {
+		t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.HasPrefix(text, "type Builder struct {") || !strings.Contains(text, "func (b *Builder) WriteString(s string) (int, error)") {
+		t.Errorf("expected type definition and method signatures:\n%s", text)
+	}
+	if strings.Contains(text, "A Builder is used") {
+		t.Errorf("signature_only should omit doc prose:\n%s", text)
+	}
+
+	if result := call(map[string]any{"path": "strings", "signature_only": true}); !result.IsError {
+		t.Error("expected error for signature_only without a target")
+	}
+}
+
+func TestHandleGetDocSynopsis(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := call(map[string]any{"path": "strings", "synopsis": true, "show_metadata": false})
+	for _, want := range []string{
+		"package strings // import \"strings\"",
+		"Package strings implements simple functions to manipulate UTF-8 encoded strings.",
+		"func Cut(s, sep string) (before, after string, found bool)\n    Cut slices s around the first instance of sep, returning the text before and after sep.\n",
+		"type Builder struct { ... }\n    A Builder is used to efficiently build a string using Builder.Write methods.\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("package synopsis missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Builder.Write methods. It minimizes") {
+		t.Errorf("synopsis should keep only the first sentence:\n%s", text)
+	}
+
+	text = call(map[string]any{"path": "strings", "target": "Builder", "synopsis": true, "show_metadata": false})
+	if !strings.HasPrefix(text, "type Builder struct { ... }\n") || !strings.Contains(text, "func (b *Builder) Len() int\n    Len returns the number of accumulated bytes; b.Len() == len(b.String()).\n") {
+		t.Errorf("type synopsis should list its methods:\n%s", text)
+	}
+}
+
+func TestHandleGetDocResponsePreamble(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	for _, showMetadata := range []bool{true, false} {
+		gs := newGodocServer(withResponsePreamble("godoc-mcp {version}"))
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = map[string]any{"path": "io", "target": "EOF", "show_metadata": showMetadata}
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+		}
+		text := result.Content[0].(mcp.TextContent).Text
+		if want := "godoc-mcp " + version + "\n"; !strings.HasPrefix(text, want) {
+			t.Errorf("show_metadata=%v: expected preamble %q, got:\n%s", showMetadata, want, text)
+		}
+	}
+}
+
+func TestHandleGetDocPrefixSuggestions(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer()
+	call := func(target string) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = map[string]any{"path": "io", "target": target}
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		return result
+	}
+
+	result := call("ReadA")
+	if result.IsError {
+		t.Fatalf("expected suggestions, got error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"No exact match for ReadA in io; did you mean:",
+		"func ReadAll(r Reader) ([]byte, error)",
+		"func ReadAtLeast(r Reader, buf []byte, min int) (n int, err error)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+
+	if text := call("Reader.Re").Content[0].(mcp.TextContent).Text; !strings.Contains(text, "Reader.Read(p []byte) (n int, err error)") {
+		t.Errorf("expected method suggestion, got:\n%s", text)
+	}
+
+	result = call("Zzz")
+	if !result.IsError {
+		t.Fatal("expected error when nothing matches the prefix")
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "symbol not found") {
+		t.Errorf("unexpected error: %s", text)
+	}
+}
+
+func TestHandleGetDocSymlinkedWorkingDir(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":     "module example.com/linked\n\ngo 1.21\n",
+		"linked.go":  "// Package linked is reached through a symlink.\npackage linked\n",
+		"sub/sub.go": "package sub\n\n// Hello greets.\nfunc Hello() string { return \"hi\" }\n",
+	})
+	link := filepath.Join(t.TempDir(), "workspace")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	if text := call(map[string]any{"path": "./sub", "target": "Hello", "working_dir": link}); !strings.Contains(text, "func Hello() string") {
+		t.Errorf("relative path under a symlinked working_dir:\n%s", text)
+	}
+	if text := call(map[string]any{"path": link, "working_dir": link}); !strings.Contains(text, "Package linked is reached through a symlink.") {
+		t.Errorf("absolute symlinked path:\n%s", text)
+	}
+
+	_, real, err := canonicalPaths(".", link)
+	if err != nil {
+		t.Fatalf("canonicalPaths: %v", err)
+	}
+	if want, _ := filepath.EvalSymlinks(dir); real != want {
+		t.Errorf("canonicalPaths working dir = %q, want %q", real, want)
+	}
+}
+
+func TestFormatGoDocErrorBuildFailure(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"syntax error", "doc: /tmp/bf/bf.go:3:9: expected ')', found '{'\n", "/tmp/bf/bf.go:3:9: expected ')', found '{'"},
+		{"mixed packages", "doc: found packages bf (bf.go) and other (o.go) in /tmp/bf\n", "found packages bf (bf.go) and other (o.go)"},
+		{"bad go.mod", "go: errors parsing go.mod:\ngo.mod:4: usage: require module/path v1.2.3\n", "go.mod:4: usage: require module/path v1.2.3"},
+		{"missing sum", "go: missing go.sum entry for module providing package example.com/x\n", "missing go.sum entry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := formatGoDocError(tt.output, exitErr)
+			if !errors.Is(err, errModuleBuild) {
+				t.Fatalf("expected errModuleBuild, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q should quote %q", err, tt.want)
+			}
+			if strings.Contains(err.Error(), "package not found") {
+				t.Errorf("build failure should not be reported as missing package: %v", err)
+			}
+		})
+	}
+
+	for _, output := range []string{
+		"doc: no such package example.com/x\n",
+		"go: example.com/dep@v9.9.9: invalid version: unknown revision v9.9.9\n",
+		"go: example.com/gone@v1.0.0: invalid version: git ls-remote -q origin: exit status 128:\n\tremote: Repository not found.\n",
+	} {
+		if err := formatGoDocError(output, exitErr); errors.Is(err, errModuleBuild) {
+			t.Errorf("missing package or version misclassified as build failure: %v", err)
+		}
+	}
+
+	// An invalid version in go.mod itself is a broken module.
+	err := formatGoDocError("go: errors parsing go.mod:\ngo.mod:5: require example.com/x: version \"bad\" invalid: must be of the form v1.2.3\n", exitErr)
+	if !errors.Is(err, errModuleBuild) {
+		t.Errorf("invalid version in go.mod not classified as build failure: %v", err)
+	}
+}
+
+func TestFormatGoDocErrorToolchain(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	err := formatGoDocError("go: go.mod requires go >= 1.30 (running go 1.27.1; GOTOOLCHAIN=local)\n", exitErr)
+	if !errors.Is(err, errToolchain) || errors.Is(err, errModuleBuild) {
+		t.Fatalf("expected errToolchain only, got %v", err)
+	}
+	for _, want := range []string{"requires go 1.30", "installed toolchain is go 1.27.1", "GOTOOLCHAIN=local", "download go1.30"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should mention %q", err, want)
+		}
+	}
+
+	err = formatGoDocError("go: downloading go1.30.0 (linux/amd64)\ngo: download go1.30.0 for linux/amd64: toolchain not available\n", exitErr)
+	if !errors.Is(err, errToolchain) || !strings.Contains(err.Error(), "go1.30.0 could not be downloaded") {
+		t.Errorf("unexpected download failure error: %v", err)
+	}
+}
+
+func TestHandleGetDocInvalidModMode(t *testing.T) {
+	gs := newGodocServer()
+	for _, args := range []map[string]any{
+		{"path": "io", "mod_mode": "bogus", "working_dir": t.TempDir()},
+		{"path": "io", "mod_mode": "mod"},
+	} {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
+
+func TestHandleGetDocInvalidTimeout(t *testing.T) {
+	gs := newGodocServer()
+	for _, timeout := range []int{-1, 301} {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = map[string]any{"path": "io", "timeout_seconds": timeout}
+
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("expected error for timeout_seconds=%d", timeout)
+		}
+	}
+}
+
+func TestHandleGetDocQualifiedPath(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_doc"
+	req.Params.Arguments = map[string]any{
+		"path": "net/http.Client.Do",
+	}
+
+	result, err := gs.handleGetDoc(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleGetDoc returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "func (c *Client) Do(req *Request) (*Response, error)") {
+		t.Errorf("expected Client.Do documentation, got:\n%s", text)
+	}
+}
+
+func TestHandleGetDocBadFlag(t *testing.T) {
+	gs := newGodocServer()
+
 	req := mcp.CallToolRequest{}
 	req.Params.Name = "get_doc"
 	req.Params.Arguments = map[string]any{
@@ -613,6 +1696,31 @@ func TestHandleListPackagesMissingPath(t *testing.T) {
 
 // Test helpers
 
+// testSession is a minimal client session identified only by its ID.
+type testSession string
+
+func (s testSession) Initialize()                                         {}
+func (s testSession) Initialized() bool                                   { return true }
+func (s testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s testSession) SessionID() string                                   { return string(s) }
+
+// writeModule creates a temporary directory populated with the given files,
+// keyed by slash-separated relative path, and returns its path.
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
 func makeLines(n int) []string {
 	lines := make([]string, n)
 	for i := range lines {
@@ -627,3 +1735,77 @@ func firstLine(s string) string {
 	}
 	return s
 }
+
+// fakeGo puts a shell script named go first on PATH for the rest of the test.
+func fakeGo(t *testing.T, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go command needs a POSIX shell")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go"), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestRunGoDocStderr(t *testing.T) {
+	fakeGo(t, `echo "package demo // import \"demo\""
+echo "warning: GOPATH set to GOROOT has no effect" >&2
+`)
+	ctx := context.Background()
+
+	doc, err := newGodocServer().runGoDoc(ctx, "", "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(doc, "warning:") {
+		t.Errorf("stderr leaked into the documentation: %q", doc)
+	}
+
+	doc, err = newGodocServer(withIncludeStderr(true)).runGoDoc(ctx, "", "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(doc, "package demo") || !strings.Contains(doc, "warning: GOPATH set to GOROOT") {
+		t.Errorf("expected documentation followed by the warning: %q", doc)
+	}
+}
+
+func TestRunGoDocNoCache(t *testing.T) {
+	count := filepath.Join(t.TempDir(), "count")
+	fakeGo(t, `echo x >> "`+count+`"
+echo "package demo // import \"demo\""
+wc -l < "`+count+`"
+`)
+	gs := newGodocServer()
+	ctx := context.Background()
+
+	first, err := gs.runGoDoc(ctx, "", "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached, _ := gs.runGoDoc(ctx, "", "demo"); cached != first {
+		t.Errorf("expected a cache hit, got %q then %q", first, cached)
+	}
+	fresh, err := gs.runGoDoc(withNoCache(ctx), "", "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh == first {
+		t.Errorf("no_cache lookup returned the cached %q", fresh)
+	}
+	if refreshed, _ := gs.runGoDoc(ctx, "", "demo"); refreshed != fresh {
+		t.Errorf("expected the fresh result to replace the cached one, got %q", refreshed)
+	}
+}
+
+func TestRunGoDocStderrClassifiesErrors(t *testing.T) {
+	fakeGo(t, `echo "doc: no symbol Nope in package demo" >&2
+exit 1
+`)
+	_, err := newGodocServer().runGoDoc(context.Background(), "", "demo", "Nope")
+	if !errors.Is(err, errSymbolNotFound) {
+		t.Errorf("expected errSymbolNotFound, got %v", err)
+	}
+}
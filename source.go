@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxReadLines caps the number of lines a single read_lines call returns.
+const maxReadLines = 2000
+
+const readLinesDescription = `Read a specific line range from a Go source file.
+Use this when you already know the file and lines you need (e.g., from a stack
+trace or compiler error) instead of reading the whole file. Lines are returned
+with their line numbers.
+
+Only files inside GOROOT, the module cache, or the module given by working_dir
+can be read.`
+
+func (gs *godocServer) handleReadLines(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	file, err := request.RequireString("file")
+	if err != nil {
+		return mcp.NewToolResultError("file argument is required"), nil
+	}
+
+	startLine := request.GetInt("start_line", 1)
+	endLine := request.GetInt("end_line", 0)
+	workingDir := request.GetString("working_dir", "")
+
+	if workingDir != "" {
+		info, err := os.Stat(workingDir)
+		if err != nil || !info.IsDir() {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid working directory: %s", workingDir)), nil
+		}
+	}
+	if err := gs.checkPathsAllowed("", workingDir); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// Only the module containing workingDir is readable, never the directory
+	// itself, which could be "/" or any other part of the host.
+	var moduleRoot string
+	if workingDir != "" {
+		if moduleRoot, err = findModuleRoot(workingDir); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("working_dir must be inside a Go module: %v", err)), nil
+		}
+		if err := gs.checkPathsAllowed("", moduleRoot); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	if startLine < 1 {
+		startLine = 1
+	}
+	if endLine == 0 {
+		endLine = startLine + maxReadLines - 1
+	}
+	if endLine < startLine {
+		return mcp.NewToolResultError(fmt.Sprintf("end_line %d is before start_line %d", endLine, startLine)), nil
+	}
+	if endLine-startLine+1 > maxReadLines {
+		return mcp.NewToolResultError(fmt.Sprintf("line range too large (max %d lines)", maxReadLines)), nil
+	}
+
+	if !filepath.IsAbs(file) {
+		if workingDir == "" {
+			return mcp.NewToolResultError("working_dir is required for relative file paths"), nil
+		}
+		file = filepath.Join(workingDir, file)
+	}
+
+	roots, err := gs.readableRoots(ctx, moduleRoot)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resolved, err := resolveWithinRoots(file, roots)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	text, err := readLines(resolved, startLine, endLine)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// readableRoots returns the directories read_lines may serve files from:
+// GOROOT, the module cache, and moduleRoot when provided.
+func (gs *godocServer) readableRoots(ctx context.Context, moduleRoot string) ([]string, error) {
+	envCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	out, err := gs.output(envCtx, gs.goCommand(envCtx, "", "env", "GOROOT", "GOMODCACHE"))
+	if err != nil {
+		return nil, fmt.Errorf("go env failed: %w", err)
+	}
+
+	var roots []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			roots = append(roots, line)
+		}
+	}
+	if moduleRoot != "" {
+		roots = append(roots, moduleRoot)
+	}
+	return roots, nil
+}
+
+// resolveWithinRoots canonicalizes file and verifies it lies inside one of
+// roots. Symlinks are resolved on both sides so they cannot be used to escape.
+func resolveWithinRoots(file string, roots []string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return "", fmt.Errorf("cannot access %s: %w", file, err)
+	}
+
+	for _, root := range roots {
+		realRoot, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		if withinDir(realRoot, resolved) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%s is outside GOROOT, the module cache, and the working directory's module", file)
+}
+
+// withinDir reports whether path is dir or lies below it. Both must be
+// clean absolute paths.
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// readLines returns lines start through end (1-based, inclusive) of file,
+// each prefixed with its line number.
+func readLines(file string, start, end int) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	n, last := 0, 0
+	for scanner.Scan() {
+		n++
+		if n < start {
+			continue
+		}
+		if n > end {
+			break
+		}
+		fmt.Fprintf(&b, "%6d\t%s\n", n, scanner.Text())
+		last = n
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if last == 0 {
+		return "", fmt.Errorf("start_line %d is past the end of %s (%d lines)", start, file, n)
+	}
+
+	header := fmt.Sprintf("%s:%d-%d\n\n", file, start, last)
+	return header + b.String(), nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestReadLines(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f.go")
+	os.WriteFile(file, []byte("line1\nline2\nline3\nline4\n"), 0644)
+
+	t.Run("middle range", func(t *testing.T) {
+		got, err := readLines(file, 2, 3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(got, "     2\tline2\n     3\tline3\n") {
+			t.Errorf("unexpected output:\n%s", got)
+		}
+		if strings.Contains(got, "line1") || strings.Contains(got, "line4") {
+			t.Errorf("output includes lines outside range:\n%s", got)
+		}
+	})
+
+	t.Run("end past EOF", func(t *testing.T) {
+		got, err := readLines(file, 3, 100)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(got, ":3-4") {
+			t.Errorf("expected header to report actual range, got: %s", firstLine(got))
+		}
+	})
+
+	t.Run("start past EOF", func(t *testing.T) {
+		if _, err := readLines(file, 10, 20); err == nil {
+			t.Fatal("expected error for start past end of file")
+		}
+	})
+}
+
+func TestResolveWithinRoots(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	inFile := filepath.Join(root, "in.go")
+	outFile := filepath.Join(outside, "out.go")
+	os.WriteFile(inFile, []byte("package x\n"), 0644)
+	os.WriteFile(outFile, []byte("package y\n"), 0644)
+
+	if _, err := resolveWithinRoots(inFile, []string{root}); err != nil {
+		t.Errorf("file inside root rejected: %v", err)
+	}
+	if _, err := resolveWithinRoots(outFile, []string{root}); err == nil {
+		t.Error("file outside root accepted")
+	}
+	if _, err := resolveWithinRoots(filepath.Join(root, "..", filepath.Base(outside), "out.go"), []string{root}); err == nil {
+		t.Error("dot-dot escape accepted")
+	}
+
+	link := filepath.Join(root, "link.go")
+	if err := os.Symlink(outFile, link); err == nil {
+		if _, err := resolveWithinRoots(link, []string{root}); err == nil {
+			t.Error("symlink escape accepted")
+		}
+	}
+}
+
+func TestHandleReadLinesGoroot(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	out, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		t.Fatalf("go env: %v", err)
+	}
+	file := filepath.Join(strings.TrimSpace(string(out)), "src", "io", "io.go")
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "read_lines"
+	req.Params.Arguments = map[string]any{
+		"file":       file,
+		"start_line": 1,
+		"end_line":   10,
+	}
+
+	result, err := gs.handleReadLines(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleReadLines returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleReadLines returned tool error: %+v", result.Content)
+	}
+}
+
+func TestHandleReadLinesOutsideRoots(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	file := filepath.Join(t.TempDir(), "secret.go")
+	os.WriteFile(file, []byte("package secret\n"), 0644)
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "read_lines"
+	req.Params.Arguments = map[string]any{
+		"file": file,
+	}
+
+	result, err := gs.handleReadLines(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleReadLines returned protocol error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected tool error for file outside allowed roots")
+	}
+}
+
+func TestHandleReadLinesWorkingDir(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":     "module example.com/mod\n\ngo 1.21\n",
+		"sub/sub.go": "package sub\n",
+	})
+	plain := t.TempDir()
+	os.WriteFile(filepath.Join(plain, "secret.go"), []byte("package secret\n"), 0644)
+
+	gs := newGodocServer()
+	call := func(file, workingDir string) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "read_lines"
+		req.Params.Arguments = map[string]any{"file": file, "working_dir": workingDir}
+		result, err := gs.handleReadLines(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleReadLines returned protocol error: %v", err)
+		}
+		return result
+	}
+
+	// Any directory of the module makes the whole module readable.
+	if result := call(filepath.Join(dir, "go.mod"), filepath.Join(dir, "sub")); result.IsError {
+		t.Errorf("file of the working directory's module refused: %+v", result.Content)
+	}
+
+	for _, tt := range []struct{ name, file, workingDir string }{
+		{"root", "/etc/passwd", "/"},
+		{"non-module directory", filepath.Join(plain, "secret.go"), plain},
+	} {
+		result := call(tt.file, tt.workingDir)
+		if !result.IsError {
+			t.Errorf("%s: read %s with working_dir %s, want it refused", tt.name, tt.file, tt.workingDir)
+			continue
+		}
+		if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "must be inside a Go module") {
+			t.Errorf("%s: unexpected error %q", tt.name, text)
+		}
+	}
+}
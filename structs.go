@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const describeStructDescription = `Describe the fields of a Go struct type.
+Returns each exported field with its type, its doc and line comments, and its
+struct tags split by key (json, xml, yaml, db, ...), parsed from the source.
+Use this for data-modeling and serialization questions; go doc omits field
+comments and tag details in some cases. With format "json_schema", the struct
+is instead described as a JSON Schema of the object encoding/json produces:
+property names from json tags, fields without omitempty or omitzero listed as
+required, embedded structs flattened, and named types of the package under
+$defs (with their constants as enum values). Use that to build JSON that
+unmarshals into the type.`
+
+func (gs *godocServer) handleDescribeStruct(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	target, err := request.RequireString("target")
+	if err != nil {
+		return mcp.NewToolResultError("target argument is required"), nil
+	}
+	format := request.GetString("format", "text")
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// go/doc strips unexported fields from the AST, so read the files directly.
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	ts, docText := findTypeSpec(lp.files, target)
+	if ts == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("type %s not found in %s", target, pkgPath)), nil
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is not a struct type", target)), nil
+	}
+
+	switch format {
+	case "text":
+	case "json_schema":
+		out, err := json.MarshalIndent(structSchema(lp.files, ts.Name.Name, docText, st), "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to encode schema: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(out) + "\n"), nil
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid format %q (use text or json_schema)", format)), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s // import %q\n\n", lp.files[0].Name.Name, pkgPath)
+	fmt.Fprintf(&b, "type %s%s struct\n", ts.Name.Name, typeParams(lp.fset, ts))
+	writeIndented(&b, docText)
+	b.WriteString("\nFIELDS\n\n")
+
+	exported, unexported := 0, 0
+	for _, field := range st.Fields.List {
+		typ := nodeString(lp.fset, field.Type)
+		names := make([]string, 0, len(field.Names))
+		for _, id := range field.Names {
+			names = append(names, id.Name)
+		}
+		embedded := len(names) == 0
+		if embedded {
+			names = []string{embeddedName(field.Type)}
+		}
+
+		for _, name := range names {
+			if !token.IsExported(name) {
+				unexported++
+				continue
+			}
+			exported++
+			if embedded {
+				fmt.Fprintf(&b, "%s  // embedded\n", typ)
+			} else {
+				fmt.Fprintf(&b, "%s %s\n", name, typ)
+			}
+			if field.Tag != nil {
+				tag, err := strconv.Unquote(field.Tag.Value)
+				if err != nil {
+					tag = field.Tag.Value
+				}
+				for _, kv := range structTags(tag) {
+					fmt.Fprintf(&b, "    tag %s: %q\n", kv[0], kv[1])
+				}
+			}
+			writeIndented(&b, field.Doc.Text())
+			writeIndented(&b, field.Comment.Text())
+			b.WriteString("\n")
+		}
+	}
+
+	if exported == 0 {
+		b.WriteString("no exported fields\n")
+	}
+	if unexported > 0 {
+		fmt.Fprintf(&b, "(%d unexported fields not shown)\n", unexported)
+	}
+	return mcp.NewToolResultText(strings.TrimRight(b.String(), "\n") + "\n"), nil
+}
+
+// findTypeSpec returns the declaration of the type named name in files and
+// its doc comment, which may be attached to the enclosing type ( ... ) group
+// when the group declares only this type.
+func findTypeSpec(files []*ast.File, name string) (*ast.TypeSpec, string) {
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.Name.Name != name {
+					continue
+				}
+				docText := ts.Doc.Text()
+				if docText == "" && len(gd.Specs) == 1 {
+					docText = gd.Doc.Text()
+				}
+				return ts, docText
+			}
+		}
+	}
+	return nil, ""
+}
+
+// typeParams renders the type parameter list of ts, or "" if it has none.
+func typeParams(fset *token.FileSet, ts *ast.TypeSpec) string {
+	if ts.TypeParams == nil {
+		return ""
+	}
+	params := make([]string, 0, len(ts.TypeParams.List))
+	for _, field := range ts.TypeParams.List {
+		names := make([]string, 0, len(field.Names))
+		for _, id := range field.Names {
+			names = append(names, id.Name)
+		}
+		params = append(params, strings.Join(names, ", ")+" "+nodeString(fset, field.Type))
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+// embeddedName returns the field name of an embedded field of type expr:
+// the type name without package qualifier, pointer, or type arguments.
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	}
+	return ""
+}
+
+// structTags splits a struct tag in the conventional key:"value" format into
+// its key/value pairs, in order. Parsing stops at the first malformed pair,
+// as reflect.StructTag.Lookup does.
+func structTags(tag string) [][2]string {
+	var tags [][2]string
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " ")
+		i := strings.Index(tag, ":\"")
+		if i <= 0 || strings.ContainsAny(tag[:i], " \"") {
+			break
+		}
+		key := tag[:i]
+		rest := tag[i+1:]
+		// Find the closing quote, skipping escaped characters.
+		j := 1
+		for j < len(rest) && rest[j] != '"' {
+			if rest[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(rest) {
+			break
+		}
+		value, err := strconv.Unquote(rest[:j+1])
+		if err != nil {
+			break
+		}
+		tags = append(tags, [2]string{key, value})
+		tag = rest[j+1:]
+	}
+	return tags
+}
+
+// writeIndented writes each line of text indented by four spaces.
+func writeIndented(b *strings.Builder, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if line != "" {
+			b.WriteString("    " + line)
+		}
+		b.WriteString("\n")
+	}
+}
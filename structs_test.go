@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestStructTags(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want [][2]string
+	}{
+		{`json:"id,omitempty" db:"user_id"`, [][2]string{{"json", "id,omitempty"}, {"db", "user_id"}}},
+		{`validate:"regexp=^\"a\"$"`, [][2]string{{"validate", `regexp=^"a"$`}}},
+		{`json:"-"  xml:"name,attr"`, [][2]string{{"json", "-"}, {"xml", "name,attr"}}},
+		{`not a tag`, nil},
+		{``, nil},
+	}
+	for _, tt := range tests {
+		if got := structTags(tt.tag); !slices.Equal(got, tt.want) {
+			t.Errorf("structTags(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestHandleDescribeStruct(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/model\n\ngo 1.21\n",
+		"model.go": `package model
+
+import "time"
+
+type Base struct{ Created time.Time }
+
+// User is a registered account.
+type User struct {
+	Base
+
+	// ID is the primary key.
+	ID    int64  ` + "`json:\"id\" db:\"user_id\"`" + `
+	Email string ` + "`json:\"email,omitempty\"`" + ` // lower-cased
+	hash  []byte
+}
+
+type Name string
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(target string) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "describe_struct"
+		req.Params.Arguments = map[string]any{"path": ".", "target": target, "working_dir": dir}
+		result, err := gs.handleDescribeStruct(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleDescribeStruct returned protocol error: %v", err)
+		}
+		return result
+	}
+
+	result := call("User")
+	if result.IsError {
+		t.Fatalf("handleDescribeStruct returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"type User struct\n    User is a registered account.\n",
+		"Base  // embedded\n",
+		"ID int64\n    tag json: \"id\"\n    tag db: \"user_id\"\n    ID is the primary key.\n",
+		"Email string\n    tag json: \"email,omitempty\"\n    lower-cased\n",
+		"(1 unexported fields not shown)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "hash") {
+		t.Errorf("unexported field should be omitted:\n%s", text)
+	}
+
+	if result := call("Name"); !result.IsError {
+		t.Error("expected error for a non-struct type")
+	}
+	if result := call("Missing"); !result.IsError {
+		t.Error("expected error for a missing type")
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/doc/comment"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const docStructureDescription = `Get the doc comment of a Go package or symbol as structured elements
+instead of go doc's plain text. The comment is parsed with the Go 1.19 doc
+comment syntax into an ordered list of blocks: headings, paragraphs, lists
+(ordered or not, one entry per item), and code blocks, with the doc links
+([io.Reader], [Client.Do]) and URLs in each block resolved to their package,
+symbol, or address. Use this to render documentation faithfully or to jump
+to a section, rather than parsing go doc's text approximation. With format
+set to text, markdown, rst (reStructuredText), or adoc (AsciiDoc), the same
+structure is also rendered in that markup.`
+
+// structuredDoc is the doc_structure result for a package or symbol.
+type structuredDoc struct {
+	Package     string     `json:"package"`
+	Symbol      string     `json:"symbol,omitempty"`
+	Declaration string     `json:"declaration,omitempty"`
+	Blocks      []docBlock `json:"blocks"`
+}
+
+// docBlock is one block of a parsed doc comment.
+type docBlock struct {
+	Kind    string    `json:"kind"` // "heading", "paragraph", "list", or "code"
+	Text    string    `json:"text,omitempty"`
+	Ordered bool      `json:"ordered,omitempty"`
+	Items   []string  `json:"items,omitempty"`
+	Links   []docLink `json:"links,omitempty"`
+}
+
+// docLink is a link inside a block: a URL, or a doc link to a package or
+// one of its symbols.
+type docLink struct {
+	Text    string `json:"text"`
+	URL     string `json:"url,omitempty"`
+	Package string `json:"package,omitempty"`
+	Symbol  string `json:"symbol,omitempty"`
+}
+
+func (gs *godocServer) handleDocStructure(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	target := request.GetString("target", "")
+	format := request.GetString("format", "json")
+	formatter, ok := docFormatters[format]
+	if !ok && format != "json" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid format %q: must be one of %s", format, strings.Join(docFormats, ", "))), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	sd := structuredDoc{Package: pkgPath, Symbol: target}
+	text := dp.Doc
+	if target != "" {
+		sym, ok := findSymbol(dp, target)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("symbol %s not found in %s", target, pkgPath)), nil
+		}
+		sd.Declaration = symbolSignature(lp.fset, sym)
+		text = sym.doc
+	}
+	parsed := dp.Parser().Parse(text)
+	sd.Blocks = docBlocks(parsed, pkgPath)
+	if formatter != nil {
+		return mcp.NewToolResultStructured(sd, renderDoc(formatter, sd, parsed)), nil
+	}
+
+	out, err := json.MarshalIndent(sd, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode documentation: %v", err)), nil
+	}
+	return mcp.NewToolResultStructured(sd, string(out)), nil
+}
+
+// docBlocks flattens a parsed doc comment into blocks. Doc links without an
+// import path refer to importPath, the package the comment belongs to.
+func docBlocks(d *comment.Doc, importPath string) []docBlock {
+	blocks := make([]docBlock, 0, len(d.Content))
+	for _, blk := range d.Content {
+		var b docBlock
+		switch blk := blk.(type) {
+		case *comment.Heading:
+			b.Kind = "heading"
+			b.Text = inlineText(blk.Text, importPath, &b.Links)
+		case *comment.Paragraph:
+			b.Kind = "paragraph"
+			b.Text = inlineText(blk.Text, importPath, &b.Links)
+		case *comment.List:
+			b.Kind = "list"
+			b.Ordered = len(blk.Items) > 0 && blk.Items[0].Number != ""
+			for _, item := range blk.Items {
+				var paras []string
+				for _, c := range item.Content {
+					if p, ok := c.(*comment.Paragraph); ok {
+						paras = append(paras, inlineText(p.Text, importPath, &b.Links))
+					}
+				}
+				b.Items = append(b.Items, strings.Join(paras, "\n"))
+			}
+		case *comment.Code:
+			b.Kind = "code"
+			b.Text = strings.TrimSuffix(blk.Text, "\n")
+		default:
+			continue
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
+
+// inlineText renders the text of a block as plain text, with soft line
+// breaks joined by spaces, appending each link it contains to links.
+func inlineText(texts []comment.Text, importPath string, links *[]docLink) string {
+	var b strings.Builder
+	for _, t := range texts {
+		switch t := t.(type) {
+		case comment.Plain:
+			b.WriteString(string(t))
+		case comment.Italic:
+			b.WriteString(string(t))
+		case *comment.Link:
+			text := inlineText(t.Text, importPath, nil)
+			if links != nil {
+				*links = append(*links, docLink{Text: text, URL: t.URL})
+			}
+			b.WriteString(text)
+		case *comment.DocLink:
+			text := inlineText(t.Text, importPath, nil)
+			if links != nil {
+				l := docLink{Text: text, Package: t.ImportPath, Symbol: t.Name}
+				if l.Package == "" {
+					l.Package = importPath
+				}
+				if t.Recv != "" {
+					l.Symbol = t.Recv + "." + t.Name
+				}
+				*links = append(*links, l)
+			}
+			b.WriteString(text)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleDocStructure(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/rich\n\ngo 1.21\n",
+		"rich.go": `// Package rich has rich docs.
+//
+// # Usage
+//
+// Call [New], then read from it as an [io.Reader].
+// See https://example.com/rich for more.
+//
+//  1. Open it.
+//  2. Read it.
+//
+// For example:
+//
+//	r := rich.New()
+//	io.Copy(os.Stdout, r)
+package rich
+
+import "io"
+
+// Reader reads.
+type Reader struct{ io.Reader }
+
+// New returns a [Reader].
+func New() *Reader { return nil }
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(args map[string]any) structuredDoc {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "doc_structure"
+		req.Params.Arguments = args
+		result, err := gs.handleDocStructure(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleDocStructure returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleDocStructure returned tool error: %+v", result.Content)
+		}
+		var sd structuredDoc
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &sd); err != nil {
+			t.Fatalf("text fallback is not JSON: %v", err)
+		}
+		if !reflect.DeepEqual(result.StructuredContent, sd) {
+			t.Errorf("structured content %+v does not match text %+v", result.StructuredContent, sd)
+		}
+		return sd
+	}
+
+	sd := call(map[string]any{"path": ".", "working_dir": dir})
+	want := []docBlock{
+		{Kind: "paragraph", Text: "Package rich has rich docs."},
+		{Kind: "heading", Text: "Usage"},
+		{Kind: "paragraph", Text: "Call New, then read from it as an io.Reader. See https://example.com/rich for more.", Links: []docLink{
+			{Text: "New", Package: "example.com/rich", Symbol: "New"},
+			{Text: "io.Reader", Package: "io", Symbol: "Reader"},
+			{Text: "https://example.com/rich", URL: "https://example.com/rich"},
+		}},
+		{Kind: "list", Ordered: true, Items: []string{"Open it.", "Read it."}},
+		{Kind: "paragraph", Text: "For example:"},
+		{Kind: "code", Text: "r := rich.New()\nio.Copy(os.Stdout, r)"},
+	}
+	if !reflect.DeepEqual(sd.Blocks, want) {
+		got, _ := json.MarshalIndent(sd.Blocks, "", "  ")
+		t.Errorf("blocks:\n%s", got)
+	}
+
+	sd = call(map[string]any{"path": ".", "target": "New", "working_dir": dir})
+	if sd.Symbol != "New" || sd.Declaration != "func New() *Reader" {
+		t.Errorf("unexpected symbol result: %+v", sd)
+	}
+	if len(sd.Blocks) != 1 || len(sd.Blocks[0].Links) != 1 || sd.Blocks[0].Links[0].Symbol != "Reader" {
+		t.Errorf("unexpected symbol blocks: %+v", sd.Blocks)
+	}
+}
@@ -0,0 +1,686 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/doc"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const whatisDescription = `Quick one-line lookup of a Go symbol.
+Returns only the symbol's signature and the first sentence of its doc comment.
+This is the cheapest way to confirm what a function, type, method, constant, or
+variable is before deciding whether to fetch its full documentation with get_doc.`
+
+const listSymbolsDescription = `List every exported symbol of a Go package with stability annotations.
+Each symbol is shown with its one-line signature and flags for:
+- deprecated: the doc comment has a "Deprecated:" paragraph
+- experimental: the doc comment says so, or the file is behind a GOEXPERIMENT build tag
+- since: the doc comment records when it was added (e.g. "Added in v1.4")
+- generated: the symbol is declared in a "Code generated ... DO NOT EDIT." file
+- may panic: a function or method documented to panic, or named MustXxx
+Set exclude_generated to leave generated symbols out and focus on the
+hand-written API. Use this to judge how safe an API is to depend on.`
+
+var (
+	experimentalPattern = regexp.MustCompile(`(?m)^(?:Experimental\b|EXPERIMENTAL\b|This (?:API|package|type|function) is experimental)`)
+	sincePattern        = regexp.MustCompile(`(?i)\b(?:added|available|new) (?:in|since) (go ?\d+(?:\.\d+)*|v\d+(?:\.\d+)*)`)
+)
+
+// docSymbol is a documented package-level declaration or method.
+type docSymbol struct {
+	name string // "Name", or "Type.Method" for methods
+	kind string // "func", "method", "type", "const", or "var"
+	doc  string
+	decl ast.Decl
+
+	// inInterface marks a method of an interface type, whose decl is
+	// synthesized by interfaceMethod.
+	inInterface bool
+}
+
+func (gs *godocServer) handleWhatis(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	target, err := request.RequireString("target")
+	if err != nil {
+		return mcp.NewToolResultError("target argument is required"), nil
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	sym, ok := findSymbol(dp, target)
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("symbol %s not found in %s", target, pkgPath)), nil
+	}
+
+	text := symbolSignature(lp.fset, sym)
+	if summary := dp.Synopsis(sym.doc); summary != "" {
+		text += "\n    " + summary
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// forceParseDoc documents a package whose files are all excluded by build
+// constraints by parsing its source directly. With a target, only that
+// symbol is shown; otherwise every exported symbol is listed with the file
+// that declares it.
+func (gs *godocServer) forceParseDoc(ctx context.Context, workingDir, importPath, target string) (string, error) {
+	dir, err := gs.packageDir(ctx, workingDir, importPath)
+	if err != nil {
+		return "", err
+	}
+	lp, err := parsePackageIgnoringConstraints(dir)
+	if err != nil {
+		return "", err
+	}
+	lp.path = importPath
+	lp.workingDir = workingDir
+
+	doc, err := parsedDoc(lp, target)
+	if err != nil {
+		return "", fmt.Errorf("%w (build constraints bypassed)", err)
+	}
+	return "WARNING: build constraints were bypassed. The symbols below come from files that\n" +
+		"are not built for the current platform; some may be platform-specific or declared\n" +
+		"more than once.\n\n" + doc, nil
+}
+
+// parsedDoc renders go doc-style documentation for lp from its parsed
+// source, for packages the go command cannot document itself. With a target,
+// only that symbol is shown; otherwise every exported symbol is listed with
+// the file that declares it.
+func parsedDoc(lp *loadedPackage, target string) (string, error) {
+	dp, err := lp.docPackage()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s // import %q\n\n", dp.Name, lp.path)
+
+	if target != "" {
+		sym, ok := findSymbol(dp, target)
+		if !ok {
+			return "", fmt.Errorf("symbol %s not found in %s", target, lp.path)
+		}
+		b.WriteString(symbolSignature(lp.fset, sym) + "\n")
+		for _, line := range strings.Split(strings.TrimRight(sym.doc, "\n"), "\n") {
+			b.WriteString("    " + line + "\n")
+		}
+		return b.String(), nil
+	}
+
+	if dp.Doc != "" {
+		b.WriteString(dp.Doc + "\n")
+	}
+	seen := make(map[string]bool)
+	for _, sym := range packageSymbols(dp) {
+		sig := symbolSignature(lp.fset, sym)
+		if !ast.IsExported(lastName(sym.name)) || seen[sig] {
+			continue
+		}
+		seen[sig] = true
+		file := filepath.Base(lp.fset.Position(sym.decl.Pos()).Filename)
+		fmt.Fprintf(&b, "%s  // %s\n", sig, file)
+	}
+	return b.String(), nil
+}
+
+// signatureDoc renders only the declaration of target in lp, with no doc
+// prose: the signature of a function, method, constant, or variable, or the
+// definition of a type followed by the signatures of its constructors and
+// methods.
+func signatureDoc(lp *loadedPackage, target string) (string, error) {
+	dp, err := lp.docPackage()
+	if err != nil {
+		return "", err
+	}
+	sym, ok := findSymbol(dp, target)
+	if !ok {
+		return "", fmt.Errorf("symbol %s not found in %s", target, lp.path)
+	}
+	if sym.kind != "type" {
+		return symbolSignature(lp.fset, sym) + "\n", nil
+	}
+
+	var b strings.Builder
+	for _, t := range dp.Types {
+		if t.Name != target {
+			continue
+		}
+		for _, spec := range t.Decl.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == target {
+				writeTypeDecl(&b, lp.fset, ts, "")
+			}
+		}
+		for _, f := range append(append([]*doc.Func{}, t.Funcs...), t.Methods...) {
+			b.WriteString(symbolSignature(lp.fset, docSymbol{name: f.Name, kind: "func", decl: f.Decl}) + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n", nil
+}
+
+// maxSuggestions caps how many prefix matches a missed symbol lookup offers;
+// beyond that the guess is too vague to be useful.
+const maxSuggestions = 8
+
+// prefixSuggestions lists the signatures of the exported symbols of
+// importPath whose names start with target, for a lookup of target that
+// found no exact match. It returns "" when there are no such symbols or too
+// many to be helpful.
+func (gs *godocServer) prefixSuggestions(ctx context.Context, workingDir, importPath, target string) string {
+	lp, err := gs.loadPackage(ctx, workingDir, importPath)
+	if err != nil {
+		return ""
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return ""
+	}
+
+	var sigs []string
+	for _, sym := range packageSymbols(dp) {
+		if strings.HasPrefix(sym.name, target) && ast.IsExported(lastName(sym.name)) {
+			sigs = append(sigs, symbolSignature(lp.fset, sym))
+		}
+	}
+	// Interface methods are not declarations of their own.
+	if typeName, prefix, ok := strings.Cut(target, "."); ok {
+		if _, _, it := findInterface(dp, typeName); it != nil {
+			for _, field := range it.Methods.List {
+				ft, isFunc := field.Type.(*ast.FuncType)
+				if !isFunc {
+					continue
+				}
+				for _, id := range field.Names {
+					if strings.HasPrefix(id.Name, prefix) && id.IsExported() {
+						sigs = append(sigs, typeName+"."+id.Name+strings.TrimPrefix(nodeString(lp.fset, ft), "func"))
+					}
+				}
+			}
+		}
+	}
+	if len(sigs) == 0 || len(sigs) > maxSuggestions {
+		return ""
+	}
+	return fmt.Sprintf("No exact match for %s in %s; did you mean:\n\n%s\n", target, importPath, strings.Join(sigs, "\n"))
+}
+
+// unexportedTypesDoc renders the unexported type declarations of a package
+// in go doc style. With a name, only that type is rendered and it is an error
+// if it does not exist.
+func (gs *godocServer) unexportedTypesDoc(ctx context.Context, workingDir, importPath, name string) (string, error) {
+	lp, err := gs.loadPackage(ctx, workingDir, importPath)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, f := range lp.files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.Name.IsExported() || (name != "" && ts.Name.Name != name) {
+					continue
+				}
+				docText := ts.Doc.Text()
+				if docText == "" && len(gd.Specs) == 1 {
+					docText = gd.Doc.Text()
+				}
+				writeTypeDecl(&b, lp.fset, ts, docText)
+			}
+		}
+	}
+
+	if name == "" {
+		return b.String(), nil
+	}
+	if b.Len() == 0 {
+		return "", fmt.Errorf("unexported type %s not found in %s", name, importPath)
+	}
+	return fmt.Sprintf("package %s // import %q\n\n", lp.files[0].Name.Name, importPath) + b.String(), nil
+}
+
+// synopsisDoc renders each symbol as its one-line signature followed by the
+// first sentence of its doc comment, as extracted by go/doc: every exported
+// symbol of lp under a package header, or target alone, with a type's
+// constructors and methods. It sits between go doc -short, which has no
+// prose, and the full documentation.
+func synopsisDoc(lp *loadedPackage, target string) (string, error) {
+	dp, err := lp.docPackage()
+	if err != nil {
+		return "", err
+	}
+
+	var syms []docSymbol
+	var b strings.Builder
+	if target == "" {
+		fmt.Fprintf(&b, "package %s // import %q\n\n", dp.Name, lp.path)
+		if synopsis := dp.Synopsis(dp.Doc); synopsis != "" {
+			b.WriteString(synopsis + "\n\n")
+		}
+		for _, sym := range packageSymbols(dp) {
+			if ast.IsExported(lastName(sym.name)) {
+				syms = append(syms, sym)
+			}
+		}
+	} else {
+		sym, ok := findSymbol(dp, target)
+		if !ok {
+			return "", fmt.Errorf("symbol %s not found in %s", target, lp.path)
+		}
+		syms = append(syms, sym)
+		// Like go doc Type, follow a type with its constructors and methods.
+		for _, s := range packageSymbols(dp) {
+			if sym.kind == "type" && s.name != target && ast.IsExported(lastName(s.name)) && symbolType(dp, s) == target {
+				syms = append(syms, s)
+			}
+		}
+	}
+
+	// A grouped const or var declaration shares one doc comment, and names
+	// declared by one spec share its signature.
+	described := make(map[ast.Decl]bool)
+	seen := make(map[string]bool)
+	for _, sym := range syms {
+		sig := symbolSignature(lp.fset, sym)
+		if seen[sig] {
+			continue
+		}
+		seen[sig] = true
+		b.WriteString(sig + "\n")
+		if described[sym.decl] {
+			continue
+		}
+		described[sym.decl] = true
+		if synopsis := dp.Synopsis(sym.doc); synopsis != "" {
+			b.WriteString("    " + synopsis + "\n")
+		}
+	}
+	return b.String(), nil
+}
+
+// symbolType returns the name of the type sym is grouped under by go/doc:
+// the receiver of a method, or the type a constructor, constant, or variable
+// belongs to. It returns "" for symbols at package level.
+func symbolType(dp *doc.Package, sym docSymbol) string {
+	if sym.kind == "method" {
+		typeName, _, _ := strings.Cut(sym.name, ".")
+		return typeName
+	}
+	for _, t := range dp.Types {
+		for _, f := range t.Funcs {
+			if f.Decl == sym.decl {
+				return t.Name
+			}
+		}
+		for _, v := range append(append([]*doc.Value{}, t.Consts...), t.Vars...) {
+			if v.Decl == sym.decl {
+				return t.Name
+			}
+		}
+	}
+	return ""
+}
+
+// writeTypeDecl writes ts as a standalone type declaration followed by its
+// indented doc comment.
+func writeTypeDecl(b *strings.Builder, fset *token.FileSet, ts *ast.TypeSpec, docText string) {
+	spec := *ts
+	spec.Doc, spec.Comment = nil, nil
+	decl := &ast.GenDecl{Tok: token.TYPE, Specs: []ast.Spec{&spec}}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, decl); err != nil {
+		fmt.Fprintf(b, "type %s\n", ts.Name.Name)
+	} else {
+		b.WriteString(buf.String() + "\n")
+	}
+	if docText != "" {
+		for _, line := range strings.Split(strings.TrimRight(docText, "\n"), "\n") {
+			if line != "" {
+				b.WriteString("    " + line)
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+}
+
+// lastName returns the final dot-separated element of a symbol name.
+func lastName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func (gs *godocServer) handleListSymbols(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	excludeGenerated := request.GetBool("exclude_generated", false)
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	files := make(map[string]*ast.File, len(lp.files))
+	for _, f := range lp.files {
+		files[lp.fset.Position(f.Package).Filename] = f
+	}
+
+	var lines []string
+	deprecated, experimental, generated, panics := 0, 0, 0, 0
+	for _, sym := range packageSymbols(dp) {
+		if !ast.IsExported(lastName(sym.name)) {
+			continue
+		}
+		if lp.isGenerated(sym.decl.Pos()) {
+			generated++
+			if excludeGenerated {
+				continue
+			}
+		}
+		file := files[lp.fset.Position(sym.decl.Pos()).Filename]
+		notes := stabilityNotes(sym.doc, file)
+		if lp.isGenerated(sym.decl.Pos()) {
+			notes = append(notes, "generated")
+		}
+		if _, ok := panicProne(dp, sym); ok {
+			notes = append(notes, "may panic")
+			panics++
+		}
+		line := symbolSignature(lp.fset, sym)
+		if len(notes) > 0 {
+			line += "  [" + strings.Join(notes, "; ") + "]"
+		}
+		for _, n := range notes {
+			switch {
+			case strings.HasPrefix(n, "deprecated"):
+				deprecated++
+			case n == "experimental":
+				experimental++
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		if generated > 0 {
+			return mcp.NewToolResultText(fmt.Sprintf("All %d exported symbols in %s are generated", generated, pkgPath)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("No exported symbols in %s", pkgPath)), nil
+	}
+	counts := fmt.Sprintf("%d deprecated, %d experimental", deprecated, experimental)
+	if panics > 0 {
+		counts += fmt.Sprintf(", %d may panic", panics)
+	}
+	switch {
+	case generated > 0 && excludeGenerated:
+		counts += fmt.Sprintf(", %d generated omitted", generated)
+	case generated > 0:
+		counts += fmt.Sprintf(", %d generated", generated)
+	}
+	header := fmt.Sprintf("%d exported symbols in %s (%s)\n\n", len(lines), pkgPath, counts)
+	return mcp.NewToolResultText(header + strings.Join(lines, "\n")), nil
+}
+
+// stabilityNotes derives stability annotations from a symbol's doc comment
+// and the build constraint of the file declaring it.
+func stabilityNotes(docText string, file *ast.File) []string {
+	var notes []string
+	if reason, ok := deprecationNotice(docText); ok {
+		if reason != "" {
+			notes = append(notes, "deprecated: "+reason)
+		} else {
+			notes = append(notes, "deprecated")
+		}
+	}
+	if experimentalPattern.MatchString(docText) || hasExperimentTag(file) {
+		notes = append(notes, "experimental")
+	}
+	if m := sincePattern.FindStringSubmatch(docText); m != nil {
+		notes = append(notes, "since "+strings.ReplaceAll(strings.ToLower(m[1]), " ", ""))
+	}
+	return notes
+}
+
+// deprecationNotice reports whether docText contains a "Deprecated:"
+// paragraph and returns the first line of its explanation.
+func deprecationNotice(docText string) (string, bool) {
+	for _, para := range strings.Split(docText, "\n\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(para), "Deprecated:"); ok {
+			reason, _, _ := strings.Cut(strings.TrimSpace(rest), "\n")
+			return reason, true
+		}
+	}
+	return "", false
+}
+
+// hasExperimentTag reports whether file's //go:build constraint depends on a
+// goexperiment.* tag.
+func hasExperimentTag(file *ast.File) bool {
+	if file == nil {
+		return false
+	}
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, c := range group.List {
+			if !constraint.IsGoBuild(c.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(c.Text)
+			if err != nil {
+				continue
+			}
+			found := false
+			expr.Eval(func(tag string) bool {
+				if strings.HasPrefix(tag, "goexperiment.") {
+					found = true
+				}
+				return false
+			})
+			if found {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// packageSymbols lists every documented symbol in dp, in go doc order.
+func packageSymbols(dp *doc.Package) []docSymbol {
+	var syms []docSymbol
+	addValues := func(values []*doc.Value, kind string) {
+		for _, v := range values {
+			for _, name := range valueNames(v.Decl) {
+				syms = append(syms, docSymbol{name: name, kind: kind, doc: v.Doc, decl: v.Decl})
+			}
+		}
+	}
+	addFuncs := func(funcs []*doc.Func) {
+		for _, f := range funcs {
+			sym := docSymbol{name: f.Name, kind: "func", doc: f.Doc, decl: f.Decl}
+			if f.Recv != "" {
+				sym.name = strings.TrimLeft(f.Recv, "*") + "." + f.Name
+				sym.kind = "method"
+				if i := strings.IndexByte(sym.name, '['); i >= 0 {
+					sym.name = sym.name[:i] + sym.name[strings.IndexByte(sym.name, ']')+1:]
+				}
+			}
+			syms = append(syms, sym)
+		}
+	}
+
+	addValues(dp.Consts, "const")
+	addValues(dp.Vars, "var")
+	addFuncs(dp.Funcs)
+	for _, t := range dp.Types {
+		syms = append(syms, docSymbol{name: t.Name, kind: "type", doc: t.Doc, decl: t.Decl})
+		addValues(t.Consts, "const")
+		addValues(t.Vars, "var")
+		addFuncs(t.Funcs)
+		addFuncs(t.Methods)
+	}
+	return syms
+}
+
+// findSymbol looks up target ("Name" or "Type.Method") in dp. Methods
+// include those of interface types, which go/doc does not list.
+func findSymbol(dp *doc.Package, target string) (docSymbol, bool) {
+	for _, sym := range packageSymbols(dp) {
+		if sym.name == target {
+			return sym, true
+		}
+	}
+	if typeName, method, ok := strings.Cut(target, "."); ok {
+		return interfaceMethod(dp, typeName, method)
+	}
+	return docSymbol{}, false
+}
+
+// interfaceMethod looks up the method name of the interface type typeName in
+// dp. Its declaration is synthesized as a function with the interface as its
+// receiver, so that it renders like a concrete method.
+func interfaceMethod(dp *doc.Package, typeName, name string) (docSymbol, bool) {
+	for _, t := range dp.Types {
+		if t.Name != typeName {
+			continue
+		}
+		for _, spec := range t.Decl.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok || ts.Name.Name != typeName {
+				continue
+			}
+			it, ok := ts.Type.(*ast.InterfaceType)
+			if !ok {
+				return docSymbol{}, false
+			}
+			for _, field := range it.Methods.List {
+				ft, ok := field.Type.(*ast.FuncType)
+				if !ok {
+					continue
+				}
+				for _, id := range field.Names {
+					if id.Name != name {
+						continue
+					}
+					decl := &ast.FuncDecl{
+						Doc:  field.Doc,
+						Recv: &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent(typeName)}}},
+						Name: id,
+						Type: ft,
+					}
+					return docSymbol{name: typeName + "." + name, kind: "method", doc: field.Doc.Text(), decl: decl, inInterface: true}, true
+				}
+			}
+		}
+	}
+	return docSymbol{}, false
+}
+
+// valueNames returns the names declared by a const or var declaration.
+func valueNames(decl *ast.GenDecl) []string {
+	var names []string
+	for _, spec := range decl.Specs {
+		if vs, ok := spec.(*ast.ValueSpec); ok {
+			for _, id := range vs.Names {
+				names = append(names, id.Name)
+			}
+		}
+	}
+	return names
+}
+
+// symbolSignature renders the declaration of sym on a single line. Struct and
+// interface bodies are elided.
+func symbolSignature(fset *token.FileSet, sym docSymbol) string {
+	var node any
+	prefix := ""
+	switch d := sym.decl.(type) {
+	case *ast.FuncDecl:
+		fn := *d
+		fn.Doc = nil
+		fn.Body = nil
+		node = &fn
+	case *ast.GenDecl:
+		prefix = d.Tok.String() + " "
+		name := sym.name
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				if s.Name.Name == name {
+					ts := *s
+					ts.Doc, ts.Comment = nil, nil
+					node = &ts
+				}
+			case *ast.ValueSpec:
+				for _, id := range s.Names {
+					if id.Name == name {
+						vs := *s
+						vs.Doc, vs.Comment = nil, nil
+						node = &vs
+					}
+				}
+			}
+		}
+	}
+	if node == nil {
+		return sym.kind + " " + sym.name
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return sym.kind + " " + sym.name
+	}
+
+	sig, rest, multiline := strings.Cut(buf.String(), "\n")
+	if multiline && strings.HasSuffix(sig, "{") {
+		sig += " ... }"
+	} else if multiline {
+		sig += " " + strings.Join(strings.Fields(rest), " ")
+	}
+	return prefix + sig
+}
@@ -0,0 +1,470 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const symbolsTestSource = `// Package demo is a test fixture.
+package demo
+
+// Limit is the maximum size. It is fixed.
+const Limit = 10
+
+// Default values.
+var (
+	A, B = 1, 2
+)
+
+// Config holds settings. It has fields.
+type Config struct {
+	Name string
+}
+
+// New returns a Config. Use it.
+func New(name string) *Config { return &Config{Name: name} }
+
+// Validate checks c. It returns an error.
+func (c *Config) Validate() error { return nil }
+
+// List is generic.
+type List[T any] struct{}
+
+// Len returns the length.
+func (l *List[T]) Len() int { return 0 }
+`
+
+func loadFixture(t *testing.T, files map[string]string) *loadedPackage {
+	t.Helper()
+	dir := writeModule(t, files)
+	lp, err := parsePackage(dir)
+	if err != nil {
+		t.Fatalf("parsePackage: %v", err)
+	}
+	lp.path = "example.com/demo"
+	return lp
+}
+
+func TestFindSymbolAndSignature(t *testing.T) {
+	lp := loadFixture(t, map[string]string{"demo.go": symbolsTestSource})
+	dp, err := lp.docPackage()
+	if err != nil {
+		t.Fatalf("docPackage: %v", err)
+	}
+
+	tests := []struct {
+		target   string
+		kind     string
+		sig      string
+		synopsis string
+	}{
+		{"Limit", "const", "const Limit = 10", "Limit is the maximum size."},
+		{"B", "var", "var A, B = 1, 2", "Default values."},
+		{"Config", "type", "type Config struct { ... }", "Config holds settings."},
+		{"New", "func", "func New(name string) *Config", "New returns a Config."},
+		{"Config.Validate", "method", "func (c *Config) Validate() error", "Validate checks c."},
+		{"List.Len", "method", "func (l *List[T]) Len() int", "Len returns the length."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			sym, ok := findSymbol(dp, tt.target)
+			if !ok {
+				t.Fatalf("symbol %s not found", tt.target)
+			}
+			if sym.kind != tt.kind {
+				t.Errorf("kind = %q, want %q", sym.kind, tt.kind)
+			}
+			if got := symbolSignature(lp.fset, sym); got != tt.sig {
+				t.Errorf("signature = %q, want %q", got, tt.sig)
+			}
+			if got := dp.Synopsis(sym.doc); got != tt.synopsis {
+				t.Errorf("synopsis = %q, want %q", got, tt.synopsis)
+			}
+		})
+	}
+
+	if _, ok := findSymbol(dp, "Missing"); ok {
+		t.Error("found nonexistent symbol")
+	}
+}
+
+func TestSynopsisDoc(t *testing.T) {
+	lp := loadFixture(t, map[string]string{"demo.go": symbolsTestSource})
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"", "package demo // import \"example.com/demo\"\n\n" +
+			"Package demo is a test fixture.\n\n" +
+			"const Limit = 10\n    Limit is the maximum size.\n" +
+			"var A, B = 1, 2\n    Default values.\n" +
+			"type Config struct { ... }\n    Config holds settings.\n" +
+			"func New(name string) *Config\n    New returns a Config.\n" +
+			"func (c *Config) Validate() error\n    Validate checks c.\n" +
+			"type List[T any] struct{}\n    List is generic.\n" +
+			"func (l *List[T]) Len() int\n    Len returns the length.\n"},
+		{"Config", "type Config struct { ... }\n    Config holds settings.\n" +
+			"func New(name string) *Config\n    New returns a Config.\n" +
+			"func (c *Config) Validate() error\n    Validate checks c.\n"},
+		{"New", "func New(name string) *Config\n    New returns a Config.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			got, err := synopsisDoc(lp, tt.target)
+			if err != nil {
+				t.Fatalf("synopsisDoc: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("synopsisDoc() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := synopsisDoc(lp, "Missing"); err == nil {
+		t.Error("expected an error for a missing symbol")
+	}
+}
+
+func TestHandleWhatis(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "whatis"
+	req.Params.Arguments = map[string]any{
+		"path":   "io",
+		"target": "ReadAll",
+	}
+
+	result, err := gs.handleWhatis(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleWhatis returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleWhatis returned tool error: %+v", result.Content)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.HasPrefix(text, "func ReadAll(r Reader) ([]byte, error)") {
+		t.Errorf("unexpected signature line: %s", firstLine(text))
+	}
+	if strings.Count(text, "\n") != 1 {
+		t.Errorf("expected exactly two lines, got:\n%s", text)
+	}
+}
+
+func TestHandleWhatisInterfaceMethod(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "whatis"
+	req.Params.Arguments = map[string]any{"path": "io", "target": "Reader.Read"}
+
+	result, err := gs.handleWhatis(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleWhatis returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleWhatis returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if want := "func (Reader) Read(p []byte) (n int, err error)"; firstLine(text) != want {
+		t.Errorf("signature line = %q, want %q", firstLine(text), want)
+	}
+}
+
+func TestHandleWhatisMissingTarget(t *testing.T) {
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "whatis"
+	req.Params.Arguments = map[string]any{"path": "io"}
+
+	result, err := gs.handleWhatis(context.Background(), req)
+	if err != nil {
+		t.Fatalf("returned protocol error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected tool error for missing target")
+	}
+}
+
+func TestHandleGetDocForceParse(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod":          "module example.com/plat\n\ngo 1.21\n",
+		"plat/a_plan9.go": "//go:build neverbuilt\n\n// Package plat is platform-specific.\npackage plat\n\n// Open opens things.\nfunc Open() error { return nil }\n\nfunc hidden() {}\n",
+		"plat/b_other.go": "//go:build neverbuilt\n\npackage plat\n\n// Close closes things.\nfunc Close() error { return nil }\n",
+	})
+
+	gs := newGodocServer()
+	call := func(args map[string]any) *mcp.CallToolResult {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		return result
+	}
+
+	result := call(map[string]any{"path": "./plat", "working_dir": dir})
+	if !result.IsError {
+		t.Fatal("expected build-constraint error without force_parse")
+	}
+
+	result = call(map[string]any{"path": "./plat", "working_dir": dir, "force_parse": true})
+	if result.IsError {
+		t.Fatalf("force_parse returned tool error: %+v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"WARNING: build constraints were bypassed", "func Open() error  // a_plan9.go", "func Close() error  // b_other.go"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "hidden") {
+		t.Errorf("unexported symbol listed:\n%s", text)
+	}
+
+	result = call(map[string]any{"path": "./plat", "working_dir": dir, "target": "Open", "force_parse": true})
+	if result.IsError {
+		t.Fatalf("force_parse with target returned tool error: %+v", result.Content)
+	}
+	text = result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Open opens things.") || strings.Contains(text, "Close") {
+		t.Errorf("unexpected targeted output:\n%s", text)
+	}
+}
+
+func TestHandleGetDocUnexportedTypes(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/internals\n\ngo 1.21\n",
+		"lib.go": `package internals
+
+// Store is the public API.
+type Store struct {
+	idx *index
+}
+
+// index maps keys to offsets.
+type index struct {
+	offsets map[string]int
+}
+
+// helper is not a type.
+func helper() {}
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = args
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := call(map[string]any{"path": ".", "working_dir": dir, "unexported": "types"})
+	if !strings.Contains(text, "UNEXPORTED TYPES") || !strings.Contains(text, "type index struct") {
+		t.Errorf("expected unexported types section:\n%s", text)
+	}
+	if strings.Contains(text, "helper") {
+		t.Errorf("unexported function should not be listed:\n%s", text)
+	}
+
+	text = call(map[string]any{"path": ".", "working_dir": dir, "target": "index", "unexported": "types"})
+	if !strings.Contains(text, "index maps keys to offsets.") {
+		t.Errorf("expected targeted unexported type docs:\n%s", text)
+	}
+
+	text = call(map[string]any{"path": ".", "working_dir": dir, "unexported": "all"})
+	if !strings.Contains(text, "func helper()") {
+		t.Errorf("expected -u output to include unexported functions:\n%s", text)
+	}
+}
+
+func TestHandleGetDocInvalidUnexported(t *testing.T) {
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_doc"
+	req.Params.Arguments = map[string]any{"path": "io", "unexported": "some"}
+
+	result, err := gs.handleGetDoc(context.Background(), req)
+	if err != nil {
+		t.Fatalf("returned protocol error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected tool error for invalid unexported value")
+	}
+}
+
+func TestStabilityNotes(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{"plain", "Foo does foo.\n", nil},
+		{"deprecated", "Foo does foo.\n\nDeprecated: Use Bar instead.\n", []string{"deprecated: Use Bar instead."}},
+		{"deprecated no reason", "Foo does foo.\n\nDeprecated:\n", []string{"deprecated"}},
+		{"experimental", "Foo does foo.\n\nExperimental: this may change.\n", []string{"experimental"}},
+		{"since module version", "Foo does foo. Added in v1.4.0.\n", []string{"since v1.4.0"}},
+		{"since go version", "Foo does foo.\nAvailable since Go 1.21.\n", []string{"since go1.21"}},
+		{"mentions deprecated mid-sentence", "Foo replaces the Deprecated: Bar.\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stabilityNotes(tt.doc, nil)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("stabilityNotes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasExperimentTag(t *testing.T) {
+	lp := loadFixture(t, map[string]string{
+		"a.go": "//go:build goexperiment.arenas\n\npackage demo\n\nfunc A() {}\n",
+		"b.go": "//go:build linux || !linux\n\npackage demo\n\nfunc B() {}\n",
+	})
+	for _, f := range lp.files {
+		name := lp.fset.Position(f.Package).Filename
+		want := strings.HasSuffix(name, "a.go")
+		if got := hasExperimentTag(f); got != want {
+			t.Errorf("hasExperimentTag(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestHandleListSymbols(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/stab\n\ngo 1.21\n",
+		"stab.go": `package stab
+
+// Old is old.
+//
+// Deprecated: Use New.
+func Old() {}
+
+// New is new. Added in v1.2.0.
+func New() {}
+
+func hidden() {}
+`,
+	})
+
+	gs := newGodocServer()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "list_symbols"
+	req.Params.Arguments = map[string]any{"path": ".", "working_dir": dir}
+
+	result, err := gs.handleListSymbols(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleListSymbols returned protocol error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("handleListSymbols returned tool error: %+v", result.Content)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"2 exported symbols in example.com/stab (1 deprecated, 0 experimental)",
+		"func Old()  [deprecated: Use New.]",
+		"func New()  [since v1.2.0]",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "hidden") {
+		t.Errorf("unexported symbol listed:\n%s", text)
+	}
+}
+
+func TestHandleListSymbolsGenerated(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/gen\n\ngo 1.21\n",
+		"client.go": `package gen
+
+// Dial connects.
+func Dial() {}
+`,
+		"api.pb.go": `// Code generated by protoc-gen-go. DO NOT EDIT.
+
+package gen
+
+type Request struct{}
+
+func (r *Request) GetName() string { return "" }
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "list_symbols"
+		req.Params.Arguments = args
+		result, err := gs.handleListSymbols(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleListSymbols returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleListSymbols returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := call(map[string]any{"path": ".", "working_dir": dir})
+	for _, want := range []string{
+		"3 exported symbols in example.com/gen (0 deprecated, 0 experimental, 2 generated)",
+		"type Request struct{}  [generated]",
+		"func (r *Request) GetName() string  [generated]",
+		"func Dial()\n",
+	} {
+		if !strings.Contains(text+"\n", want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+
+	text = call(map[string]any{"path": ".", "working_dir": dir, "exclude_generated": true})
+	if !strings.Contains(text, "1 exported symbols in example.com/gen (0 deprecated, 0 experimental, 2 generated omitted)") || strings.Contains(text, "Request") {
+		t.Errorf("generated symbols not excluded:\n%s", text)
+	}
+}
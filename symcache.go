@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"go/ast"
+	"log"
+	"sync"
+	"time"
+)
+
+// parsedPackage is a package directory the server has already located and
+// parsed, with the documentation of each symbol rendered so far. Later symbol
+// queries for the package are answered from here without running go doc.
+type parsedPackage struct {
+	dir       string
+	timestamp time.Time
+
+	mu      sync.Mutex
+	hash    string            // sourceHash of dir when pr was built
+	pr      *docPrinter       // go doc's view of the package
+	symbols map[string]string // rendered docs by symbol name
+}
+
+// rememberPackage records that importPath, as seen from workingDir, lives in
+// dir, so later symbol queries can be served from its parsed source.
+func (gs *godocServer) rememberPackage(ctx context.Context, workingDir, importPath, dir string) {
+	key := gs.docCacheKey(ctx, workingDir, []string{"parsed", importPath})
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if pp, ok := gs.parsed[key]; ok && pp.dir == dir && time.Since(pp.timestamp) < cacheTTL {
+		return
+	}
+	// Evict oldest entry if cache is full.
+	if len(gs.parsed) >= maxCacheSize {
+		var oldestKey string
+		var oldestTime time.Time
+		for k, v := range gs.parsed {
+			if oldestKey == "" || v.timestamp.Before(oldestTime) {
+				oldestKey = k
+				oldestTime = v.timestamp
+			}
+		}
+		delete(gs.parsed, oldestKey)
+	}
+	gs.parsed[key] = &parsedPackage{dir: dir, timestamp: time.Now()}
+}
+
+// cachedSymbolDoc returns the documentation of target in importPath from the
+// parsed package cache. It reports false when the package has not been parsed
+// yet, its entry has expired, or the symbol is not one it can render, in
+// which case the caller should ask go doc.
+func (gs *godocServer) cachedSymbolDoc(ctx context.Context, workingDir, importPath, target string) (string, bool) {
+	key := gs.docCacheKey(ctx, workingDir, []string{"parsed", importPath})
+
+	gs.mu.Lock()
+	pp, ok := gs.parsed[key]
+	if ok && time.Since(pp.timestamp) >= cacheTTL {
+		delete(gs.parsed, key)
+		ok = false
+	}
+	gs.mu.Unlock()
+	if !ok {
+		return "", false
+	}
+
+	hash, err := sourceHash(pp.dir)
+	if err != nil {
+		return "", false
+	}
+
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	if pp.pr == nil || pp.hash != hash {
+		lp, err := gs.parseDir(ctx, workingDir, importPath, pp.dir)
+		if err != nil {
+			return "", false
+		}
+		lp.path = importPath
+		pr, err := newDocPrinter(lp)
+		if err != nil {
+			return "", false
+		}
+		pp.pr, pp.hash, pp.symbols = pr, hash, make(map[string]string)
+	}
+	if text, ok := pp.symbols[target]; ok {
+		log.Printf("Symbol cache hit for %s %s", importPath, target)
+		return text, true
+	}
+	if !ast.IsExported(lastName(target)) {
+		return "", false
+	}
+	text, ok := pp.pr.symbolDoc(target)
+	if !ok {
+		return "", false
+	}
+	pp.symbols[target] = text
+	return text, true
+}
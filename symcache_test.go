@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleGetDocParsedSymbolCache(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/greet\n\ngo 1.21\n",
+		"greet.go": `// Package greet says hello.
+package greet
+
+// Hello returns a greeting for name.
+func Hello(name string) string { return "hello " + name }
+
+// Greeter greets people.
+type Greeter struct {
+	// Prefix starts every greeting.
+	Prefix string
+	count  int
+}
+
+// NewGreeter returns a Greeter.
+func NewGreeter() *Greeter { return &Greeter{} }
+
+// Greet greets name.
+func (g *Greeter) Greet(name string) string { return g.Prefix + name }
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(target string) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = map[string]any{"path": ".", "target": target, "working_dir": dir, "show_metadata": false}
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+	ranGoDoc := func(target string) bool {
+		for _, key := range gs.cache.keys() {
+			if strings.HasSuffix(key, "|"+target) || strings.Contains(key, "|"+target+"|") {
+				return true
+			}
+		}
+		return false
+	}
+
+	// A package-level query parses the package for its init function note.
+	call("")
+
+	if text := call("Hello"); !strings.Contains(text, "func Hello(name string) string") || !strings.Contains(text, "Hello returns a greeting") {
+		t.Errorf("unexpected doc for Hello:\n%s", text)
+	}
+	text := call("Greeter")
+	for _, want := range []string{"type Greeter struct {", "Prefix string", "Greeter greets people.", "func NewGreeter() *Greeter", "func (g *Greeter) Greet(name string) string"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("doc for Greeter missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "count") {
+		t.Errorf("doc for Greeter shows an unexported field:\n%s", text)
+	}
+	if ranGoDoc("Hello") || ranGoDoc("Greeter") {
+		t.Error("symbol queries ran go doc instead of using the parsed package")
+	}
+
+	// Editing the source invalidates the rendered docs.
+	src := filepath.Join(dir, "greet.go")
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte(strings.Replace(string(data), "returns a greeting", "builds a salutation", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(src, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if text := call("Hello"); !strings.Contains(text, "Hello builds a salutation") {
+		t.Errorf("expected updated doc after edit:\n%s", text)
+	}
+}
+
+func TestSymbolDocMatchesGoDoc(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/state\n\ngo 1.21\n",
+		"state.go": `// Package state tracks connections.
+package state
+
+// A State represents the state of a connection. It's used by the optional [Conn.Hook] hook, which is called
+// whenever a connection changes state, with the new state.
+type State int
+
+const (
+	// New is a new connection.
+	New State = iota
+	Active
+	closed
+)
+
+// String returns the name of s.
+func (s State) String() string { return "" }
+
+// Conn is a connection.
+type Conn struct {
+	// Hook, if set, is called on state changes.
+	Hook func(State)
+	id   int
+}
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(target string) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_doc"
+		req.Params.Arguments = map[string]any{"path": ".", "target": target, "working_dir": dir, "show_metadata": false}
+		result, err := gs.handleGetDoc(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleGetDoc returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleGetDoc returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	targets := []string{"State", "Conn", "New", "State.String"}
+	cold := make(map[string]string)
+	for _, target := range targets {
+		cold[target] = call(target)
+	}
+	// A package-level query parses the package; symbols are then rendered
+	// from its source.
+	call("")
+	for _, target := range targets {
+		if warm := call(target); warm != cold[target] {
+			t.Errorf("%s: rendered doc differs from go doc:\n--- go doc\n%s\n--- rendered\n%s", target, cold[target], warm)
+		}
+	}
+	for _, pp := range gs.parsed {
+		if len(pp.symbols) != len(targets) {
+			t.Errorf("rendered %d symbols from source, want %d", len(pp.symbols), len(targets))
+		}
+	}
+	if !strings.Contains(cold["State"], "const New State = iota ...") {
+		t.Errorf("doc for State lacks its constants:\n%s", cold["State"])
+	}
+}
+
+func TestCachedSymbolDocUnknownPackage(t *testing.T) {
+	gs := newGodocServer()
+	if _, ok := gs.cachedSymbolDoc(context.Background(), "", "example.com/none", "X"); ok {
+		t.Error("expected a miss for a package that was never parsed")
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const usageHintsDescription = `Rank a Go package's exported symbols by how often its own tests and
+examples use them. References in _test.go files (including external _test
+packages) are counted per symbol, with references from Example functions
+shown separately. The most-exercised symbols are usually the intended entry
+points; symbols no test or example touches are listed last. Use this to pick
+the idiomatic API when several look applicable. Method calls are matched by
+name, so a method name shared by several types is credited to all of them.`
+
+// defaultUsageLimit is the number of symbols usage_hints ranks when the
+// request does not say.
+const defaultUsageLimit = 15
+
+// symbolUsage counts the references to one exported symbol.
+type symbolUsage struct {
+	name     string
+	refs     int
+	examples int // refs made inside Example functions
+}
+
+func (gs *godocServer) handleUsageHints(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pkgPath, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path argument is required"), nil
+	}
+	limit := request.GetInt("limit", defaultUsageLimit)
+	if limit < 1 {
+		limit = defaultUsageLimit
+	}
+
+	pkgPath, workingDir, err := gs.resolvePackage(ctx, pkgPath, request.GetString("working_dir", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	lp, err := gs.loadPackage(ctx, workingDir, pkgPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dp, err := lp.docPackage()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	bp, err := build.ImportDir(lp.dir, 0)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read package in %s: %v", lp.dir, err)), nil
+	}
+	if len(bp.TestGoFiles)+len(bp.XTestGoFiles) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No test or example files in %s", pkgPath)), nil
+	}
+
+	// Top-level names are referenced bare from the package's own tests and
+	// qualified from external ones; methods are matched by selector name.
+	usage := make(map[string]*symbolUsage)
+	var order []string
+	top := make(map[string]bool)
+	methods := make(map[string][]string)
+	for _, sym := range packageSymbols(dp) {
+		if !ast.IsExported(lastName(sym.name)) || usage[sym.name] != nil {
+			continue
+		}
+		usage[sym.name] = &symbolUsage{name: sym.name}
+		order = append(order, sym.name)
+		if sym.kind == "method" {
+			methods[lastName(sym.name)] = append(methods[lastName(sym.name)], sym.name)
+		} else {
+			top[sym.name] = true
+		}
+	}
+
+	fset := token.NewFileSet()
+	examples := 0
+	for _, name := range append(append([]string{}, bp.TestGoFiles...), bp.XTestGoFiles...) {
+		f, err := parser.ParseFile(fset, filepath.Join(lp.dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to parse %s: %v", name, err)), nil
+		}
+		qualifier := ""
+		if f.Name.Name != dp.Name {
+			if qualifier = importName(f, pkgPath, dp.Name); qualifier == "" {
+				continue
+			}
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			inExample := ok && fn.Recv == nil && strings.HasPrefix(fn.Name.Name, "Example")
+			if inExample {
+				examples++
+			}
+			countUsage(decl, qualifier, top, methods, func(name string) {
+				usage[name].refs++
+				if inExample {
+					usage[name].examples++
+				}
+			})
+		}
+	}
+
+	var unused []string
+	ranked := make([]*symbolUsage, 0, len(order))
+	for _, name := range order {
+		if u := usage[name]; u.refs > 0 {
+			ranked = append(ranked, u)
+		} else {
+			unused = append(unused, name)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].refs != ranked[j].refs {
+			return ranked[i].refs > ranked[j].refs
+		}
+		return ranked[i].examples > ranked[j].examples
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage of %s in its tests and examples (%d test files, %d examples)\n\n",
+		pkgPath, len(bp.TestGoFiles)+len(bp.XTestGoFiles), examples)
+	if len(ranked) == 0 {
+		b.WriteString("No exported symbol is referenced by the tests.\n")
+	} else {
+		b.WriteString("COMMONLY USED\n\n  refs  examples  symbol\n")
+		for i, u := range ranked {
+			if i == limit {
+				fmt.Fprintf(&b, "\n... and %d more referenced symbols\n", len(ranked)-limit)
+				break
+			}
+			fmt.Fprintf(&b, "%6d %9d  %s\n", u.refs, u.examples, u.name)
+		}
+	}
+	if len(unused) > 0 {
+		fmt.Fprintf(&b, "\nNOT EXERCISED BY ANY TEST OR EXAMPLE\n\n%s\n", strings.Join(unused, ", "))
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// importName returns the name under which f imports importPath: its
+// explicit alias, or defaultName. It returns "" if f does not import it or
+// imports it with a blank or dot alias.
+func importName(f *ast.File, importPath, defaultName string) string {
+	for _, imp := range f.Imports {
+		if strings.Trim(imp.Path.Value, "`\"") != importPath {
+			continue
+		}
+		if imp.Name == nil {
+			return defaultName
+		}
+		if imp.Name.Name == "_" || imp.Name.Name == "." {
+			return ""
+		}
+		return imp.Name.Name
+	}
+	return ""
+}
+
+// countUsage calls count for each reference in node to an exported symbol.
+// With an empty qualifier, names in top are matched as bare identifiers, as
+// in the package's own test files; otherwise as qualifier.Name. A selector
+// naming a method in methods counts once for every type declaring it.
+func countUsage(node ast.Node, qualifier string, top map[string]bool, methods map[string][]string, count func(string)) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			if id, ok := n.X.(*ast.Ident); ok && qualifier != "" && id.Name == qualifier {
+				if top[n.Sel.Name] {
+					count(n.Sel.Name)
+				}
+				return false
+			}
+			for _, m := range methods[n.Sel.Name] {
+				count(m)
+			}
+			countUsage(n.X, qualifier, top, methods, count)
+			return false
+		case *ast.Ident:
+			if qualifier == "" && top[n.Name] {
+				count(n.Name)
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleUsageHints(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/kv\n\ngo 1.21\n",
+		"kv.go": `// Package kv stores values.
+package kv
+
+// Store holds values.
+type Store struct{}
+
+// Open returns a Store.
+func Open() *Store { return &Store{} }
+
+// Get returns the value for key.
+func (s *Store) Get(key string) string { return "" }
+
+// Compact is rarely needed.
+func (s *Store) Compact() {}
+
+// Version reports the library version.
+func Version() string { return "1" }
+`,
+		"kv_test.go": `package kv
+
+import "testing"
+
+func TestGet(t *testing.T) {
+	s := Open()
+	s.Get("a")
+	s.Get("b")
+	var _ *Store = s
+}
+`,
+		"example_test.go": `package kv_test
+
+import (
+	"fmt"
+
+	store "example.com/kv"
+)
+
+func ExampleOpen() {
+	s := store.Open()
+	fmt.Println(s.Get("a"))
+}
+`,
+	})
+
+	gs := newGodocServer()
+	call := func(args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "usage_hints"
+		req.Params.Arguments = args
+		result, err := gs.handleUsageHints(context.Background(), req)
+		if err != nil {
+			t.Fatalf("handleUsageHints returned protocol error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleUsageHints returned tool error: %+v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := call(map[string]any{"path": ".", "working_dir": dir})
+	for _, want := range []string{
+		"Usage of example.com/kv in its tests and examples (2 test files, 1 examples)",
+		"     3         1  Store.Get\n     2         1  Open\n     1         0  Store\n",
+		"NOT EXERCISED BY ANY TEST OR EXAMPLE\n\nVersion, Store.Compact\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	text = call(map[string]any{"path": ".", "working_dir": dir, "limit": 1})
+	if !strings.Contains(text, "... and 2 more referenced symbols") || strings.Contains(text, "  Open\n") {
+		t.Errorf("limit not applied:\n%s", text)
+	}
+}
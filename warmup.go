@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+)
+
+// warmupCommands are the go subcommands -warmup-command accepts.
+var warmupCommands = map[string]bool{"build": true, "vet": true}
+
+// warmupTimeout bounds each warmup command. A cold build of a large module
+// takes far longer than cmdTimeout allows a documentation lookup.
+const warmupTimeout = 10 * time.Minute
+
+// maxWarmupErrorLines caps how much of a failed warmup's output is logged.
+const maxWarmupErrorLines = 10
+
+// startWarmup warms the build cache for the -warmup directories in the
+// background, so the server accepts requests meanwhile.
+func (gs *godocServer) startWarmup() {
+	if len(gs.warmupDirs) == 0 {
+		return
+	}
+	go gs.warmBuildCache(context.Background())
+}
+
+// warmBuildCache runs go build ./... (or go vet ./..., per -warmup-command)
+// in each -warmup directory so the packages and dependencies later go doc
+// lookups load are already compiled. The commands run outside the process
+// pool, which would otherwise queue lookups behind them. Failures are logged
+// and never fatal: packages that did compile still warm the cache. It
+// returns how many directories warmed without error.
+func (gs *godocServer) warmBuildCache(ctx context.Context) int {
+	warmed := 0
+	for _, dir := range gs.warmupDirs {
+		log.Printf("Warming build cache: go %s ./... in %s", gs.warmupCommand, dir)
+		start := time.Now()
+		cmdCtx, cancel := context.WithTimeout(ctx, warmupTimeout)
+		out, err := gs.goCommand(cmdCtx, dir, gs.warmupCommand, "./...").CombinedOutput()
+		cancel()
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+			if len(lines) > maxWarmupErrorLines {
+				lines = append(lines[:maxWarmupErrorLines], "...")
+			}
+			log.Printf("Build cache warmup in %s failed after %s (packages that compiled are still cached): %v\n%s",
+				dir, elapsed, err, strings.Join(lines, "\n"))
+			continue
+		}
+		warmed++
+		log.Printf("Build cache warmed for %s in %s", dir, elapsed)
+	}
+	return warmed
+}
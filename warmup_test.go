@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWarmBuildCache(t *testing.T) {
+	record := filepath.Join(t.TempDir(), "calls")
+	fakeGo(t, `echo "$PWD $*" >> `+record+`
+case "$PWD" in
+*broken) echo "broken.go:3:1: syntax error" >&2; exit 1 ;;
+esac
+`)
+	good, broken := t.TempDir(), filepath.Join(t.TempDir(), "broken")
+	if err := os.Mkdir(broken, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	gs := newGodocServer(withWarmup([]string{good, broken}, "vet"))
+	if got := gs.warmBuildCache(context.Background()); got != 1 {
+		t.Errorf("warmBuildCache() = %d, want 1", got)
+	}
+
+	calls, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{good + " vet ./...", broken + " vet ./..."} {
+		if !strings.Contains(string(calls), want) {
+			t.Errorf("go was not run as %q; calls:\n%s", want, calls)
+		}
+	}
+	for _, want := range []string{"Build cache warmed for " + good, "Build cache warmup in " + broken + " failed", "broken.go:3:1: syntax error"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("log missing %q:\n%s", want, logs.String())
+		}
+	}
+
+	if gs := newGodocServer(withWarmup(nil, "install")); gs.warmupCommand != "build" {
+		t.Errorf("unknown command: warmupCommand = %q, want build", gs.warmupCommand)
+	}
+}
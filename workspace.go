@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// workspaceFile returns the go.work file that puts go commands run in dir in
+// workspace mode, or "" when none applies: GOWORK names it explicitly, is
+// "off", or is unset and a go.work is found in dir or a parent directory.
+func workspaceFile(env []string, dir string) string {
+	switch gowork := envValue(env, "GOWORK"); gowork {
+	case "off":
+		return ""
+	case "":
+	default:
+		return gowork
+	}
+	if dir == "" {
+		return ""
+	}
+	dir = filepath.Clean(dir)
+	for {
+		file := filepath.Join(dir, "go.work")
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			return file
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// workspaceGoFlags removes from goflags the -mod settings the go command
+// rejects in workspace mode, where only -mod=readonly and -mod=vendor are
+// allowed. A GOFLAGS=-mod=mod set for single-module builds would otherwise
+// make every go command in a workspace fail.
+func workspaceGoFlags(goflags string) string {
+	var kept []string
+	for _, f := range strings.Fields(goflags) {
+		if mode, ok := strings.CutPrefix(strings.TrimLeft(f, "-"), "mod="); ok && mode != "readonly" && mode != "vendor" {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return strings.Join(kept, " ")
+}
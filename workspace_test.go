@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestWorkspaceFile(t *testing.T) {
+	root := writeModule(t, map[string]string{
+		"go.work":      "go 1.21\n\nuse ./app\n",
+		"app/go.mod":   "module example.com/app\n\ngo 1.21\n",
+		"app/pkg/p.go": "package pkg\n",
+		"alone/go.mod": "module example.com/alone\n\ngo 1.21\n",
+	})
+	outside := t.TempDir()
+	work := filepath.Join(root, "go.work")
+
+	tests := []struct {
+		env  []string
+		dir  string
+		want string
+	}{
+		{nil, filepath.Join(root, "app", "pkg"), work},
+		{nil, root, work},
+		{nil, outside, ""},
+		{nil, "", ""},
+		{[]string{"GOWORK=off"}, filepath.Join(root, "app"), ""},
+		{[]string{"GOWORK=/elsewhere/go.work"}, outside, "/elsewhere/go.work"},
+	}
+	for _, tt := range tests {
+		if got := workspaceFile(tt.env, tt.dir); got != tt.want {
+			t.Errorf("workspaceFile(%q, %q) = %q, want %q", tt.env, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestWorkspaceGoFlags(t *testing.T) {
+	tests := map[string]string{
+		"":                           "",
+		"-mod=mod":                   "",
+		"-mod=readonly":              "-mod=readonly",
+		"--mod=vendor":               "--mod=vendor",
+		"-trimpath -mod=mod -v":      "-trimpath -v",
+		"-modcacherw -mod=mod":       "-modcacherw",
+		"-tags=integration -mod=mod": "-tags=integration",
+	}
+	for in, want := range tests {
+		if got := workspaceGoFlags(in); got != want {
+			t.Errorf("workspaceGoFlags(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestWorkspaceReplace resolves packages across a go.work workspace whose
+// modules carry their own replace directives: app replaces lib, a workspace
+// module, and lib replaces util, which is outside the workspace. GOFLAGS=-mod=mod,
+// which workspace mode rejects, must not break resolution.
+func TestWorkspaceReplace(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found in PATH")
+	}
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOPROXY", "off")
+
+	root := writeModule(t, map[string]string{
+		"go.work":      "go 1.21\n\nuse (\n\t./app\n\t./lib\n)\n",
+		"app/go.mod":   "module example.com/app\n\ngo 1.21\n\nrequire example.com/lib v0.0.0\n\nreplace example.com/lib => ../lib\n",
+		"app/app.go":   "package app\n\nimport \"example.com/lib\"\n\nvar _ = lib.L\n",
+		"lib/go.mod":   "module example.com/lib\n\ngo 1.21\n\nrequire example.com/util v0.0.0\n\nreplace example.com/util => ../util\n",
+		"lib/lib.go":   "// Package lib is in the workspace.\npackage lib\n\nimport \"example.com/util\"\n\n// L is lib.\nfunc L() { util.U() }\n",
+		"util/go.mod":  "module example.com/util\n\ngo 1.21\n",
+		"util/util.go": "// Package util is outside the workspace.\npackage util\n\n// U is util.\nfunc U() {}\n",
+	})
+	app := filepath.Join(root, "app")
+
+	gs := newGodocServer()
+	defer gs.cleanup()
+	ctx := context.Background()
+
+	call := func(tool string, args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = tool
+		req.Params.Arguments = args
+		result, err := gs.mcpServer.GetTool(tool).Handler(ctx, req)
+		if err != nil {
+			t.Fatalf("%s returned protocol error: %v", tool, err)
+		}
+		text := result.Content[0].(mcp.TextContent).Text
+		if result.IsError {
+			t.Fatalf("%s %v: unexpected tool error: %s", tool, args, text)
+		}
+		return text
+	}
+
+	for _, dir := range []string{app, root} {
+		text := call("get_doc", map[string]any{"path": "example.com/util", "target": "U", "working_dir": dir})
+		if !strings.Contains(text, "U is util.") {
+			t.Errorf("get_doc util.U from %s: missing doc:\n%s", dir, text)
+		}
+		wantNote := "replaced by the directory " + filepath.Join(root, "util") + " in the workspace " + filepath.Join(root, "go.work")
+		if !strings.Contains(text, wantNote) {
+			t.Errorf("get_doc util.U from %s: want note %q, got:\n%s", dir, wantNote, text)
+		}
+	}
+
+	text := call("get_doc", map[string]any{"path": "example.com/lib", "working_dir": app})
+	if !strings.Contains(text, "Package lib is in the workspace.") {
+		t.Errorf("get_doc lib: missing package doc:\n%s", text)
+	}
+	text = call("list_symbols", map[string]any{"path": "example.com/util", "working_dir": app})
+	if !strings.Contains(text, "func U()") {
+		t.Errorf("list_symbols util: missing U:\n%s", text)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_doc"
+	req.Params.Arguments = map[string]any{"path": "example.com/lib", "working_dir": app, "mod_mode": "mod"}
+	result, err := gs.handleGetDoc(ctx, req)
+	if err != nil {
+		t.Fatalf("handleGetDoc returned protocol error: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "workspace mode") {
+		t.Errorf("expected mod_mode 'mod' to be rejected in workspace mode, got %+v", result)
+	}
+}